@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+	"github.com/chudno/amo_crm_sdk/utils/schema"
+)
+
+// entityNames задает префиксы констант и имена структур для типов сущностей
+var entityNames = map[custom_fields.EntityType]struct {
+	prefix string
+	title  string
+}{
+	custom_fields.EntityLeads:     {"Lead", "сделок"},
+	custom_fields.EntityContacts:  {"Contact", "контактов"},
+	custom_fields.EntityCompanies: {"Company", "компаний"},
+	custom_fields.EntityCustomers: {"Customer", "покупателей"},
+}
+
+// fieldGoTypes задает тип поля структуры и параметры тега для типов пользовательских полей.
+// Поля остальных типов в структуры не попадают.
+var fieldGoTypes = map[string]struct {
+	goType  string
+	tagOpts string
+}{
+	custom_fields.TypeText:          {"*string", ""},
+	custom_fields.TypeTextarea:      {"*string", ""},
+	custom_fields.TypeURL:           {"*string", ""},
+	custom_fields.TypeStreetAddress: {"*string", ""},
+	custom_fields.TypeTrackingData:  {"*string", ""},
+	custom_fields.TypeMultitext:     {"[]string", ""},
+	custom_fields.TypeNumeric:       {"*float64", ""},
+	custom_fields.TypePrice:         {"*float64", ""},
+	custom_fields.TypeMonetary:      {"*float64", ""},
+	custom_fields.TypeCheckbox:      {"*bool", ""},
+	custom_fields.TypeDate:          {"*time.Time", ""},
+	custom_fields.TypeDateTime:      {"*time.Time", ""},
+	custom_fields.TypeBirthday:      {"*time.Time", ""},
+	custom_fields.TypeSelect:        {"*int", ",enum_id"},
+	custom_fields.TypeRadiobutton:   {"*int", ",enum_id"},
+	custom_fields.TypeMultiselect:   {"[]int", ",enum_id"},
+	custom_fields.TypeSmartAddress:  {"*custom_fields.Address", ""},
+	custom_fields.TypeLegalEntity:   {"*custom_fields.LegalEntityValue", ""},
+}
+
+// generator формирует исходный код с константами и структурами
+type generator struct {
+	buf   bytes.Buffer
+	names map[string]bool
+}
+
+// Generate формирует Go-файл пакета pkg с константами и типизированными структурами по схеме аккаунта.
+// Результат зависит только от содержимого схемы.
+func Generate(s *schema.Schema, pkg string) ([]byte, error) {
+	g := &generator{names: make(map[string]bool)}
+
+	body := g.body(s)
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by amogen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Пакет %s содержит константы и структуры, сгенерированные по схеме аккаунта amoCRM.\n", pkg)
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+
+	// Стандартная библиотека и пакеты SDK разделяются пустой строкой
+	var imports []string
+	if strings.Contains(body, "time.Time") {
+		imports = append(imports, `"time"`)
+	}
+	if strings.Contains(body, "custom_fields.") {
+		if len(imports) > 0 {
+			imports = append(imports, "")
+		}
+		imports = append(imports, `"github.com/chudno/amo_crm_sdk/utils/custom_fields"`)
+	}
+	if len(imports) > 0 {
+		fmt.Fprintf(&out, "import (\n\t%s\n)\n\n", strings.Join(imports, "\n\t"))
+	}
+	out.WriteString(body)
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("не удалось отформатировать сгенерированный код: %v", err)
+	}
+	return formatted, nil
+}
+
+// body формирует константы и структуры
+func (g *generator) body(s *schema.Schema) string {
+	for _, entityType := range schema.Entities {
+		g.fieldConstants(s, entityType)
+	}
+	g.pipelineConstants(s)
+	g.userConstants(s)
+	g.taskTypeConstants(s)
+	for _, entityType := range schema.Entities {
+		g.fieldsStruct(s, entityType)
+	}
+	return g.buf.String()
+}
+
+// fieldConstants формирует константы ID полей сущности и их вариантов
+func (g *generator) fieldConstants(s *schema.Schema, entityType custom_fields.EntityType) {
+	fields := s.Fields(entityType)
+	if len(fields) == 0 {
+		return
+	}
+	entity := entityNames[entityType]
+
+	fmt.Fprintf(&g.buf, "// ID пользовательских полей %s\nconst (\n", entity.title)
+	for _, field := range fields {
+		name := g.unique(entity.prefix+"Field"+fieldName(field), field.ID)
+		fmt.Fprintf(&g.buf, "\t%s = %d // %s (%s)\n", name, field.ID, comment(field.Name), field.Type)
+	}
+	g.buf.WriteString(")\n\n")
+
+	for _, field := range fields {
+		if len(field.Enums) == 0 {
+			continue
+		}
+		fmt.Fprintf(&g.buf, "// Варианты значений поля %q %s\nconst (\n", comment(field.Name), entity.title)
+		for _, enum := range field.Enums {
+			name := g.unique(entity.prefix+fieldName(field)+identifier(enum.Value, "Enum"), enum.ID)
+			fmt.Fprintf(&g.buf, "\t%s = %d // %s\n", name, enum.ID, comment(enum.Value))
+		}
+		g.buf.WriteString(")\n\n")
+	}
+}
+
+// pipelineConstants формирует константы воронок и этапов
+func (g *generator) pipelineConstants(s *schema.Schema) {
+	if len(s.Pipelines) == 0 {
+		return
+	}
+
+	g.buf.WriteString("// Воронки и этапы\nconst (\n")
+	for _, pipeline := range s.Pipelines {
+		pipelineName := g.unique("Pipeline"+identifier(pipeline.Name, "Pipeline"), pipeline.ID)
+		fmt.Fprintf(&g.buf, "\t%s = %d // %s\n", pipelineName, pipeline.ID, comment(pipeline.Name))
+		for _, status := range pipeline.Statuses {
+			name := g.unique(pipelineName+"Status"+identifier(status.Name, "Status"), status.ID)
+			fmt.Fprintf(&g.buf, "\t%s = %d // %s\n", name, status.ID, comment(status.Name))
+		}
+	}
+	g.buf.WriteString(")\n\n")
+}
+
+// userConstants формирует константы пользователей
+func (g *generator) userConstants(s *schema.Schema) {
+	if len(s.Users) == 0 {
+		return
+	}
+
+	g.buf.WriteString("// Пользователи\nconst (\n")
+	for _, user := range s.Users {
+		name := g.unique("User"+identifier(user.Name, "User"), user.ID)
+		fmt.Fprintf(&g.buf, "\t%s = %d // %s\n", name, user.ID, comment(user.Name))
+	}
+	g.buf.WriteString(")\n\n")
+}
+
+// taskTypeConstants формирует константы типов задач
+func (g *generator) taskTypeConstants(s *schema.Schema) {
+	if len(s.TaskTypes) == 0 {
+		return
+	}
+
+	g.buf.WriteString("// Типы задач\nconst (\n")
+	for _, taskType := range s.TaskTypes {
+		name := g.unique("TaskType"+identifier(taskType.Name, "TaskType"), taskType.ID)
+		fmt.Fprintf(&g.buf, "\t%s = %d // %s\n", name, taskType.ID, comment(taskType.Name))
+	}
+	g.buf.WriteString(")\n\n")
+}
+
+// fieldsStruct формирует структуру с полями сущности для custom_fields.MarshalFields
+func (g *generator) fieldsStruct(s *schema.Schema, entityType custom_fields.EntityType) {
+	fields := s.Fields(entityType)
+	if len(fields) == 0 {
+		return
+	}
+	entity := entityNames[entityType]
+	structName := entity.prefix + "Fields"
+
+	fmt.Fprintf(&g.buf, "// %s содержит пользовательские поля %s.\n", structName, entity.title)
+	fmt.Fprintf(&g.buf, "// Используйте custom_fields.MarshalFields и custom_fields.UnmarshalFields для преобразования.\n")
+	fmt.Fprintf(&g.buf, "type %s struct {\n", structName)
+
+	used := make(map[string]bool)
+	for _, field := range fields {
+		goType, ok := fieldGoTypes[field.Type]
+		if !ok {
+			fmt.Fprintf(&g.buf, "\t// Поле %d %q типа %s не поддерживается\n", field.ID, comment(field.Name), field.Type)
+			continue
+		}
+
+		name := fieldName(field)
+		if used[name] {
+			name += strconv.Itoa(field.ID)
+		}
+		used[name] = true
+
+		fmt.Fprintf(&g.buf, "\t%s %s `amo:\"field_id=%d%s\"` // %s\n", name, goType.goType, field.ID, goType.tagOpts, comment(field.Name))
+	}
+	g.buf.WriteString("}\n\n")
+}
+
+// unique возвращает имя константы, добавляя ID при совпадении с уже использованным именем
+func (g *generator) unique(name string, id int) string {
+	if g.names[name] {
+		name += strconv.Itoa(id)
+	}
+	g.names[name] = true
+	return name
+}
+
+// fieldName возвращает имя поля: по коду, если он задан, иначе по названию
+func fieldName(field custom_fields.CustomField) string {
+	if field.Code != "" {
+		return identifier(field.Code, "Field"+strconv.Itoa(field.ID))
+	}
+	return identifier(field.Name, "Field"+strconv.Itoa(field.ID))
+}
+
+// translit - транслитерация кириллицы для имен констант
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// identifier преобразует произвольное название в экспортируемый Go-идентификатор в стиле CamelCase.
+// Если в названии нет букв и цифр, используется fallback.
+func identifier(name, fallback string) string {
+	var words []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range name {
+		lower := unicode.ToLower(r)
+		switch {
+		case translit[lower] != "" || lower == 'ъ' || lower == 'ь':
+			word.WriteString(translit[lower])
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			// Граница слова в camelCase: "leadSource" -> "lead", "Source"
+			if unicode.IsUpper(r) && word.Len() > 0 && !isUpperWord(word.String()) {
+				flush()
+			}
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	var result strings.Builder
+	for _, w := range words {
+		w = strings.ToLower(w)
+		result.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	if result.Len() == 0 {
+		return fallback
+	}
+	if unicode.IsDigit(rune(result.String()[0])) {
+		return fallback + result.String()
+	}
+	return result.String()
+}
+
+// isUpperWord проверяет, состоит ли слово только из заглавных букв и цифр
+func isUpperWord(word string) bool {
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// comment убирает переводы строк из текста комментария
+func comment(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
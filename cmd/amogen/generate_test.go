@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/utils/schema"
+)
+
+// loadTestSchema читает снимок схемы из testdata
+func loadTestSchema(t *testing.T) *schema.Schema {
+	file, err := os.Open(filepath.Join("testdata", "snapshot.json"))
+	if err != nil {
+		t.Fatalf("Не удалось открыть снимок: %v", err)
+	}
+	defer file.Close()
+
+	s, err := schema.ReadSnapshot(file)
+	if err != nil {
+		t.Fatalf("Ошибка при чтении снимка: %v", err)
+	}
+	return s
+}
+
+func TestGenerate(t *testing.T) {
+	code, err := Generate(loadTestSchema(t), "amoschema")
+	if err != nil {
+		t.Fatalf("Ошибка при генерации: %v", err)
+	}
+
+	golden, err := ioutil.ReadFile(filepath.Join("testdata", "schema.go.golden"))
+	if err != nil {
+		t.Fatalf("Не удалось прочитать эталон: %v", err)
+	}
+	if !bytes.Equal(code, golden) {
+		t.Errorf("Сгенерированный код отличается от testdata/schema.go.golden:\n%s", code)
+	}
+
+	// Повторная генерация по заново прочитанному снимку дает тот же результат
+	again, err := Generate(loadTestSchema(t), "amoschema")
+	if err != nil {
+		t.Fatalf("Ошибка при повторной генерации: %v", err)
+	}
+	if !bytes.Equal(code, again) {
+		t.Error("Результат генерации не детерминирован")
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := map[string]string{
+		"Бюджет клиента": "ByudzhetKlienta",
+		"LEAD_SOURCE":    "LeadSource",
+		"utm_source":     "UtmSource",
+		"leadSource":     "LeadSource",
+		"IP-телефония":   "IpTelefoniya",
+		"1С: Интеграция": "Field1sIntegratsiya",
+		"!!!":            "Field",
+	}
+
+	for name, expected := range tests {
+		if actual := identifier(name, "Field"); actual != expected {
+			t.Errorf("identifier(%q) = %q, ожидалось %q", name, actual, expected)
+		}
+	}
+}
+
+func TestRunRequiresSource(t *testing.T) {
+	os.Unsetenv("AMO_URL")
+	os.Unsetenv("AMO_TOKEN")
+	if err := run([]string{"-package", "amoschema"}); err == nil {
+		t.Error("Ожидалась ошибка без снимка и параметров подключения, но ее не было")
+	}
+}
@@ -0,0 +1,87 @@
+// Команда amogen генерирует Go-файл с именованными константами ID пользовательских полей,
+// вариантов списков, воронок, этапов, пользователей и типов задач, а также типизированными
+// структурами полей сделок, контактов, компаний и покупателей.
+//
+// Схема загружается из аккаунта amoCRM или из ранее сохраненного JSON-снимка:
+//
+//	amogen -url https://example.amocrm.ru -token $AMO_TOKEN -save-snapshot schema.json -out amoschema/schema.go
+//	amogen -snapshot schema.json -package amoschema -out amoschema/schema.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/utils/schema"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "amogen:", err)
+		os.Exit(1)
+	}
+}
+
+// run разбирает аргументы командной строки и выполняет генерацию
+func run(args []string) error {
+	flags := flag.NewFlagSet("amogen", flag.ContinueOnError)
+	snapshotPath := flags.String("snapshot", "", "путь к JSON-снимку схемы аккаунта")
+	baseURL := flags.String("url", os.Getenv("AMO_URL"), "адрес аккаунта amoCRM (по умолчанию $AMO_URL)")
+	token := flags.String("token", os.Getenv("AMO_TOKEN"), "токен доступа (по умолчанию $AMO_TOKEN)")
+	savePath := flags.String("save-snapshot", "", "сохранить загруженную из API схему в JSON-снимок")
+	pkg := flags.String("package", "amoschema", "имя пакета сгенерированного файла")
+	outPath := flags.String("out", "", "путь к сгенерированному файлу (по умолчанию stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	accountSchema, err := loadSchema(*snapshotPath, *baseURL, *token)
+	if err != nil {
+		return err
+	}
+
+	if *savePath != "" {
+		file, err := os.Create(*savePath)
+		if err != nil {
+			return err
+		}
+		if err := accountSchema.WriteSnapshot(file); err != nil {
+			file.Close()
+			return err
+		}
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	code, err := Generate(accountSchema, *pkg)
+	if err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(*outPath, code, 0644)
+}
+
+// loadSchema загружает схему из снимка или из API
+func loadSchema(snapshotPath, baseURL, token string) (*schema.Schema, error) {
+	if snapshotPath != "" {
+		file, err := os.Open(snapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return schema.ReadSnapshot(file)
+	}
+
+	if baseURL == "" || token == "" {
+		return nil, fmt.Errorf("укажите -snapshot или -url и -token")
+	}
+	return schema.Fetch(client.NewClient(baseURL, token))
+}
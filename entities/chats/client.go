@@ -0,0 +1,194 @@
+// Пакет chats предоставляет клиент API чатов amoCRM (amojo).
+//
+// API чатов используется интеграциями, которые подключают к аккаунту собственный канал
+// мессенджера. Запросы подписываются секретом канала: заголовок X-Signature содержит
+// HMAC-SHA1 от метода, Content-MD5, типа содержимого, даты и пути запроса.
+package chats
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL адрес API чатов amoCRM по умолчанию.
+const DefaultBaseURL = "https://amojo.amocrm.ru"
+
+// contentType тип содержимого запросов API чатов
+const contentType = "application/json"
+
+// Client клиент API чатов для одного канала.
+type Client struct {
+	baseURL    string
+	channelID  string
+	secret     string
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// ClientOption задает параметры клиента API чатов.
+type ClientOption func(*Client)
+
+// WithBaseURL задает адрес API чатов, например https://amojo.kommo.com.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient задает HTTP-клиент для запросов.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient создает клиент API чатов для канала с ID channelID и секретом secret,
+// выданными amoCRM при регистрации канала.
+func NewClient(channelID, secret string, options ...ClientOption) *Client {
+	c := &Client{
+		baseURL:   DefaultBaseURL,
+		channelID: channelID,
+		secret:    secret,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		now: time.Now,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// ChannelID возвращает ID канала.
+func (c *Client) ChannelID() string {
+	return c.channelID
+}
+
+// ScopeID возвращает ID подключения канала к аккаунту с amojo ID accountID.
+// Такое же значение возвращает Connect в поле ScopeID.
+func (c *Client) ScopeID(accountID string) string {
+	return c.channelID + "_" + accountID
+}
+
+// ContentMD5 возвращает значение заголовка Content-MD5 для тела запроса.
+func ContentMD5(body []byte) string {
+	sum := md5.Sum(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign возвращает подпись запроса для заголовка X-Signature:
+// HMAC-SHA1 с секретом канала от строк метода, Content-MD5, типа содержимого, даты и пути, разделенных переводом строки.
+func Sign(secret, method, contentMD5, contentType, date, path string) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{strings.ToUpper(method), contentMD5, contentType, date, path}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// newRequest создает подписанный запрос к API чатов
+func (c *Client) newRequest(method, path string, payload interface{}) (*http.Request, error) {
+	var body []byte
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	date := c.now().UTC().Format(time.RFC1123Z)
+	checksum := ContentMD5(body)
+
+	req.Header.Set("Date", date)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-MD5", checksum)
+	req.Header.Set("X-Signature", Sign(c.secret, method, checksum, contentType, date, req.URL.RequestURI()))
+
+	return req, nil
+}
+
+// do выполняет запрос и разбирает ответ в result, если он задан
+func (c *Client) do(method, path string, payload, result interface{}, statuses ...int) error {
+	req, err := c.newRequest(method, path, payload)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if !expectedStatus(resp.StatusCode, statuses) {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if len(message) > 0 {
+			return fmt.Errorf("неожиданный статус-код: %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+		}
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// expectedStatus проверяет статус-код ответа, по умолчанию ожидается 200
+func expectedStatus(code int, statuses []int) bool {
+	if len(statuses) == 0 {
+		return code == http.StatusOK
+	}
+	for _, status := range statuses {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Connection подключение канала к аккаунту.
+type Connection struct {
+	AccountID      string `json:"account_id"`
+	ScopeID        string `json:"scope_id"`
+	Title          string `json:"title"`
+	HookAPIVersion string `json:"hook_api_version"`
+}
+
+// Connect подключает канал к аккаунту с amojo ID accountID.
+// Полученный ScopeID используется во всех запросах по чатам аккаунта.
+func (c *Client) Connect(accountID, title string) (*Connection, error) {
+	payload := map[string]string{
+		"account_id":       accountID,
+		"title":            title,
+		"hook_api_version": "v2",
+	}
+
+	var connection Connection
+	if err := c.do("POST", fmt.Sprintf("/v2/origin/custom/%s/connect", c.channelID), payload, &connection); err != nil {
+		return nil, err
+	}
+	return &connection, nil
+}
+
+// Disconnect отключает канал от аккаунта с amojo ID accountID.
+func (c *Client) Disconnect(accountID string) error {
+	payload := map[string]string{"account_id": accountID}
+	return c.do("DELETE", fmt.Sprintf("/v2/origin/custom/%s/disconnect", c.channelID), payload, nil,
+		http.StatusOK, http.StatusNoContent)
+}
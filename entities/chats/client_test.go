@@ -0,0 +1,138 @@
+package chats
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const (
+	testChannelID = "f90ba33d-c9d9-44da-b76c-c349b0ecbe41"
+	testSecret    = "5a44c5dff55f3c15a4cce8d7c4cc27e207c7e189"
+	testAccountID = "af9945ff-1490-4cad-807d-945c15d88bec"
+	testDate      = "Thu, 01 Jun 2023 12:00:00 +0000"
+)
+
+// newTestClient создает клиент с фиксированным временем и сервер с проверкой подписи
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("Ошибка при чтении тела запроса: %v", err)
+		}
+
+		if checksum := r.Header.Get("Content-MD5"); checksum != ContentMD5(body) {
+			t.Errorf("Неверный заголовок Content-MD5: %s", checksum)
+		}
+		if r.Header.Get("Date") != testDate {
+			t.Errorf("Неверный заголовок Date: %s", r.Header.Get("Date"))
+		}
+		expected := Sign(testSecret, r.Method, ContentMD5(body), r.Header.Get("Content-Type"), testDate, r.URL.RequestURI())
+		if signature := r.Header.Get("X-Signature"); signature != expected {
+			t.Errorf("Неверная подпись запроса: %s, ожидалась %s", signature, expected)
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewClient(testChannelID, testSecret, WithBaseURL(server.URL+"/"))
+	c.now = func() time.Time {
+		return time.Date(2023, 6, 1, 15, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	}
+	return c
+}
+
+func TestSign(t *testing.T) {
+	// Подписи рассчитаны независимо: hmac.new(secret, строка, hashlib.sha1).hexdigest()
+	tests := []struct {
+		name, method, body, path, expectedMD5, expected string
+	}{
+		{
+			name:        "Подключение канала",
+			method:      "POST",
+			body:        `{"account_id":"af9945ff-1490-4cad-807d-945c15d88bec","title":"ChatIntegration","hook_api_version":"v2"}`,
+			path:        "/v2/origin/custom/f90ba33d-c9d9-44da-b76c-c349b0ecbe41/connect",
+			expectedMD5: "7e961b6b58c7f8d85f273526d3757f92",
+			expected:    "6a0045bc4ebacf403046ef38bbcb9a4955de8cd5",
+		},
+		{
+			name:        "Запрос без тела",
+			method:      "get",
+			path:        "/v2/origin/custom/scope/chats/ref/history?limit=50&offset=0",
+			expectedMD5: "d41d8cd98f00b204e9800998ecf8427e",
+			expected:    "f2ff475676e9500b577b2d416d5cb9a8fa8613ad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checksum := ContentMD5([]byte(tt.body))
+			if checksum != tt.expectedMD5 {
+				t.Errorf("Ожидался Content-MD5 %s, получен %s", tt.expectedMD5, checksum)
+			}
+			if signature := Sign(testSecret, tt.method, checksum, "application/json", testDate, tt.path); signature != tt.expected {
+				t.Errorf("Ожидалась подпись %s, получена %s", tt.expected, signature)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/v2/origin/custom/"+testChannelID+"/connect" {
+			t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		if body["account_id"] != testAccountID || body["title"] != "ChatIntegration" || body["hook_api_version"] != "v2" {
+			t.Errorf("Неверное тело запроса: %v", body)
+		}
+
+		_, _ = w.Write([]byte(`{
+			"account_id": "af9945ff-1490-4cad-807d-945c15d88bec",
+			"scope_id": "f90ba33d-c9d9-44da-b76c-c349b0ecbe41_af9945ff-1490-4cad-807d-945c15d88bec",
+			"title": "ChatIntegration",
+			"hook_api_version": "v2"
+		}`))
+	})
+
+	connection, err := c.Connect(testAccountID, "ChatIntegration")
+	if err != nil {
+		t.Fatalf("Ошибка при подключении канала: %v", err)
+	}
+	if connection.ScopeID != c.ScopeID(testAccountID) {
+		t.Errorf("Ожидался scope_id %s, получен %s", c.ScopeID(testAccountID), connection.ScopeID)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/v2/origin/custom/"+testChannelID+"/disconnect" {
+			t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	if err := c.Disconnect(testAccountID); err != nil {
+		t.Fatalf("Ошибка при отключении канала: %v", err)
+	}
+}
+
+func TestClientError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid signature"}`, http.StatusForbidden)
+	})
+
+	if _, err := c.Connect(testAccountID, "ChatIntegration"); err == nil {
+		t.Error("Ожидалась ошибка при статусе 403")
+	}
+}
@@ -0,0 +1,285 @@
+package chats
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// MessageType тип сообщения.
+type MessageType string
+
+const (
+	// MessageTypeText текстовое сообщение
+	MessageTypeText MessageType = "text"
+	// MessageTypePicture изображение
+	MessageTypePicture MessageType = "picture"
+	// MessageTypeVideo видео
+	MessageTypeVideo MessageType = "video"
+	// MessageTypeFile файл
+	MessageTypeFile MessageType = "file"
+	// MessageTypeVoice голосовое сообщение
+	MessageTypeVoice MessageType = "voice"
+	// MessageTypeSticker стикер
+	MessageTypeSticker MessageType = "sticker"
+	// MessageTypeLocation геопозиция
+	MessageTypeLocation MessageType = "location"
+	// MessageTypeContact контакт
+	MessageTypeContact MessageType = "contact"
+)
+
+// DeliveryStatus статус доставки сообщения.
+type DeliveryStatus int
+
+const (
+	// DeliveryStatusDelivered сообщение доставлено
+	DeliveryStatusDelivered DeliveryStatus = 1
+	// DeliveryStatusRead сообщение прочитано
+	DeliveryStatusRead DeliveryStatus = 2
+	// DeliveryStatusError ошибка доставки сообщения
+	DeliveryStatusError DeliveryStatus = -1
+)
+
+// Profile контактные данные участника чата.
+type Profile struct {
+	Phone string `json:"phone,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+// Participant участник чата.
+// ID - ID пользователя на стороне интеграции, RefID - ID пользователя в amojo.
+type Participant struct {
+	ID          string   `json:"id,omitempty"`
+	RefID       string   `json:"ref_id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Avatar      string   `json:"avatar,omitempty"`
+	Profile     *Profile `json:"profile,omitempty"`
+	ProfileLink string   `json:"profile_link,omitempty"`
+}
+
+// Source источник чата, если канал подключен к нескольким источникам.
+type Source struct {
+	ExternalID string `json:"external_id"`
+}
+
+// Location геопозиция.
+type Location struct {
+	Lon float64 `json:"lon"`
+	Lat float64 `json:"lat"`
+}
+
+// Contact контакт в сообщении.
+type Contact struct {
+	Name  string `json:"name"`
+	Phone string `json:"phone"`
+}
+
+// Message содержимое сообщения.
+type Message struct {
+	Type     MessageType `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	Media    string      `json:"media,omitempty"`
+	FileName string      `json:"file_name,omitempty"`
+	FileSize int         `json:"file_size,omitempty"`
+	Location *Location   `json:"location,omitempty"`
+	Contact  *Contact    `json:"contact,omitempty"`
+}
+
+// TextMessage возвращает текстовое сообщение.
+func TextMessage(text string) Message {
+	return Message{Type: MessageTypeText, Text: text}
+}
+
+// PictureMessage возвращает сообщение с изображением, доступным по ссылке media.
+func PictureMessage(media, caption string) Message {
+	return Message{Type: MessageTypePicture, Media: media, Text: caption}
+}
+
+// FileMessage возвращает сообщение с файлом, доступным по ссылке media.
+func FileMessage(media, fileName string, fileSize int) Message {
+	return Message{Type: MessageTypeFile, Media: media, FileName: fileName, FileSize: fileSize}
+}
+
+// LocationMessage возвращает сообщение с геопозицией.
+func LocationMessage(lat, lon float64) Message {
+	return Message{Type: MessageTypeLocation, Location: &Location{Lon: lon, Lat: lat}}
+}
+
+// ContactMessage возвращает сообщение с контактом.
+func ContactMessage(name, phone string) Message {
+	return Message{Type: MessageTypeContact, Contact: &Contact{Name: name, Phone: phone}}
+}
+
+// NewMessage сообщение для отправки в amoCRM.
+// Для сообщений клиента задается Sender с ID клиента, для сообщений менеджера при импорте -
+// Sender с RefID пользователя amojo и Receiver с ID клиента.
+type NewMessage struct {
+	MsgID             string       `json:"msgid"`
+	ConversationID    string       `json:"conversation_id"`
+	ConversationRefID string       `json:"conversation_ref_id,omitempty"`
+	Timestamp         int64        `json:"timestamp"`
+	MsecTimestamp     int64        `json:"msec_timestamp"`
+	Sender            Participant  `json:"sender"`
+	Receiver          *Participant `json:"receiver,omitempty"`
+	Message           Message      `json:"message"`
+	Silent            bool         `json:"silent"`
+	Source            *Source      `json:"source,omitempty"`
+}
+
+// SentMessage результат отправки сообщения.
+type SentMessage struct {
+	ConversationID string `json:"conversation_id"`
+	SenderID       string `json:"sender_id"`
+	ReceiverID     string `json:"receiver_id"`
+	MsgID          string `json:"msgid"`
+	RefID          string `json:"ref_id"`
+}
+
+// SendMessage отправляет в amoCRM новое сообщение из чата на стороне интеграции.
+// Если время сообщения не задано, используется текущее.
+func (c *Client) SendMessage(scopeID string, message *NewMessage) (*SentMessage, error) {
+	return c.sendMessage(scopeID, message)
+}
+
+// ImportMessage импортирует сообщение из истории переписки, например ответ менеджера,
+// отправленный вне amoCRM. Импортированное сообщение не создает уведомлений.
+func (c *Client) ImportMessage(scopeID string, message *NewMessage) (*SentMessage, error) {
+	imported := *message
+	imported.Silent = true
+	return c.sendMessage(scopeID, &imported)
+}
+
+// sendMessage отправляет событие нового сообщения
+func (c *Client) sendMessage(scopeID string, message *NewMessage) (*SentMessage, error) {
+	if message.MsgID == "" || message.ConversationID == "" {
+		return nil, fmt.Errorf("не заданы ID сообщения и ID чата")
+	}
+
+	payload := *message
+	if payload.Timestamp == 0 && payload.MsecTimestamp == 0 {
+		now := c.now()
+		payload.Timestamp = now.Unix()
+		payload.MsecTimestamp = now.UnixNano() / int64(time.Millisecond)
+	} else if payload.MsecTimestamp == 0 {
+		payload.MsecTimestamp = payload.Timestamp * 1000
+	} else if payload.Timestamp == 0 {
+		payload.Timestamp = payload.MsecTimestamp / 1000
+	}
+
+	request := struct {
+		EventType string      `json:"event_type"`
+		Payload   *NewMessage `json:"payload"`
+	}{"new_message", &payload}
+
+	var response struct {
+		NewMessage SentMessage `json:"new_message"`
+	}
+	if err := c.do("POST", fmt.Sprintf("/v2/origin/custom/%s", scopeID), request, &response); err != nil {
+		return nil, err
+	}
+	return &response.NewMessage, nil
+}
+
+// NewChat чат для создания в amoCRM.
+type NewChat struct {
+	ConversationID string      `json:"conversation_id"`
+	Source         *Source     `json:"source,omitempty"`
+	User           Participant `json:"user"`
+}
+
+// ChatUser клиент созданного чата.
+type ChatUser struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id"`
+	Name     string `json:"name"`
+	Avatar   string `json:"avatar,omitempty"`
+	Phone    string `json:"phone,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// Chat созданный чат. ID - ID чата в amojo, используется как ConversationRefID.
+type Chat struct {
+	ID   string   `json:"id"`
+	User ChatUser `json:"user"`
+}
+
+// CreateChat создает чат с клиентом до получения первого сообщения.
+func (c *Client) CreateChat(scopeID string, chat *NewChat) (*Chat, error) {
+	var created Chat
+	if err := c.do("POST", fmt.Sprintf("/v2/origin/custom/%s/chats", scopeID), chat, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// SendTyping сообщает amoCRM, что участник чата с ID senderID печатает сообщение.
+func (c *Client) SendTyping(scopeID, conversationID, senderID string) error {
+	payload := struct {
+		ConversationID string      `json:"conversation_id"`
+		Sender         Participant `json:"sender"`
+	}{conversationID, Participant{ID: senderID}}
+
+	return c.do("POST", fmt.Sprintf("/v2/origin/custom/%s/typing", scopeID), payload, nil,
+		http.StatusOK, http.StatusNoContent)
+}
+
+// StatusUpdate статус доставки сообщения, отправленного из amoCRM.
+type StatusUpdate struct {
+	MsgID          string         `json:"msgid"`
+	DeliveryStatus DeliveryStatus `json:"delivery_status"`
+	ErrorCode      int            `json:"error_code,omitempty"`
+	Error          string         `json:"error,omitempty"`
+}
+
+// SetDeliveryStatus обновляет в amojo статус доставки сообщения, отправленного менеджером из amoCRM.
+func (c *Client) SetDeliveryStatus(scopeID string, status *StatusUpdate) error {
+	if status.MsgID == "" {
+		return fmt.Errorf("не задан ID сообщения")
+	}
+	return c.do("POST", fmt.Sprintf("/v2/origin/custom/%s/%s/delivery_status", scopeID, url.PathEscape(status.MsgID)),
+		status, nil, http.StatusOK, http.StatusNoContent)
+}
+
+// MessageContent содержимое сообщения из amojo с ID и миниатюрой.
+type MessageContent struct {
+	Message
+	ID        string `json:"id"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+}
+
+// HistoryMessage сообщение из истории чата.
+type HistoryMessage struct {
+	Timestamp int64          `json:"timestamp"`
+	Sender    Participant    `json:"sender"`
+	Receiver  *Participant   `json:"receiver,omitempty"`
+	Message   MessageContent `json:"message"`
+}
+
+// GetHistory получает историю чата с amojo ID conversationRefID.
+func (c *Client) GetHistory(scopeID, conversationRefID string, offset, limit int) ([]HistoryMessage, error) {
+	params := url.Values{}
+	if offset > 0 {
+		params.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := fmt.Sprintf("/v2/origin/custom/%s/chats/%s/history", scopeID, url.PathEscape(conversationRefID))
+	if len(params) > 0 {
+		path += "?" + params.Encode()
+	}
+
+	var response struct {
+		Messages []HistoryMessage `json:"messages"`
+	}
+	if err := c.do("GET", path, nil, &response, http.StatusOK, http.StatusNoContent); err != nil {
+		return nil, err
+	}
+	if response.Messages == nil {
+		return []HistoryMessage{}, nil
+	}
+	return response.Messages, nil
+}
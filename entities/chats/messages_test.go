@@ -0,0 +1,206 @@
+package chats
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestSendMessage(t *testing.T) {
+	scopeID := testChannelID + "_" + testAccountID
+
+	tests := []struct {
+		name     string
+		message  Message
+		expected string
+	}{
+		{"Текст", TextMessage("Здравствуйте"), `{"type":"text","text":"Здравствуйте"}`},
+		{"Изображение", PictureMessage("https://example.com/photo.jpg", "Фото"),
+			`{"type":"picture","text":"Фото","media":"https://example.com/photo.jpg"}`},
+		{"Файл", FileMessage("https://example.com/price.pdf", "price.pdf", 2048),
+			`{"type":"file","media":"https://example.com/price.pdf","file_name":"price.pdf","file_size":2048}`},
+		{"Геопозиция", LocationMessage(55.75, 37.61), `{"type":"location","location":{"lon":37.61,"lat":55.75}}`},
+		{"Контакт", ContactMessage("Иван", "+79990000000"), `{"type":"contact","contact":{"name":"Иван","phone":"+79990000000"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "POST" || r.URL.Path != "/v2/origin/custom/"+scopeID {
+					t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+				}
+
+				var body struct {
+					EventType string `json:"event_type"`
+					Payload   struct {
+						MsgID         string          `json:"msgid"`
+						Timestamp     int64           `json:"timestamp"`
+						MsecTimestamp int64           `json:"msec_timestamp"`
+						Silent        bool            `json:"silent"`
+						Sender        Participant     `json:"sender"`
+						Message       json.RawMessage `json:"message"`
+					} `json:"payload"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+				}
+				if body.EventType != "new_message" || body.Payload.Silent {
+					t.Errorf("Неверное событие: %s, silent=%v", body.EventType, body.Payload.Silent)
+				}
+				if body.Payload.Timestamp != 1685620800 || body.Payload.MsecTimestamp != 1685620800000 {
+					t.Errorf("Неверное время сообщения: %d, %d", body.Payload.Timestamp, body.Payload.MsecTimestamp)
+				}
+				if string(body.Payload.Message) != tt.expected {
+					t.Errorf("Ожидалось сообщение %s, получено %s", tt.expected, body.Payload.Message)
+				}
+
+				_, _ = w.Write([]byte(`{"new_message":{"conversation_id":"conv-1","sender_id":"amo-sender","msgid":"amo-msg-1","ref_id":"msg-1"}}`))
+			})
+
+			sent, err := c.SendMessage(scopeID, &NewMessage{
+				MsgID:          "msg-1",
+				ConversationID: "conv-1",
+				Sender:         Participant{ID: "client-1", Name: "Иван"},
+				Message:        tt.message,
+			})
+			if err != nil {
+				t.Fatalf("Ошибка при отправке сообщения: %v", err)
+			}
+			if sent.MsgID != "amo-msg-1" || sent.RefID != "msg-1" {
+				t.Errorf("Неверный результат отправки: %+v", sent)
+			}
+		})
+	}
+}
+
+func TestImportMessage(t *testing.T) {
+	scopeID := testChannelID + "_" + testAccountID
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Payload NewMessage `json:"payload"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		if !body.Payload.Silent {
+			t.Error("Импортируемое сообщение должно отправляться с silent=true")
+		}
+		if body.Payload.Timestamp != 1600000000 || body.Payload.MsecTimestamp != 1600000000000 {
+			t.Errorf("Неверное время сообщения: %d, %d", body.Payload.Timestamp, body.Payload.MsecTimestamp)
+		}
+		if body.Payload.Sender.RefID != "manager-ref" || body.Payload.Receiver == nil || body.Payload.Receiver.ID != "client-1" {
+			t.Errorf("Неверные участники сообщения: %+v, %+v", body.Payload.Sender, body.Payload.Receiver)
+		}
+		_, _ = w.Write([]byte(`{"new_message":{"msgid":"amo-msg-2"}}`))
+	})
+
+	message := &NewMessage{
+		MsgID:          "msg-2",
+		ConversationID: "conv-1",
+		Timestamp:      1600000000,
+		Sender:         Participant{RefID: "manager-ref"},
+		Receiver:       &Participant{ID: "client-1"},
+		Message:        TextMessage("Ответ менеджера"),
+	}
+	if _, err := c.ImportMessage(scopeID, message); err != nil {
+		t.Fatalf("Ошибка при импорте сообщения: %v", err)
+	}
+	if message.Silent {
+		t.Error("ImportMessage не должен изменять переданное сообщение")
+	}
+
+	if _, err := c.SendMessage(scopeID, &NewMessage{Message: TextMessage("Без ID")}); err == nil {
+		t.Error("Ожидалась ошибка для сообщения без ID")
+	}
+}
+
+func TestCreateChat(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/origin/custom/scope/chats" {
+			t.Errorf("Неверный путь запроса: %s", r.URL.Path)
+		}
+		var chat NewChat
+		if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		if chat.ConversationID != "conv-1" || chat.User.Profile == nil || chat.User.Profile.Phone != "+79990000000" {
+			t.Errorf("Неверное тело запроса: %+v", chat)
+		}
+		_, _ = w.Write([]byte(`{"id":"chat-ref-1","user":{"id":"user-ref-1","client_id":"client-1","name":"Иван","phone":"+79990000000"}}`))
+	})
+
+	chat, err := c.CreateChat("scope", &NewChat{
+		ConversationID: "conv-1",
+		User:           Participant{ID: "client-1", Name: "Иван", Profile: &Profile{Phone: "+79990000000"}},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при создании чата: %v", err)
+	}
+	if chat.ID != "chat-ref-1" || chat.User.ClientID != "client-1" {
+		t.Errorf("Неверный результат создания чата: %+v", chat)
+	}
+}
+
+func TestSendTypingAndDeliveryStatus(t *testing.T) {
+	var paths []string
+	var bodies []map[string]interface{}
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := c.SendTyping("scope", "conv-1", "client-1"); err != nil {
+		t.Fatalf("Ошибка при отправке статуса набора текста: %v", err)
+	}
+	err := c.SetDeliveryStatus("scope", &StatusUpdate{MsgID: "amo-msg-1", DeliveryStatus: DeliveryStatusError, ErrorCode: 905, Error: "Пользователь заблокировал бота"})
+	if err != nil {
+		t.Fatalf("Ошибка при обновлении статуса доставки: %v", err)
+	}
+
+	expectedPaths := []string{"/v2/origin/custom/scope/typing", "/v2/origin/custom/scope/amo-msg-1/delivery_status"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("Ожидались пути %v, получено %v", expectedPaths, paths)
+	}
+	if bodies[0]["conversation_id"] != "conv-1" {
+		t.Errorf("Неверное тело запроса набора текста: %v", bodies[0])
+	}
+	if bodies[1]["delivery_status"] != float64(-1) || bodies[1]["error_code"] != float64(905) {
+		t.Errorf("Неверное тело запроса статуса доставки: %v", bodies[1])
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/v2/origin/custom/scope/chats/chat-ref-1/history" {
+			t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("limit") != "50" || r.URL.Query().Get("offset") != "" {
+			t.Errorf("Неверные параметры запроса: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"messages":[
+			{"timestamp":1685620800,"sender":{"id":"user-ref-1","name":"Иван"},"message":{"id":"m1","type":"text","text":"Здравствуйте"}},
+			{"timestamp":1685620860,"sender":{"id":"manager-ref"},"receiver":{"id":"user-ref-1"},"message":{"id":"m2","type":"picture","media":"https://example.com/a.jpg","thumbnail":"https://example.com/a_small.jpg"}}
+		]}`))
+	})
+
+	messages, err := c.GetHistory("scope", "chat-ref-1", 0, 50)
+	if err != nil {
+		t.Fatalf("Ошибка при получении истории чата: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Ожидалось 2 сообщения, получено %d", len(messages))
+	}
+	if messages[0].Message.Text != "Здравствуйте" || messages[1].Message.Type != MessageTypePicture || messages[1].Message.Thumbnail == "" {
+		t.Errorf("Неверное содержимое истории: %+v", messages)
+	}
+	if messages[1].Receiver == nil || messages[1].Receiver.ID != "user-ref-1" {
+		t.Errorf("Неверный получатель сообщения: %+v", messages[1].Receiver)
+	}
+}
@@ -0,0 +1,182 @@
+package chats
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxWebhookBodySize максимальный размер тела вебхука канала.
+const MaxWebhookBodySize = 1 << 20
+
+// WebhookUser участник чата в вебхуке канала.
+// ID - ID пользователя в amojo, ClientID - ID клиента на стороне интеграции.
+type WebhookUser struct {
+	ID          string   `json:"id"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Avatar      string   `json:"avatar,omitempty"`
+	Profile     *Profile `json:"profile,omitempty"`
+	ProfileLink string   `json:"profile_link,omitempty"`
+}
+
+// Conversation чат в вебхуке канала.
+// ID - ID чата в amojo, ClientID - ID чата на стороне интеграции.
+type Conversation struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id,omitempty"`
+}
+
+// OutgoingMessage сообщение, отправленное менеджером из amoCRM.
+type OutgoingMessage struct {
+	Sender        WebhookUser    `json:"sender"`
+	Receiver      WebhookUser    `json:"receiver"`
+	Conversation  Conversation   `json:"conversation"`
+	Source        *Source        `json:"source,omitempty"`
+	Timestamp     int64          `json:"timestamp"`
+	MsecTimestamp int64          `json:"msec_timestamp"`
+	Message       MessageContent `json:"message"`
+}
+
+// WebhookPayload вебхук канала с исходящим сообщением.
+// ScopeID берется из последнего сегмента пути запроса, куда amojo отправляет вебхуки канала.
+type WebhookPayload struct {
+	ScopeID   string          `json:"-"`
+	AccountID string          `json:"account_id"`
+	Time      int64           `json:"time"`
+	Message   OutgoingMessage `json:"message"`
+}
+
+// MessageHandler обрабатывает исходящее сообщение из amoCRM.
+// Если обработчик вернул ошибку, на вебхук отвечается статусом 500.
+type MessageHandler func(ctx context.Context, payload *WebhookPayload) error
+
+// VerifySignature проверяет подпись вебхука канала: заголовок X-Signature содержит
+// HMAC-SHA1 с секретом канала от тела запроса.
+func VerifySignature(secret string, body []byte, signature string) bool {
+	expected, err := hex.DecodeString(strings.TrimSpace(signature))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ParseWebhook разбирает тело вебхука канала.
+func ParseWebhook(body []byte) (*WebhookPayload, error) {
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе вебхука канала: %w", err)
+	}
+	if payload.Message.Conversation.ID == "" && payload.Message.Message.ID == "" {
+		return nil, fmt.Errorf("вебхук канала не содержит сообщения")
+	}
+	return &payload, nil
+}
+
+// WebhookHandler принимает вебхуки канала (http.Handler), проверяет подпись
+// и передает исходящие сообщения в обработчик.
+type WebhookHandler struct {
+	secret  string
+	handler MessageHandler
+}
+
+// NewWebhookHandler создает обработчик вебхуков канала с секретом secret.
+func NewWebhookHandler(secret string, handler MessageHandler) *WebhookHandler {
+	return &WebhookHandler{secret: secret, handler: handler}
+}
+
+// ServeHTTP проверяет подпись вебхука и передает сообщение в обработчик.
+// При неверной подписи отвечает 401, при ошибке разбора - 400, при ошибке обработчика - 500.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, MaxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "ошибка при чтении тела запроса", http.StatusBadRequest)
+		return
+	}
+
+	if !VerifySignature(h.secret, body, req.Header.Get("X-Signature")) {
+		http.Error(w, "неверная подпись", http.StatusUnauthorized)
+		return
+	}
+
+	payload, err := ParseWebhook(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payload.ScopeID = req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]
+
+	if err := h.handler(req.Context(), payload); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// NewReply возвращает ответ клиента на сообщение из вебхука для SendMessage:
+// сообщение отправляется в тот же чат от имени получателя исходного сообщения.
+// Если чат начат менеджером в amoCRM, в вебхуке нет client_id чата и ConversationID ответа
+// нужно задать самостоятельно.
+func (p *WebhookPayload) NewReply(msgID string, message Message) *NewMessage {
+	receiver := p.Message.Receiver
+	return &NewMessage{
+		MsgID:             msgID,
+		ConversationID:    p.Message.Conversation.ClientID,
+		ConversationRefID: p.Message.Conversation.ID,
+		Sender: Participant{
+			ID:          receiver.ClientID,
+			RefID:       receiver.ID,
+			Name:        receiver.Name,
+			Avatar:      receiver.Avatar,
+			Profile:     receiver.Profile,
+			ProfileLink: receiver.ProfileLink,
+		},
+		Message: message,
+		Source:  p.Message.Source,
+	}
+}
+
+// Reply отправляет в amoCRM ответ клиента на сообщение из вебхука.
+// Для чата без client_id, например начатого менеджером в amoCRM, возвращается ошибка:
+// такой ответ отправляется через NewReply и SendMessage с заданным ConversationID.
+func (c *Client) Reply(payload *WebhookPayload, msgID string, message Message) (*SentMessage, error) {
+	if payload.Message.Conversation.ClientID == "" {
+		return nil, fmt.Errorf("в вебхуке не задан client_id чата %s: чат начат в amoCRM, задайте ConversationID ответа из NewReply", payload.Message.Conversation.ID)
+	}
+	return c.SendMessage(payload.ScopeID, payload.NewReply(msgID, message))
+}
+
+// MarkDelivered отмечает сообщение из вебхука доставленным клиенту.
+func (c *Client) MarkDelivered(payload *WebhookPayload) error {
+	return c.SetDeliveryStatus(payload.ScopeID, &StatusUpdate{MsgID: payload.Message.Message.ID, DeliveryStatus: DeliveryStatusDelivered})
+}
+
+// MarkRead отмечает сообщение из вебхука прочитанным клиентом.
+func (c *Client) MarkRead(payload *WebhookPayload) error {
+	return c.SetDeliveryStatus(payload.ScopeID, &StatusUpdate{MsgID: payload.Message.Message.ID, DeliveryStatus: DeliveryStatusRead})
+}
+
+// MarkFailed отмечает, что сообщение из вебхука не удалось доставить клиенту.
+func (c *Client) MarkFailed(payload *WebhookPayload, errorCode int, errorText string) error {
+	return c.SetDeliveryStatus(payload.ScopeID, &StatusUpdate{
+		MsgID:          payload.Message.Message.ID,
+		DeliveryStatus: DeliveryStatusError,
+		ErrorCode:      errorCode,
+		Error:          errorText,
+	})
+}
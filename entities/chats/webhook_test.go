@@ -0,0 +1,173 @@
+package chats
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testWebhookSignature подпись testdata/outgoing_message.json, рассчитанная независимо
+const testWebhookSignature = "1356e98a14821d5c9645e7cb2a764164c2e4e215"
+
+// readWebhookBody читает записанный вебхук канала
+func readWebhookBody(t *testing.T) []byte {
+	body, err := os.ReadFile(filepath.Join("testdata", "outgoing_message.json"))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении тестового вебхука: %v", err)
+	}
+	return body
+}
+
+// postChannelWebhook отправляет вебхук канала в обработчик
+func postChannelWebhook(handler http.Handler, body []byte, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/amojo/"+testChannelID+"_"+testAccountID, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signature", signature)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := readWebhookBody(t)
+
+	if !VerifySignature(testSecret, body, testWebhookSignature) {
+		t.Error("Верная подпись не прошла проверку")
+	}
+	if VerifySignature("other_secret", body, testWebhookSignature) {
+		t.Error("Подпись с другим секретом прошла проверку")
+	}
+	if VerifySignature(testSecret, append(body, ' '), testWebhookSignature) {
+		t.Error("Подпись измененного тела прошла проверку")
+	}
+	if VerifySignature(testSecret, body, "not-hex") {
+		t.Error("Некорректная подпись прошла проверку")
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	var received *WebhookPayload
+	handler := NewWebhookHandler(testSecret, func(ctx context.Context, payload *WebhookPayload) error {
+		received = payload
+		return nil
+	})
+
+	rec := postChannelWebhook(handler, readWebhookBody(t), testWebhookSignature)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d", rec.Code)
+	}
+	if received == nil {
+		t.Fatal("Обработчик не вызван")
+	}
+
+	if received.ScopeID != testChannelID+"_"+testAccountID {
+		t.Errorf("Неверный scope_id: %s", received.ScopeID)
+	}
+	message := received.Message
+	if message.Sender.Name != "Менеджер" || message.Receiver.ClientID != "client-1" || message.Receiver.Profile.Phone != "+79990000000" {
+		t.Errorf("Неверные участники сообщения: %+v, %+v", message.Sender, message.Receiver)
+	}
+	if message.Conversation.ID != "8e3e7640-49af-4448-a2c6-d5a421f7f217" || message.Conversation.ClientID != "conversation-1" {
+		t.Errorf("Неверный чат: %+v", message.Conversation)
+	}
+	if message.Message.Type != MessageTypeFile || message.Message.FileName != "price.pdf" || message.Message.FileSize != 20480 {
+		t.Errorf("Неверное вложение: %+v", message.Message)
+	}
+	if message.Source == nil || message.Source.ExternalID != "78001234567" {
+		t.Errorf("Неверный источник: %+v", message.Source)
+	}
+}
+
+func TestWebhookHandlerErrors(t *testing.T) {
+	handler := NewWebhookHandler(testSecret, func(ctx context.Context, payload *WebhookPayload) error {
+		return errors.New("ошибка доставки в мессенджер")
+	})
+	body := readWebhookBody(t)
+
+	if rec := postChannelWebhook(handler, body, "0000"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Ожидался статус 401 при неверной подписи, получен %d", rec.Code)
+	}
+	if rec := postChannelWebhook(handler, body, testWebhookSignature); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Ожидался статус 500 при ошибке обработчика, получен %d", rec.Code)
+	}
+
+	invalid := []byte(`{"account_id":"x"}`)
+	mac := hmac.New(sha1.New, []byte(testSecret))
+	mac.Write(invalid)
+	if rec := postChannelWebhook(handler, invalid, hex.EncodeToString(mac.Sum(nil))); rec.Code != http.StatusBadRequest {
+		t.Errorf("Ожидался статус 400 для вебхука без сообщения, получен %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/amojo/scope", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Ожидался статус 405 для GET, получен %d", rec.Code)
+	}
+}
+
+func TestReplyAndStatuses(t *testing.T) {
+	payload, err := ParseWebhook(readWebhookBody(t))
+	if err != nil {
+		t.Fatalf("Ошибка при разборе вебхука: %v", err)
+	}
+	payload.ScopeID = "scope"
+
+	var requests []map[string]interface{}
+	var paths []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		requests = append(requests, body)
+		paths = append(paths, r.URL.Path)
+		_, _ = w.Write([]byte(`{"new_message":{"msgid":"amo-msg-3"}}`))
+	})
+
+	if _, err := c.Reply(payload, "reply-1", TextMessage("Спасибо")); err != nil {
+		t.Fatalf("Ошибка при отправке ответа: %v", err)
+	}
+	reply := requests[0]["payload"].(map[string]interface{})
+	sender := reply["sender"].(map[string]interface{})
+	if reply["conversation_id"] != "conversation-1" || sender["id"] != "client-1" || paths[0] != "/v2/origin/custom/scope" {
+		t.Errorf("Неверный ответ: %v в %s", reply, paths[0])
+	}
+
+	// Чат начат менеджером в amoCRM: client_id чата нет
+	managerChat := *payload
+	managerChat.Message.Conversation.ClientID = ""
+	if _, err := c.Reply(&managerChat, "reply-2", TextMessage("Спасибо")); err == nil || !strings.Contains(err.Error(), "client_id") {
+		t.Errorf("Ожидалась ошибка об отсутствии client_id чата, получено %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("Ответ без client_id чата не должен отправляться, выполнено запросов: %d", len(requests))
+	}
+
+	if err := c.MarkDelivered(payload); err != nil {
+		t.Fatalf("Ошибка при обновлении статуса доставки: %v", err)
+	}
+	if err := c.MarkRead(payload); err != nil {
+		t.Fatalf("Ошибка при обновлении статуса прочтения: %v", err)
+	}
+	if err := c.MarkFailed(payload, 905, "Пользователь заблокировал бота"); err != nil {
+		t.Fatalf("Ошибка при обновлении статуса ошибки: %v", err)
+	}
+
+	statusPath := "/v2/origin/custom/scope/0a6ed5d5-8e2f-4f2c-9b83-2d4b6f6d3c9e/delivery_status"
+	for i, expected := range []float64{1, 2, -1} {
+		if paths[i+1] != statusPath || requests[i+1]["delivery_status"] != expected {
+			t.Errorf("Неверный статус доставки: %v в %s", requests[i+1], paths[i+1])
+		}
+	}
+}
@@ -93,8 +93,46 @@ func GetCompany(apiClient *client.Client, companyID int, withOptions ...WithOpti
 	return &company, nil
 }
 
+// Validator проверяет компанию перед отправкой в amoCRM, например по схеме аккаунта.
+type Validator interface {
+	ValidateCompany(company *Company) error
+}
+
+// SaveOption задает дополнительные параметры создания и обновления компании
+type SaveOption func(*saveOptions)
+
+// saveOptions содержит параметры создания и обновления компании
+type saveOptions struct {
+	validator Validator
+}
+
+// WithValidator включает проверку компании перед отправкой запроса.
+// Если проверка не пройдена, запрос не выполняется и возвращается ошибка валидатора.
+func WithValidator(validator Validator) SaveOption {
+	return func(options *saveOptions) {
+		options.validator = validator
+	}
+}
+
+// applySaveOptions применяет параметры сохранения и выполняет проверку компании
+func applySaveOptions(company *Company, opts []SaveOption) error {
+	var options saveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.validator != nil {
+		return options.validator.ValidateCompany(company)
+	}
+	return nil
+}
+
 // CreateCompany создает новую компанию в amoCRM.
-func CreateCompany(apiClient *client.Client, company *Company) (*Company, error) {
+func CreateCompany(apiClient *client.Client, company *Company, opts ...SaveOption) (*Company, error) {
+	if err := applySaveOptions(company, opts); err != nil {
+		return nil, err
+	}
+
 	url := apiClient.GetBaseURL() + "/api/v4/companies"
 	companyJSON, err := json.Marshal(company)
 	if err != nil {
@@ -123,7 +161,11 @@ func CreateCompany(apiClient *client.Client, company *Company) (*Company, error)
 }
 
 // UpdateCompany обновляет существующую компанию в amoCRM.
-func UpdateCompany(apiClient *client.Client, company *Company) (*Company, error) {
+func UpdateCompany(apiClient *client.Client, company *Company, opts ...SaveOption) (*Company, error) {
+	if err := applySaveOptions(company, opts); err != nil {
+		return nil, err
+	}
+
 	url := apiClient.GetBaseURL() + "/api/v4/companies/" + fmt.Sprintf("%d", company.ID)
 	companyJSON, err := json.Marshal(company)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/chudno/amo_crm_sdk/client"
@@ -41,8 +42,11 @@ type Tag struct {
 
 // ContactEmbedded содержит связанные с контактом сущности
 type ContactEmbedded struct {
-	Companies []Company `json:"companies,omitempty"`
-	Tags      []Tag     `json:"tags,omitempty"`
+	Companies       []Company        `json:"companies,omitempty"`
+	Tags            []Tag            `json:"tags,omitempty"`
+	Leads           []Lead           `json:"leads,omitempty"`
+	Customers       []Customer       `json:"customers,omitempty"`
+	CatalogElements []CatalogElement `json:"catalog_elements,omitempty"`
 }
 
 // Company представляет компанию, связанную с контактом
@@ -51,11 +55,30 @@ type Company struct {
 	Name string `json:"name,omitempty"`
 }
 
+// Lead представляет сделку, связанную с контактом
+type Lead struct {
+	ID int `json:"id"`
+}
+
+// Customer представляет покупателя, связанного с контактом
+type Customer struct {
+	ID int `json:"id"`
+}
+
+// CatalogElement представляет элемент каталога, связанный с контактом
+type CatalogElement struct {
+	ID       int         `json:"id"`
+	Metadata interface{} `json:"metadata,omitempty"`
+}
+
 // WithOption определяет связанные сущности, которые нужно получить вместе с контактом
 type WithOption string
 
 const (
-	WithCompanies WithOption = "companies"
+	WithCompanies       WithOption = "companies"
+	WithLeads           WithOption = "leads"
+	WithCustomers       WithOption = "customers"
+	WithCatalogElements WithOption = "catalog_elements"
 )
 
 // GetContact получает контакт по его ID.
@@ -95,8 +118,46 @@ func GetContact(apiClient *client.Client, contactID int, withOptions ...WithOpti
 	return &contact, nil
 }
 
+// Validator проверяет контакт перед отправкой в amoCRM, например по схеме аккаунта.
+type Validator interface {
+	ValidateContact(contact *Contact) error
+}
+
+// SaveOption задает дополнительные параметры создания и обновления контакта
+type SaveOption func(*saveOptions)
+
+// saveOptions содержит параметры создания и обновления контакта
+type saveOptions struct {
+	validator Validator
+}
+
+// WithValidator включает проверку контакта перед отправкой запроса.
+// Если проверка не пройдена, запрос не выполняется и возвращается ошибка валидатора.
+func WithValidator(validator Validator) SaveOption {
+	return func(options *saveOptions) {
+		options.validator = validator
+	}
+}
+
+// applySaveOptions применяет параметры сохранения и выполняет проверку контакта
+func applySaveOptions(contact *Contact, opts []SaveOption) error {
+	var options saveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.validator != nil {
+		return options.validator.ValidateContact(contact)
+	}
+	return nil
+}
+
 // CreateContact создает новый контакт в amoCRM.
-func CreateContact(apiClient *client.Client, contact *Contact) (*Contact, error) {
+func CreateContact(apiClient *client.Client, contact *Contact, opts ...SaveOption) (*Contact, error) {
+	if err := applySaveOptions(contact, opts); err != nil {
+		return nil, err
+	}
+
 	url := apiClient.GetBaseURL() + "/api/v4/contacts"
 	contactJSON, err := json.Marshal(contact)
 	if err != nil {
@@ -124,6 +185,49 @@ func CreateContact(apiClient *client.Client, contact *Contact) (*Contact, error)
 	return &newContact, nil
 }
 
+// UpdateContact обновляет существующий контакт в amoCRM.
+func UpdateContact(apiClient *client.Client, contact *Contact, opts ...SaveOption) (*Contact, error) {
+	if contact.ID == 0 {
+		return nil, fmt.Errorf("ID контакта не указан")
+	}
+
+	if err := applySaveOptions(contact, opts); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/v4/contacts/%d", apiClient.GetBaseURL(), contact.ID)
+
+	contactJSON, err := json.Marshal(contact)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(contactJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var updatedContact Contact
+	if err := json.NewDecoder(resp.Body).Decode(&updatedContact); err != nil {
+		return nil, err
+	}
+
+	return &updatedContact, nil
+}
+
 // DeleteContactsResponse представляет ответ от API при удалении контактов
 type DeleteContactsResponse struct {
 	Status  string            `json:"status"`
@@ -191,25 +295,39 @@ func GetContacts(apiClient *client.Client, page, limit int, withOptions ...WithO
 
 // LinkContactWithCompany связывает контакт с компанией
 func LinkContactWithCompany(apiClient *client.Client, contactID, companyID int) error {
+	return LinkContactWithEntity(apiClient, contactID, "companies", companyID)
+}
+
+// LinkContactWithEntity связывает контакт с сущностью (сделкой, компанией, покупателем).
+// Параметр entityType задается во множественном числе: leads, companies, customers.
+func LinkContactWithEntity(apiClient *client.Client, contactID int, entityType string, entityID int) error {
+	return changeContactLinks(apiClient, "link", contactID, entityType, entityID)
+}
+
+// UnlinkContactFromEntity отвязывает контакт от сущности.
+func UnlinkContactFromEntity(apiClient *client.Client, contactID int, entityType string, entityID int) error {
+	return changeContactLinks(apiClient, "unlink", contactID, entityType, entityID)
+}
+
+// changeContactLinks выполняет запрос на связывание или отвязывание контакта.
+func changeContactLinks(apiClient *client.Client, action string, contactID int, entityType string, entityID int) error {
 	// Формируем URL для запроса
-	url := fmt.Sprintf("%s/api/v4/contacts/%d/link", apiClient.GetBaseURL(), contactID)
+	url := fmt.Sprintf("%s/api/v4/contacts/%d/%s", apiClient.GetBaseURL(), contactID, action)
 
 	// Формируем тело запроса
+	type linkItem struct {
+		EntityID   int    `json:"entity_id"`
+		EntityType string `json:"entity_type"`
+	}
 	type linkRequest struct {
-		To []struct {
-			EntityID   int    `json:"entity_id"`
-			EntityType string `json:"entity_type"`
-		} `json:"to"`
+		To []linkItem `json:"to"`
 	}
 
 	reqBody := linkRequest{
-		To: []struct {
-			EntityID   int    `json:"entity_id"`
-			EntityType string `json:"entity_type"`
-		}{
+		To: []linkItem{
 			{
-				EntityID:   companyID,
-				EntityType: "companies",
+				EntityID:   entityID,
+				EntityType: entityType,
 			},
 		},
 	}
@@ -236,9 +354,207 @@ func LinkContactWithCompany(apiClient *client.Client, contactID, companyID int)
 	defer resp.Body.Close()
 
 	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	return nil
 }
+
+// ContactsFilter задает параметры поиска контактов.
+type ContactsFilter struct {
+	// Query - строка полнотекстового поиска (имя, телефон, email и т.д.)
+	Query string
+	// IDs - фильтр по ID контактов
+	IDs []int
+	// Names - фильтр по именам контактов
+	Names []string
+	// ResponsibleUserIDs - фильтр по ID ответственных пользователей
+	ResponsibleUserIDs []int
+	// UpdatedAtFrom - нижняя граница даты изменения (Unix timestamp)
+	UpdatedAtFrom int64
+	// UpdatedAtTo - верхняя граница даты изменения (Unix timestamp)
+	UpdatedAtTo int64
+}
+
+// Values преобразует фильтр в параметры запроса API amoCRM.
+func (f *ContactsFilter) Values() url.Values {
+	params := url.Values{}
+	if f == nil {
+		return params
+	}
+
+	if f.Query != "" {
+		params.Set("query", f.Query)
+	}
+	for _, id := range f.IDs {
+		params.Add("filter[id][]", strconv.Itoa(id))
+	}
+	for _, name := range f.Names {
+		params.Add("filter[name][]", name)
+	}
+	for _, userID := range f.ResponsibleUserIDs {
+		params.Add("filter[responsible_user_id][]", strconv.Itoa(userID))
+	}
+	if f.UpdatedAtFrom > 0 {
+		params.Set("filter[updated_at][from]", strconv.FormatInt(f.UpdatedAtFrom, 10))
+	}
+	if f.UpdatedAtTo > 0 {
+		params.Set("filter[updated_at][to]", strconv.FormatInt(f.UpdatedAtTo, 10))
+	}
+
+	return params
+}
+
+// FindContacts получает список контактов, подходящих под фильтр.
+// Если контакты не найдены, возвращается пустой список без ошибки.
+func FindContacts(apiClient *client.Client, page, limit int, filter *ContactsFilter, withOptions ...WithOption) ([]Contact, error) {
+	// Формируем базовый URL
+	baseURL := fmt.Sprintf("%s/api/v4/contacts", apiClient.GetBaseURL())
+
+	// Добавляем параметры запроса
+	params := filter.Values()
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	// Добавляем параметр with, если указаны withOptions
+	if len(withOptions) > 0 {
+		var withValues []string
+		for _, opt := range withOptions {
+			withValues = append(withValues, string(opt))
+		}
+		params.Set("with", strings.Join(withValues, ","))
+	}
+
+	// Создаем запрос
+	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// amoCRM возвращает 204, если по запросу ничего не найдено
+	if resp.StatusCode == http.StatusNoContent {
+		return []Contact{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var contacts ContactsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&contacts); err != nil {
+		return nil, err
+	}
+
+	return contacts.Embedded.Contacts, nil
+}
+
+// FindContactsByPhone ищет контакты по номеру телефона.
+// Поиск выполняется по всем вариантам записи номера (+7..., 8..., только цифры),
+// найденные контакты объединяются без повторов.
+func FindContactsByPhone(apiClient *client.Client, phone string, withOptions ...WithOption) ([]Contact, error) {
+	variants := PhoneVariants(phone)
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("некорректный номер телефона: %q", phone)
+	}
+
+	return findContactsByQueries(apiClient, variants, withOptions...)
+}
+
+// FindContactsByEmail ищет контакты по адресу электронной почты.
+func FindContactsByEmail(apiClient *client.Client, email string, withOptions ...WithOption) ([]Contact, error) {
+	normalized := NormalizeEmail(email)
+	if normalized == "" {
+		return nil, fmt.Errorf("некорректный email: %q", email)
+	}
+
+	return findContactsByQueries(apiClient, []string{normalized}, withOptions...)
+}
+
+// searchPageLimit - количество контактов, запрашиваемых за одну страницу поиска
+const searchPageLimit = 250
+
+// findContactsByQueries выполняет поиск по каждой строке и объединяет результаты.
+// Для каждой строки запрашиваются все страницы результатов.
+func findContactsByQueries(apiClient *client.Client, queries []string, withOptions ...WithOption) ([]Contact, error) {
+	seen := make(map[int]bool)
+	result := []Contact{}
+
+	for _, query := range queries {
+		for page := 1; ; page++ {
+			found, err := FindContacts(apiClient, page, searchPageLimit, &ContactsFilter{Query: query}, withOptions...)
+			if err != nil {
+				return nil, err
+			}
+			for _, contact := range found {
+				if seen[contact.ID] {
+					continue
+				}
+				seen[contact.ID] = true
+				result = append(result, contact)
+			}
+			if len(found) < searchPageLimit {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// NormalizePhone приводит номер телефона к виду из одних цифр.
+// Российские номера приводятся к формату 7XXXXXXXXXX: ведущая 8 заменяется на 7,
+// к десятизначному российскому номеру добавляется 7. Российским считается десятизначный номер,
+// который начинается с 9 (мобильный) или с 3, 4, 8 (код города или 800). Остальные номера,
+// например американские, возвращаются без изменений.
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+
+	normalized := digits.String()
+	switch {
+	case len(normalized) == 11 && normalized[0] == '8':
+		normalized = "7" + normalized[1:]
+	case len(normalized) == 10 && isRussianNationalNumber(normalized):
+		normalized = "7" + normalized
+	}
+
+	return normalized
+}
+
+// isRussianNationalNumber проверяет, что десятизначный номер записан в российском формате без кода страны
+func isRussianNationalNumber(number string) bool {
+	return strings.ContainsRune("3489", rune(number[0]))
+}
+
+// PhoneVariants возвращает варианты записи номера, под которыми он может храниться в amoCRM.
+// Для российского номера это +7XXXXXXXXXX, 8XXXXXXXXXX, 7XXXXXXXXXX и XXXXXXXXXX.
+func PhoneVariants(phone string) []string {
+	normalized := NormalizePhone(phone)
+	if normalized == "" {
+		return nil
+	}
+
+	if len(normalized) == 11 && normalized[0] == '7' {
+		local := normalized[1:]
+		return []string{"+" + normalized, "8" + local, normalized, local}
+	}
+
+	return []string{"+" + normalized, normalized}
+}
+
+// NormalizeEmail приводит адрес электронной почты к нижнему регистру без пробелов по краям.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/chudno/amo_crm_sdk/client"
@@ -201,3 +202,186 @@ func TestListContacts(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateContact(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем метод запроса
+		if r.Method != "PATCH" {
+			t.Errorf("Ожидался метод PATCH, получен %s", r.Method)
+		}
+
+		// Проверяем путь запроса
+		expectedPath := "/api/v4/contacts/123"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Ожидался путь %s, получен %s", expectedPath, r.URL.Path)
+		}
+
+		// Отправляем ответ
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"id": 123, "name": "Обновленный контакт", "updated_at": 1609545600}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	updatedContact, err := UpdateContact(apiClient, &Contact{ID: 123, Name: "Обновленный контакт"})
+	if err != nil {
+		t.Fatalf("Ошибка при обновлении контакта: %v", err)
+	}
+
+	if updatedContact.Name != "Обновленный контакт" {
+		t.Errorf("Ожидалось имя контакта 'Обновленный контакт', получено '%s'", updatedContact.Name)
+	}
+
+	// Контакт без ID не должен отправляться
+	if _, err := UpdateContact(apiClient, &Contact{Name: "Без ID"}); err == nil {
+		t.Error("Ожидалась ошибка для контакта без ID, но ее не было")
+	}
+}
+
+func TestFindContacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		expected := map[string]string{
+			"query":                         "Иван",
+			"filter[updated_at][from]":      "1609459200",
+			"filter[updated_at][to]":        "1609545600",
+			"filter[responsible_user_id][]": "456",
+			"with":                          "leads,customers,catalog_elements",
+			"page":                          "1",
+			"limit":                         "50",
+		}
+		for key, value := range expected {
+			if query.Get(key) != value {
+				t.Errorf("Ожидался параметр %s=%s, получен %s", key, value, query.Get(key))
+			}
+		}
+
+		if ids := query["filter[id][]"]; len(ids) != 2 || ids[0] != "1" || ids[1] != "2" {
+			t.Errorf("Ожидались параметры filter[id][]=1,2, получены %v", ids)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"_embedded":{"contacts":[{"id":1,"name":"Иван","_embedded":{"leads":[{"id":10}],"customers":[{"id":20}]}}]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	filter := &ContactsFilter{
+		Query:              "Иван",
+		IDs:                []int{1, 2},
+		ResponsibleUserIDs: []int{456},
+		UpdatedAtFrom:      1609459200,
+		UpdatedAtTo:        1609545600,
+	}
+	contacts, err := FindContacts(apiClient, 1, 50, filter, WithLeads, WithCustomers, WithCatalogElements)
+	if err != nil {
+		t.Fatalf("Ошибка при поиске контактов: %v", err)
+	}
+
+	if len(contacts) != 1 || contacts[0].Embedded == nil {
+		t.Fatalf("Ожидался 1 контакт со связанными сущностями, получено %v", contacts)
+	}
+
+	if len(contacts[0].Embedded.Leads) != 1 || contacts[0].Embedded.Leads[0].ID != 10 {
+		t.Errorf("Ожидалась связанная сделка с ID 10, получено %v", contacts[0].Embedded.Leads)
+	}
+
+	if len(contacts[0].Embedded.Customers) != 1 || contacts[0].Embedded.Customers[0].ID != 20 {
+		t.Errorf("Ожидался связанный покупатель с ID 20, получено %v", contacts[0].Embedded.Customers)
+	}
+}
+
+func TestFindContactsByPhone(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query().Get("query")
+		queries = append(queries, query)
+
+		// Один и тот же контакт находится по двум вариантам номера
+		switch query {
+		case "+79001234567", "89001234567":
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"_embedded":{"contacts":[{"id":123,"name":"Иван"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	contacts, err := FindContactsByPhone(apiClient, "8 (900) 123-45-67")
+	if err != nil {
+		t.Fatalf("Ошибка при поиске контактов по телефону: %v", err)
+	}
+
+	if len(queries) != 4 {
+		t.Errorf("Ожидалось 4 поисковых запроса, выполнено %d: %v", len(queries), queries)
+	}
+
+	if len(contacts) != 1 || contacts[0].ID != 123 {
+		t.Errorf("Ожидался 1 контакт с ID 123 без повторов, получено %v", contacts)
+	}
+
+	if _, err := FindContactsByPhone(apiClient, "нет номера"); err == nil {
+		t.Error("Ожидалась ошибка для пустого номера, но ее не было")
+	}
+}
+
+func TestFindContactsByEmailPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		// Первая страница заполнена полностью, вторая содержит один контакт
+		count := searchPageLimit
+		if page == "2" {
+			count = 1
+		}
+		items := make([]string, count)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"id":%s%03d}`, page, i)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"contacts":[` + strings.Join(items, ",") + `]}}`))
+	}))
+	defer server.Close()
+
+	contacts, err := FindContactsByEmail(client.NewClient(server.URL, "test_api_key"), "ivan@example.com")
+	if err != nil {
+		t.Fatalf("Ошибка при поиске контактов по email: %v", err)
+	}
+	if len(contacts) != searchPageLimit+1 || fmt.Sprint(pages) != "[1 2]" {
+		t.Errorf("Ожидалось %d контактов на страницах 1 и 2, получено %d на %v", searchPageLimit+1, len(contacts), pages)
+	}
+}
+
+func TestPhoneVariants(t *testing.T) {
+	tests := []struct {
+		phone    string
+		expected []string
+	}{
+		{"+7 (900) 123-45-67", []string{"+79001234567", "89001234567", "79001234567", "9001234567"}},
+		{"8-900-123-45-67", []string{"+79001234567", "89001234567", "79001234567", "9001234567"}},
+		{"9001234567", []string{"+79001234567", "89001234567", "79001234567", "9001234567"}},
+		{"(495) 123-45-67", []string{"+74951234567", "84951234567", "74951234567", "4951234567"}},
+		{"+380 44 123 4567", []string{"+380441234567", "380441234567"}},
+		{"(212) 555-01-23", []string{"+2125550123", "2125550123"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		variants := PhoneVariants(tt.phone)
+		if fmt.Sprint(variants) != fmt.Sprint(tt.expected) {
+			t.Errorf("PhoneVariants(%q) = %v, ожидалось %v", tt.phone, variants, tt.expected)
+		}
+	}
+
+	if email := NormalizeEmail("  Ivan@Example.COM "); email != "ivan@example.com" {
+		t.Errorf("Ожидался email ivan@example.com, получен %s", email)
+	}
+}
@@ -0,0 +1,593 @@
+// Пакет customers предоставляет методы для взаимодействия с сущностями "Покупатели" в API amoCRM.
+package customers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/internal/filter"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+)
+
+// Customer представляет собой структуру покупателя в amoCRM.
+type Customer struct {
+	ID                 int                              `json:"id,omitempty"`
+	Name               string                           `json:"name,omitempty"`
+	NextPrice          int                              `json:"next_price,omitempty"`
+	NextDate           int64                            `json:"next_date,omitempty"`
+	ResponsibleUserID  int                              `json:"responsible_user_id,omitempty"`
+	StatusID           int                              `json:"status_id,omitempty"`
+	Periodicity        int                              `json:"periodicity,omitempty"`
+	CreatedBy          int                              `json:"created_by,omitempty"`
+	UpdatedBy          int                              `json:"updated_by,omitempty"`
+	CreatedAt          int64                            `json:"created_at,omitempty"`
+	UpdatedAt          int64                            `json:"updated_at,omitempty"`
+	ClosestTaskAt      int64                            `json:"closest_task_at,omitempty"`
+	IsDeleted          bool                             `json:"is_deleted,omitempty"`
+	CustomFieldsValues []custom_fields.CustomFieldValue `json:"custom_fields_values,omitempty"`
+	LTV                int                              `json:"ltv,omitempty"`
+	PurchasesCount     int                              `json:"purchases_count,omitempty"`
+	AverageCheck       int                              `json:"average_check,omitempty"`
+	AccountID          int                              `json:"account_id,omitempty"`
+	Embedded           *CustomerEmbedded                `json:"_embedded,omitempty"`
+}
+
+// Tag представляет тег покупателя
+type Tag struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Segment представляет сегмент, в который входит покупатель
+type Segment struct {
+	ID int `json:"id"`
+}
+
+// Contact представляет контакт, связанный с покупателем
+type Contact struct {
+	ID     int  `json:"id"`
+	IsMain bool `json:"is_main,omitempty"`
+}
+
+// Company представляет компанию, связанную с покупателем
+type Company struct {
+	ID int `json:"id"`
+}
+
+// CatalogElement представляет элемент каталога, связанный с покупателем
+type CatalogElement struct {
+	ID        int         `json:"id"`
+	CatalogID int         `json:"catalog_id,omitempty"`
+	Metadata  interface{} `json:"metadata,omitempty"`
+}
+
+// CustomerEmbedded содержит связанные с покупателем сущности
+type CustomerEmbedded struct {
+	Tags            []Tag            `json:"tags,omitempty"`
+	Segments        []Segment        `json:"segments,omitempty"`
+	Contacts        []Contact        `json:"contacts,omitempty"`
+	Companies       []Company        `json:"companies,omitempty"`
+	CatalogElements []CatalogElement `json:"catalog_elements,omitempty"`
+}
+
+// WithOption определяет связанные сущности, которые нужно получить вместе с покупателем
+type WithOption string
+
+const (
+	WithCatalogElements WithOption = "catalog_elements"
+	WithContacts        WithOption = "contacts"
+	WithCompanies       WithOption = "companies"
+	WithSegments        WithOption = "segments"
+)
+
+// CustomersResponse представляет ответ от API при получении списка покупателей
+type CustomersResponse struct {
+	Page     int `json:"page"`
+	PerPage  int `json:"per_page"`
+	Embedded struct {
+		Customers []Customer `json:"customers"`
+	} `json:"_embedded"`
+}
+
+// CustomersFilter задает параметры фильтрации списка покупателей.
+type CustomersFilter struct {
+	// Query - строка полнотекстового поиска
+	Query string
+	// IDs - фильтр по ID покупателей
+	IDs []int
+	// Names - фильтр по названиям покупателей
+	Names []string
+	// ResponsibleUserIDs - фильтр по ID ответственных пользователей
+	ResponsibleUserIDs []int
+	// NextDateFrom, NextDateTo - диапазон ожидаемой даты покупки (Unix timestamp)
+	NextDateFrom int64
+	NextDateTo   int64
+	// CreatedAtFrom, CreatedAtTo - диапазон даты создания (Unix timestamp)
+	CreatedAtFrom int64
+	CreatedAtTo   int64
+	// UpdatedAtFrom, UpdatedAtTo - диапазон даты изменения (Unix timestamp)
+	UpdatedAtFrom int64
+	UpdatedAtTo   int64
+}
+
+// Values преобразует фильтр в параметры запроса API amoCRM.
+func (f *CustomersFilter) Values() url.Values {
+	params := url.Values{}
+	if f == nil {
+		return params
+	}
+
+	if f.Query != "" {
+		params.Set("query", f.Query)
+	}
+	for _, id := range f.IDs {
+		params.Add("filter[id][]", strconv.Itoa(id))
+	}
+	for _, name := range f.Names {
+		params.Add("filter[name][]", name)
+	}
+	for _, userID := range f.ResponsibleUserIDs {
+		params.Add("filter[responsible_user_id][]", strconv.Itoa(userID))
+	}
+	filter.AddRange(params, "next_date", f.NextDateFrom, f.NextDateTo)
+	filter.AddRange(params, "created_at", f.CreatedAtFrom, f.CreatedAtTo)
+	filter.AddRange(params, "updated_at", f.UpdatedAtFrom, f.UpdatedAtTo)
+
+	return params
+}
+
+// withParam формирует значение параметра with.
+func withParam(withOptions []WithOption) string {
+	var withValues []string
+	for _, opt := range withOptions {
+		withValues = append(withValues, string(opt))
+	}
+	return strings.Join(withValues, ",")
+}
+
+// GetCustomer получает покупателя по его ID.
+// Параметр withOptions позволяет указать, какие связанные сущности нужно получить вместе с покупателем.
+func GetCustomer(apiClient *client.Client, customerID int, withOptions ...WithOption) (*Customer, error) {
+	// Формируем базовый URL
+	baseURL := fmt.Sprintf("%s/api/v4/customers/%d", apiClient.GetBaseURL(), customerID)
+
+	// Добавляем параметры запроса, если указаны withOptions
+	if len(withOptions) > 0 {
+		params := url.Values{}
+		params.Add("with", withParam(withOptions))
+		baseURL = baseURL + "?" + params.Encode()
+	}
+
+	// Создаем запрос
+	req, err := http.NewRequest("GET", baseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var customer Customer
+	if err := json.NewDecoder(resp.Body).Decode(&customer); err != nil {
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// GetCustomers получает список покупателей с возможностью фильтрации и пагинации.
+// Параметр withOptions позволяет указать, какие связанные сущности нужно получить вместе с покупателями.
+func GetCustomers(apiClient *client.Client, page, limit int, filter *CustomersFilter, withOptions ...WithOption) ([]Customer, error) {
+	// Формируем базовый URL
+	baseURL := fmt.Sprintf("%s/api/v4/customers", apiClient.GetBaseURL())
+
+	// Добавляем параметры запроса
+	params := filter.Values()
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	// Добавляем параметр with, если указаны withOptions
+	if len(withOptions) > 0 {
+		params.Set("with", withParam(withOptions))
+	}
+
+	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// amoCRM возвращает 204, если по запросу ничего не найдено
+	if resp.StatusCode == http.StatusNoContent {
+		return []Customer{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response CustomersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Customers, nil
+}
+
+// CreateCustomer создает нового покупателя в amoCRM.
+func CreateCustomer(apiClient *client.Client, customer *Customer) (*Customer, error) {
+	created, err := CreateCustomers(apiClient, []Customer{*customer})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(created) == 0 {
+		return nil, fmt.Errorf("не удалось создать покупателя")
+	}
+
+	return &created[0], nil
+}
+
+// CreateCustomers создает несколько покупателей одним запросом.
+func CreateCustomers(apiClient *client.Client, customers []Customer) ([]Customer, error) {
+	return saveCustomers(apiClient, "POST", customers)
+}
+
+// UpdateCustomer обновляет существующего покупателя в amoCRM.
+func UpdateCustomer(apiClient *client.Client, customer *Customer) (*Customer, error) {
+	if customer.ID == 0 {
+		return nil, fmt.Errorf("ID покупателя не указан")
+	}
+
+	url := fmt.Sprintf("%s/api/v4/customers/%d", apiClient.GetBaseURL(), customer.ID)
+
+	customerData, err := json.Marshal(customer)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(customerData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var updatedCustomer Customer
+	if err := json.NewDecoder(resp.Body).Decode(&updatedCustomer); err != nil {
+		return nil, err
+	}
+
+	return &updatedCustomer, nil
+}
+
+// UpdateCustomers обновляет несколько покупателей одним запросом.
+// У каждого покупателя должен быть указан ID.
+func UpdateCustomers(apiClient *client.Client, customers []Customer) ([]Customer, error) {
+	for _, customer := range customers {
+		if customer.ID == 0 {
+			return nil, fmt.Errorf("ID покупателя не указан")
+		}
+	}
+
+	return saveCustomers(apiClient, "PATCH", customers)
+}
+
+// saveCustomers отправляет пакетный запрос на создание или обновление покупателей.
+func saveCustomers(apiClient *client.Client, method string, customers []Customer) ([]Customer, error) {
+	url := fmt.Sprintf("%s/api/v4/customers", apiClient.GetBaseURL())
+
+	customersData, err := json.Marshal(customers)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(customersData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response CustomersResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Customers, nil
+}
+
+// DeleteCustomer удаляет покупателя по его ID.
+func DeleteCustomer(apiClient *client.Client, customerID int) error {
+	url := fmt.Sprintf("%s/api/v4/customers/%d", apiClient.GetBaseURL(), customerID)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Mode определяет режим работы покупателей
+type Mode string
+
+const (
+	// ModePeriodicity режим "Периодические покупки"
+	ModePeriodicity Mode = "periodicity"
+	// ModeSegments режим "Сегментация"
+	ModeSegments Mode = "segments"
+)
+
+// ModeSettings представляет настройки режима покупателей
+type ModeSettings struct {
+	Mode      Mode `json:"mode"`
+	IsEnabled bool `json:"is_enabled"`
+}
+
+// SetCustomersMode включает или выключает функционал покупателей и задает режим его работы.
+func SetCustomersMode(apiClient *client.Client, mode Mode, isEnabled bool) (*ModeSettings, error) {
+	url := fmt.Sprintf("%s/api/v4/customers/mode", apiClient.GetBaseURL())
+
+	settingsData, err := json.Marshal(ModeSettings{Mode: mode, IsEnabled: isEnabled})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(settingsData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var settings ModeSettings
+	if err := json.NewDecoder(resp.Body).Decode(&settings); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// Status представляет собой статус (этап) покупателей в amoCRM.
+type Status struct {
+	ID         int         `json:"id,omitempty"`
+	Name       string      `json:"name"`
+	Sort       int         `json:"sort,omitempty"`
+	IsDefault  bool        `json:"is_default,omitempty"`
+	Color      string      `json:"color,omitempty"`
+	Type       int         `json:"type,omitempty"`
+	Conditions interface{} `json:"conditions,omitempty"`
+	AccountID  int         `json:"account_id,omitempty"`
+}
+
+// StatusesResponse представляет ответ от API при получении списка статусов покупателей
+type StatusesResponse struct {
+	Page     int `json:"page"`
+	PerPage  int `json:"per_page"`
+	Total    int `json:"total"`
+	Embedded struct {
+		Statuses []Status `json:"statuses"`
+	} `json:"_embedded"`
+}
+
+// GetStatuses получает список статусов покупателей.
+func GetStatuses(apiClient *client.Client, page, limit int) ([]Status, error) {
+	params := url.Values{}
+	params.Add("page", strconv.Itoa(page))
+	params.Add("limit", strconv.Itoa(limit))
+
+	url := fmt.Sprintf("%s/api/v4/customers/statuses?%s", apiClient.GetBaseURL(), params.Encode())
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response StatusesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Statuses, nil
+}
+
+// GetStatus получает статус покупателей по его ID.
+func GetStatus(apiClient *client.Client, statusID int) (*Status, error) {
+	url := fmt.Sprintf("%s/api/v4/customers/statuses/%d", apiClient.GetBaseURL(), statusID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var status Status
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// CreateStatuses создает статусы покупателей одним запросом.
+func CreateStatuses(apiClient *client.Client, statuses []Status) ([]Status, error) {
+	url := fmt.Sprintf("%s/api/v4/customers/statuses", apiClient.GetBaseURL())
+
+	statusesData, err := json.Marshal(statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(statusesData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response StatusesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Statuses, nil
+}
+
+// UpdateStatus обновляет статус покупателей.
+func UpdateStatus(apiClient *client.Client, status *Status) (*Status, error) {
+	if status.ID == 0 {
+		return nil, fmt.Errorf("ID статуса не указан")
+	}
+
+	url := fmt.Sprintf("%s/api/v4/customers/statuses/%d", apiClient.GetBaseURL(), status.ID)
+
+	statusData, err := json.Marshal(status)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(statusData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var updatedStatus Status
+	if err := json.NewDecoder(resp.Body).Decode(&updatedStatus); err != nil {
+		return nil, err
+	}
+
+	return &updatedStatus, nil
+}
+
+// DeleteStatus удаляет статус покупателей по его ID.
+func DeleteStatus(apiClient *client.Client, statusID int) error {
+	url := fmt.Sprintf("%s/api/v4/customers/statuses/%d", apiClient.GetBaseURL(), statusID)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
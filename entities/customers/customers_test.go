@@ -0,0 +1,359 @@
+package customers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestGetCustomer(t *testing.T) {
+	// Создаем тестовый сервер
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем метод запроса
+		if r.Method != "GET" {
+			t.Errorf("Ожидался метод GET, получен %s", r.Method)
+		}
+
+		// Проверяем путь запроса
+		expectedPath := "/api/v4/customers/123"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Ожидался путь %s, получен %s", expectedPath, r.URL.Path)
+		}
+
+		// Проверяем параметр with
+		if with := r.URL.Query().Get("with"); with != "contacts,segments" {
+			t.Errorf("Ожидался параметр with=contacts,segments, получен with=%s", with)
+		}
+
+		// Отправляем ответ
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{
+			"id": 123,
+			"name": "Постоянный покупатель",
+			"next_price": 5000,
+			"next_date": 1609459200,
+			"status_id": 10,
+			"ltv": 25000,
+			"purchases_count": 5,
+			"average_check": 5000,
+			"_embedded": {
+				"contacts": [{"id": 456, "is_main": true}],
+				"segments": [{"id": 7}]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	// Создаем клиент API
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	// Вызываем тестируемый метод
+	customer, err := GetCustomer(apiClient, 123, WithContacts, WithSegments)
+
+	// Проверяем результаты
+	if err != nil {
+		t.Fatalf("Ошибка при получении покупателя: %v", err)
+	}
+
+	if customer.ID != 123 || customer.Name != "Постоянный покупатель" {
+		t.Errorf("Получен неверный покупатель: %+v", customer)
+	}
+
+	if customer.NextPrice != 5000 || customer.LTV != 25000 || customer.PurchasesCount != 5 {
+		t.Errorf("Неверные показатели покупателя: %+v", customer)
+	}
+
+	if customer.Embedded == nil || len(customer.Embedded.Contacts) != 1 || !customer.Embedded.Contacts[0].IsMain {
+		t.Errorf("Ожидался основной связанный контакт, получено %+v", customer.Embedded)
+	}
+
+	if len(customer.Embedded.Segments) != 1 || customer.Embedded.Segments[0].ID != 7 {
+		t.Errorf("Ожидался сегмент с ID 7, получено %+v", customer.Embedded.Segments)
+	}
+}
+
+func TestGetCustomers(t *testing.T) {
+	tests := []struct {
+		name         string
+		responseCode int
+		responseBody string
+		expectError  bool
+		expectedLen  int
+	}{
+		{
+			name:         "Успешное получение списка покупателей",
+			responseCode: http.StatusOK,
+			responseBody: `{"_embedded":{"customers":[{"id":1,"name":"Первый"},{"id":2,"name":"Второй"}]}}`,
+			expectedLen:  2,
+		},
+		{
+			name:         "Покупатели не найдены",
+			responseCode: http.StatusNoContent,
+			expectedLen:  0,
+		},
+		{
+			name:         "Ошибка сервера",
+			responseCode: http.StatusInternalServerError,
+			responseBody: `{"error":"server_error"}`,
+			expectError:  true,
+		},
+	}
+
+	filter := &CustomersFilter{
+		IDs:                []int{1, 2},
+		ResponsibleUserIDs: []int{456},
+		NextDateFrom:       1609459200,
+		NextDateTo:         1612137600,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Проверяем путь запроса
+				if r.URL.Path != "/api/v4/customers" {
+					t.Errorf("Ожидался путь /api/v4/customers, получен %s", r.URL.Path)
+				}
+
+				// Проверяем параметры запроса
+				query := r.URL.Query()
+				expected := map[string]string{
+					"page":                          "1",
+					"limit":                         "50",
+					"filter[responsible_user_id][]": "456",
+					"filter[next_date][from]":       "1609459200",
+					"filter[next_date][to]":         "1612137600",
+				}
+				for key, value := range expected {
+					if query.Get(key) != value {
+						t.Errorf("Ожидался параметр %s=%s, получен %s", key, value, query.Get(key))
+					}
+				}
+				if ids := query["filter[id][]"]; len(ids) != 2 {
+					t.Errorf("Ожидалось 2 параметра filter[id][], получено %v", ids)
+				}
+
+				w.WriteHeader(tt.responseCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			apiClient := client.NewClient(server.URL, "test_api_key")
+
+			customers, err := GetCustomers(apiClient, 1, 50, filter)
+
+			if tt.expectError && err == nil {
+				t.Error("Ожидалась ошибка, но ее не было")
+			}
+
+			if !tt.expectError {
+				if err != nil {
+					t.Errorf("Неожиданная ошибка: %v", err)
+				}
+
+				if len(customers) != tt.expectedLen {
+					t.Errorf("Ожидалось %d покупателей, получено %d", tt.expectedLen, len(customers))
+				}
+			}
+		})
+	}
+}
+
+func TestCreateCustomers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем метод и путь запроса
+		if r.Method != "POST" || r.URL.Path != "/api/v4/customers" {
+			t.Errorf("Ожидался запрос POST /api/v4/customers, получен %s %s", r.Method, r.URL.Path)
+		}
+
+		// Проверяем, что покупатели отправлены массивом
+		body, _ := ioutil.ReadAll(r.Body)
+		var sent []Customer
+		if err := json.Unmarshal(body, &sent); err != nil {
+			t.Fatalf("Ожидался массив покупателей: %v", err)
+		}
+
+		var response CustomersResponse
+		for i, customer := range sent {
+			customer.ID = 100 + i
+			response.Embedded.Customers = append(response.Embedded.Customers, customer)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	created, err := CreateCustomers(apiClient, []Customer{{Name: "Первый"}, {Name: "Второй"}})
+	if err != nil {
+		t.Fatalf("Ошибка при создании покупателей: %v", err)
+	}
+
+	if len(created) != 2 || created[0].ID != 100 || created[1].ID != 101 {
+		t.Errorf("Ожидались покупатели с ID 100 и 101, получено %+v", created)
+	}
+
+	customer, err := CreateCustomer(apiClient, &Customer{Name: "Одиночный", NextPrice: 1000})
+	if err != nil {
+		t.Fatalf("Ошибка при создании покупателя: %v", err)
+	}
+
+	if customer.ID != 100 || customer.NextPrice != 1000 {
+		t.Errorf("Получен неверный покупатель: %+v", customer)
+	}
+}
+
+func TestUpdateCustomers(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Path == "/api/v4/customers" {
+			// Незаполненное название не должно передаваться
+			body, _ := ioutil.ReadAll(r.Body)
+			if strings.Contains(string(body), `"name"`) {
+				t.Errorf("Незаполненное название не должно передаваться: %s", body)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"customers":[{"id":1},{"id":2}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":1,"name":"Обновленный"}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	updated, err := UpdateCustomer(apiClient, &Customer{ID: 1, Name: "Обновленный"})
+	if err != nil {
+		t.Fatalf("Ошибка при обновлении покупателя: %v", err)
+	}
+	if updated.Name != "Обновленный" {
+		t.Errorf("Ожидалось имя 'Обновленный', получено '%s'", updated.Name)
+	}
+
+	updatedList, err := UpdateCustomers(apiClient, []Customer{{ID: 1}, {ID: 2}})
+	if err != nil {
+		t.Fatalf("Ошибка при пакетном обновлении покупателей: %v", err)
+	}
+	if len(updatedList) != 2 {
+		t.Errorf("Ожидалось 2 покупателя, получено %d", len(updatedList))
+	}
+
+	if len(methods) != 2 || methods[0] != "PATCH /api/v4/customers/1" || methods[1] != "PATCH /api/v4/customers" {
+		t.Errorf("Выполнены неожиданные запросы: %v", methods)
+	}
+
+	// Покупатели без ID не должны отправляться
+	if _, err := UpdateCustomer(apiClient, &Customer{Name: "Без ID"}); err == nil {
+		t.Error("Ожидалась ошибка для покупателя без ID, но ее не было")
+	}
+	if _, err := UpdateCustomers(apiClient, []Customer{{ID: 1}, {Name: "Без ID"}}); err == nil {
+		t.Error("Ожидалась ошибка для покупателя без ID, но ее не было")
+	}
+}
+
+func TestDeleteCustomer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path != "/api/v4/customers/123" {
+			t.Errorf("Ожидался запрос DELETE /api/v4/customers/123, получен %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	if err := DeleteCustomer(apiClient, 123); err != nil {
+		t.Errorf("Неожиданная ошибка: %v", err)
+	}
+}
+
+func TestSetCustomersMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/v4/customers/mode" {
+			t.Errorf("Ожидался запрос PATCH /api/v4/customers/mode, получен %s %s", r.Method, r.URL.Path)
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"mode":"periodicity","is_enabled":true}` {
+			t.Errorf("Неожиданное тело запроса: %s", body)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	settings, err := SetCustomersMode(apiClient, ModePeriodicity, true)
+	if err != nil {
+		t.Fatalf("Ошибка при переключении режима покупателей: %v", err)
+	}
+
+	if settings.Mode != ModePeriodicity || !settings.IsEnabled {
+		t.Errorf("Получены неверные настройки: %+v", settings)
+	}
+}
+
+func TestStatuses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v4/customers/statuses":
+			if r.URL.Query().Get("limit") != "50" {
+				t.Errorf("Ожидался параметр limit=50, получен %s", r.URL.Query().Get("limit"))
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"statuses":[{"id":1,"name":"Ожидается покупка","is_default":true},{"id":2,"name":"Просрочено"}]}}`))
+		case "GET /api/v4/customers/statuses/2":
+			_, _ = w.Write([]byte(`{"id":2,"name":"Просрочено","color":"#ff8f92"}`))
+		case "POST /api/v4/customers/statuses":
+			_, _ = w.Write([]byte(`{"_embedded":{"statuses":[{"id":3,"name":"Новый"}]}}`))
+		case "PATCH /api/v4/customers/statuses/3":
+			_, _ = w.Write([]byte(`{"id":3,"name":"Переименованный"}`))
+		case "DELETE /api/v4/customers/statuses/3":
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	statuses, err := GetStatuses(apiClient, 1, 50)
+	if err != nil || len(statuses) != 2 || !statuses[0].IsDefault {
+		t.Errorf("Ожидалось 2 статуса, первый по умолчанию; получено %+v, ошибка %v", statuses, err)
+	}
+
+	status, err := GetStatus(apiClient, 2)
+	if err != nil || status.Color != "#ff8f92" {
+		t.Errorf("Получен неверный статус %+v, ошибка %v", status, err)
+	}
+
+	created, err := CreateStatuses(apiClient, []Status{{Name: "Новый"}})
+	if err != nil || len(created) != 1 || created[0].ID != 3 {
+		t.Errorf("Ожидался созданный статус с ID 3, получено %+v, ошибка %v", created, err)
+	}
+
+	updated, err := UpdateStatus(apiClient, &Status{ID: 3, Name: "Переименованный"})
+	if err != nil || updated.Name != "Переименованный" {
+		t.Errorf("Получен неверный статус %+v, ошибка %v", updated, err)
+	}
+
+	if _, err := UpdateStatus(apiClient, &Status{Name: "Без ID"}); err == nil {
+		t.Error("Ожидалась ошибка для статуса без ID, но ее не было")
+	}
+
+	if err := DeleteStatus(apiClient, 3); err != nil {
+		t.Errorf("Неожиданная ошибка при удалении статуса: %v", err)
+	}
+}
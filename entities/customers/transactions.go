@@ -0,0 +1,309 @@
+package customers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// Transaction представляет собой покупку (транзакцию) покупателя в amoCRM.
+type Transaction struct {
+	ID          int                  `json:"id,omitempty"`
+	Price       int                  `json:"price"`
+	Comment     string               `json:"comment,omitempty"`
+	CompletedAt int64                `json:"completed_at,omitempty"`
+	CustomerID  int                  `json:"customer_id,omitempty"`
+	CreatedBy   int                  `json:"created_by,omitempty"`
+	UpdatedBy   int                  `json:"updated_by,omitempty"`
+	CreatedAt   int64                `json:"created_at,omitempty"`
+	UpdatedAt   int64                `json:"updated_at,omitempty"`
+	IsDeleted   bool                 `json:"is_deleted,omitempty"`
+	AccountID   int                  `json:"account_id,omitempty"`
+	NextPrice   int                  `json:"next_price,omitempty"`
+	NextDate    int64                `json:"next_date,omitempty"`
+	Embedded    *TransactionEmbedded `json:"_embedded,omitempty"`
+}
+
+// TransactionEmbedded содержит связанные с транзакцией сущности
+type TransactionEmbedded struct {
+	Customer        *Customer                   `json:"customer,omitempty"`
+	CatalogElements []TransactionCatalogElement `json:"catalog_elements,omitempty"`
+}
+
+// TransactionCatalogElement представляет товар, входящий в покупку
+type TransactionCatalogElement struct {
+	ID        int                         `json:"id"`
+	CatalogID int                         `json:"catalog_id,omitempty"`
+	Metadata  *TransactionElementMetadata `json:"metadata,omitempty"`
+}
+
+// TransactionElementMetadata содержит количество и цену товара в покупке
+type TransactionElementMetadata struct {
+	Quantity  float64 `json:"quantity,omitempty"`
+	CatalogID int     `json:"catalog_id,omitempty"`
+	PriceID   int     `json:"price_id,omitempty"`
+}
+
+// TransactionsResponse представляет ответ от API при получении списка транзакций
+type TransactionsResponse struct {
+	Page     int `json:"page"`
+	PerPage  int `json:"per_page"`
+	Embedded struct {
+		Transactions []Transaction `json:"transactions"`
+	} `json:"_embedded"`
+}
+
+// TransactionsFilter задает параметры фильтрации списка транзакций.
+type TransactionsFilter struct {
+	// IDs - фильтр по ID транзакций
+	IDs []int
+	// WithCatalogElements - получить товары, входящие в покупки
+	WithCatalogElements bool
+}
+
+// Values преобразует фильтр в параметры запроса API amoCRM.
+func (f *TransactionsFilter) Values() url.Values {
+	params := url.Values{}
+	if f == nil {
+		return params
+	}
+
+	for _, id := range f.IDs {
+		params.Add("filter[id][]", strconv.Itoa(id))
+	}
+	if f.WithCatalogElements {
+		params.Set("with", string(WithCatalogElements))
+	}
+
+	return params
+}
+
+// GetTransactions получает список транзакций всех покупателей.
+func GetTransactions(apiClient *client.Client, page, limit int, filter *TransactionsFilter) ([]Transaction, error) {
+	url := fmt.Sprintf("%s/api/v4/customers/transactions", apiClient.GetBaseURL())
+	return getTransactions(apiClient, url, page, limit, filter)
+}
+
+// GetCustomerTransactions получает список транзакций покупателя.
+func GetCustomerTransactions(apiClient *client.Client, customerID, page, limit int, filter *TransactionsFilter) ([]Transaction, error) {
+	url := fmt.Sprintf("%s/api/v4/customers/%d/transactions", apiClient.GetBaseURL(), customerID)
+	return getTransactions(apiClient, url, page, limit, filter)
+}
+
+// getTransactions выполняет запрос списка транзакций.
+func getTransactions(apiClient *client.Client, baseURL string, page, limit int, filter *TransactionsFilter) ([]Transaction, error) {
+	params := filter.Values()
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// amoCRM возвращает 204, если транзакций нет
+	if resp.StatusCode == http.StatusNoContent {
+		return []Transaction{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response TransactionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Transactions, nil
+}
+
+// CreateTransactions добавляет покупки покупателю.
+func CreateTransactions(apiClient *client.Client, customerID int, transactions []Transaction) ([]Transaction, error) {
+	url := fmt.Sprintf("%s/api/v4/customers/%d/transactions", apiClient.GetBaseURL(), customerID)
+
+	transactionsData, err := json.Marshal(transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(transactionsData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response TransactionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Transactions, nil
+}
+
+// CreateTransaction добавляет одну покупку покупателю.
+func CreateTransaction(apiClient *client.Client, customerID int, transaction *Transaction) (*Transaction, error) {
+	created, err := CreateTransactions(apiClient, customerID, []Transaction{*transaction})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(created) == 0 {
+		return nil, fmt.Errorf("не удалось создать транзакцию")
+	}
+
+	return &created[0], nil
+}
+
+// DeleteTransaction удаляет покупку покупателя.
+func DeleteTransaction(apiClient *client.Client, customerID, transactionID int) error {
+	url := fmt.Sprintf("%s/api/v4/customers/%d/transactions/%d", apiClient.GetBaseURL(), customerID, transactionID)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// BonusPoints представляет баланс бонусных баллов покупателя после операции
+type BonusPoints struct {
+	BonusPoints int `json:"bonus_points"`
+}
+
+// EarnBonusPoints начисляет покупателю бонусные баллы и возвращает новый баланс.
+func EarnBonusPoints(apiClient *client.Client, customerID, points int) (*BonusPoints, error) {
+	return changeBonusPoints(apiClient, customerID, "earn", points)
+}
+
+// RedeemBonusPoints списывает бонусные баллы покупателя и возвращает новый баланс.
+func RedeemBonusPoints(apiClient *client.Client, customerID, points int) (*BonusPoints, error) {
+	return changeBonusPoints(apiClient, customerID, "redeem", points)
+}
+
+// changeBonusPoints выполняет начисление или списание бонусных баллов.
+func changeBonusPoints(apiClient *client.Client, customerID int, operation string, points int) (*BonusPoints, error) {
+	if points <= 0 {
+		return nil, fmt.Errorf("количество баллов должно быть положительным")
+	}
+
+	url := fmt.Sprintf("%s/api/v4/customers/%d/bonus_points", apiClient.GetBaseURL(), customerID)
+
+	requestData, err := json.Marshal(map[string]int{operation: points})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var result BonusPoints
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// TransactionsSummary содержит показатели покупателя, рассчитанные по его покупкам
+type TransactionsSummary struct {
+	// LTV - суммарная выручка по покупкам
+	LTV int
+	// PurchasesCount - количество покупок
+	PurchasesCount int
+	// AverageCheck - средний чек
+	AverageCheck int
+	// FirstPurchaseAt, LastPurchaseAt - даты первой и последней покупки (Unix timestamp)
+	FirstPurchaseAt int64
+	LastPurchaseAt  int64
+	// AverageInterval - средний интервал между покупками в секундах
+	AverageInterval int64
+	// NextPurchaseAt - ожидаемая дата следующей покупки (Unix timestamp),
+	// 0, если покупок меньше двух
+	NextPurchaseAt int64
+}
+
+// SummarizeTransactions рассчитывает LTV, средний чек и ожидаемую дату следующей покупки.
+// Удаленные транзакции и транзакции без даты покупки не учитываются.
+// Дата следующей покупки - дата последней покупки плюс средний интервал между покупками.
+func SummarizeTransactions(transactions []Transaction) TransactionsSummary {
+	var dates []int64
+	var summary TransactionsSummary
+
+	for _, transaction := range transactions {
+		if transaction.IsDeleted || transaction.CompletedAt == 0 {
+			continue
+		}
+		summary.LTV += transaction.Price
+		summary.PurchasesCount++
+		dates = append(dates, transaction.CompletedAt)
+	}
+
+	if summary.PurchasesCount == 0 {
+		return summary
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i] < dates[j] })
+	summary.AverageCheck = summary.LTV / summary.PurchasesCount
+	summary.FirstPurchaseAt = dates[0]
+	summary.LastPurchaseAt = dates[len(dates)-1]
+
+	if len(dates) > 1 {
+		summary.AverageInterval = (summary.LastPurchaseAt - summary.FirstPurchaseAt) / int64(len(dates)-1)
+		summary.NextPurchaseAt = summary.LastPurchaseAt + summary.AverageInterval
+	}
+
+	return summary
+}
@@ -0,0 +1,187 @@
+package customers
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestGetTransactions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Проверяем метод запроса
+		if r.Method != "GET" {
+			t.Errorf("Ожидался метод GET, получен %s", r.Method)
+		}
+
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v4/customers/transactions":
+			if ids := query["filter[id][]"]; len(ids) != 2 || ids[0] != "1" || ids[1] != "2" {
+				t.Errorf("Ожидались параметры filter[id][]=1,2, получено %v", ids)
+			}
+			if query.Get("with") != "catalog_elements" {
+				t.Errorf("Ожидался параметр with=catalog_elements, получен %s", query.Get("with"))
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"_embedded":{"transactions":[
+				{"id":1,"price":1000,"completed_at":1609459200,"customer_id":10,
+				 "_embedded":{"catalog_elements":[{"id":5,"metadata":{"quantity":2,"catalog_id":3}}]}},
+				{"id":2,"price":500,"completed_at":1609545600,"customer_id":11}]}}`))
+		case "/api/v4/customers/10/transactions":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный путь запроса %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	transactions, err := GetTransactions(apiClient, 1, 50, &TransactionsFilter{IDs: []int{1, 2}, WithCatalogElements: true})
+	if err != nil {
+		t.Fatalf("Ошибка при получении транзакций: %v", err)
+	}
+
+	if len(transactions) != 2 || transactions[0].Price != 1000 || transactions[1].CustomerID != 11 {
+		t.Errorf("Получены неверные транзакции: %+v", transactions)
+	}
+
+	elements := transactions[0].Embedded.CatalogElements
+	if len(elements) != 1 || elements[0].Metadata.Quantity != 2 {
+		t.Errorf("Ожидался товар в количестве 2, получено %+v", elements)
+	}
+
+	customerTransactions, err := GetCustomerTransactions(apiClient, 10, 1, 50, nil)
+	if err != nil {
+		t.Fatalf("Ошибка при получении транзакций покупателя: %v", err)
+	}
+	if len(customerTransactions) != 0 {
+		t.Errorf("Ожидался пустой список транзакций, получено %d", len(customerTransactions))
+	}
+}
+
+func TestCreateAndDeleteTransaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v4/customers/10/transactions":
+			body, _ := ioutil.ReadAll(r.Body)
+			expected := `[{"price":1500,"comment":"Заказ №42","completed_at":1609459200,"next_date":1612137600}]`
+			if string(body) != expected {
+				t.Errorf("Неожиданное тело запроса:\n%s\nожидалось:\n%s", body, expected)
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"_embedded":{"transactions":[{"id":77,"price":1500,"customer_id":10}]}}`))
+		case "DELETE /api/v4/customers/10/transactions/77":
+			w.WriteHeader(http.StatusNoContent)
+		case "DELETE /api/v4/customers/10/transactions/78":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	transaction, err := CreateTransaction(apiClient, 10, &Transaction{
+		Price:       1500,
+		Comment:     "Заказ №42",
+		CompletedAt: 1609459200,
+		NextDate:    1612137600,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при создании транзакции: %v", err)
+	}
+	if transaction.ID != 77 {
+		t.Errorf("Ожидался ID транзакции 77, получен %d", transaction.ID)
+	}
+
+	if err := DeleteTransaction(apiClient, 10, 77); err != nil {
+		t.Errorf("Неожиданная ошибка при удалении транзакции: %v", err)
+	}
+
+	if err := DeleteTransaction(apiClient, 10, 78); err == nil {
+		t.Error("Ожидалась ошибка при удалении несуществующей транзакции, но ее не было")
+	}
+}
+
+func TestBonusPoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v4/customers/10/bonus_points" {
+			t.Errorf("Ожидался запрос POST /api/v4/customers/10/bonus_points, получен %s %s", r.Method, r.URL.Path)
+		}
+
+		body, _ := ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		switch string(body) {
+		case `{"earn":500}`:
+			_, _ = w.Write([]byte(`{"bonus_points":1500}`))
+		case `{"redeem":300}`:
+			_, _ = w.Write([]byte(`{"bonus_points":1200}`))
+		default:
+			t.Errorf("Неожиданное тело запроса: %s", body)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	earned, err := EarnBonusPoints(apiClient, 10, 500)
+	if err != nil || earned.BonusPoints != 1500 {
+		t.Errorf("Ожидался баланс 1500, получено %+v, ошибка %v", earned, err)
+	}
+
+	redeemed, err := RedeemBonusPoints(apiClient, 10, 300)
+	if err != nil || redeemed.BonusPoints != 1200 {
+		t.Errorf("Ожидался баланс 1200, получено %+v, ошибка %v", redeemed, err)
+	}
+
+	if _, err := RedeemBonusPoints(apiClient, 10, 0); err == nil {
+		t.Error("Ожидалась ошибка для нулевого количества баллов, но ее не было")
+	}
+}
+
+func TestSummarizeTransactions(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	start := int64(1609459200)
+
+	t.Run("Несколько покупок", func(t *testing.T) {
+		summary := SummarizeTransactions([]Transaction{
+			{Price: 3000, CompletedAt: start + 30*day},
+			{Price: 1000, CompletedAt: start},
+			{Price: 2000, CompletedAt: start + 20*day},
+			{Price: 9000, CompletedAt: start + 25*day, IsDeleted: true},
+		})
+
+		if summary.LTV != 6000 || summary.PurchasesCount != 3 || summary.AverageCheck != 2000 {
+			t.Errorf("Неверные LTV, количество или средний чек: %+v", summary)
+		}
+		if summary.FirstPurchaseAt != start || summary.LastPurchaseAt != start+30*day {
+			t.Errorf("Неверные даты первой и последней покупки: %+v", summary)
+		}
+		if summary.AverageInterval != 15*day {
+			t.Errorf("Ожидался средний интервал 15 дней, получено %d секунд", summary.AverageInterval)
+		}
+		if summary.NextPurchaseAt != start+45*day {
+			t.Errorf("Ожидалась следующая покупка через 45 дней от начала, получено %d", summary.NextPurchaseAt)
+		}
+	})
+
+	t.Run("Одна покупка", func(t *testing.T) {
+		summary := SummarizeTransactions([]Transaction{{Price: 700, CompletedAt: start}})
+		if summary.LTV != 700 || summary.NextPurchaseAt != 0 {
+			t.Errorf("Ожидались LTV 700 и неизвестная дата следующей покупки, получено %+v", summary)
+		}
+	})
+
+	t.Run("Нет покупок", func(t *testing.T) {
+		summary := SummarizeTransactions(nil)
+		if summary != (TransactionsSummary{}) {
+			t.Errorf("Ожидались нулевые показатели, получено %+v", summary)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -11,47 +12,144 @@ import (
 )
 
 // EventType тип события.
+// Значения совпадают с типами событий amoCRM.
 type EventType string
 
 const (
-	// EventTypeNote тип события - Примечание
-	EventTypeNote EventType = "note"
-	// EventTypeCall тип события - Звонок
-	EventTypeCall EventType = "call"
-	// EventTypeMeeting тип события - Встреча
-	EventTypeMeeting EventType = "meeting"
-	// EventTypeIncomingLead тип события - Входящий лид
-	EventTypeIncomingLead EventType = "incoming_lead"
-	// EventTypeTaskResult тип события - Результат по задаче
-	EventTypeTaskResult EventType = "task_result"
-	// EventTypeMessage тип события - Сообщение
-	EventTypeMessage EventType = "message"
-	// EventTypeSendEmailStatus тип события - Статус отправки email
-	EventTypeSendEmailStatus EventType = "send_email_status"
-	// EventTypeCatalogObject тип события - Объект каталога
-	EventTypeCatalogObject EventType = "catalog_object"
-	// EventTypeEntityView тип события - Просмотр сущности
-	EventTypeEntityView EventType = "entity_view"
-	// EventTypeEntityUpdate тип события - Обновление сущности
-	EventTypeEntityUpdate EventType = "entity_update"
-	// EventTypeEntityStatusChange тип события - Изменение статуса сущности
-	EventTypeEntityStatusChange EventType = "entity_status_change"
-	// EventTypeEntityResponsibleChange тип события - Изменение ответственного сущности
-	EventTypeEntityResponsibleChange EventType = "entity_responsible_change"
-	// EventTypeEntityCreate тип события - Создание сущности
-	EventTypeEntityCreate EventType = "entity_create"
-	// EventTypeEntityDelete тип события - Удаление сущности
-	EventTypeEntityDelete EventType = "entity_delete"
-	// EventTypeActivityCreate тип события - Создание активности
-	EventTypeActivityCreate EventType = "activity_create"
-	// EventTypeActivityUpdate тип события - Обновление активности
-	EventTypeActivityUpdate EventType = "activity_update"
-	// EventTypeActivityStatusChange тип события - Изменение статуса активности
-	EventTypeActivityStatusChange EventType = "activity_status_change"
-	// EventTypeActivityDelete тип события - Удаление активности
-	EventTypeActivityDelete EventType = "activity_delete"
+	// EventTypeLeadAdded тип события - Новая сделка
+	EventTypeLeadAdded EventType = "lead_added"
+	// EventTypeLeadDeleted тип события - Сделка удалена
+	EventTypeLeadDeleted EventType = "lead_deleted"
+	// EventTypeLeadRestored тип события - Сделка восстановлена
+	EventTypeLeadRestored EventType = "lead_restored"
+	// EventTypeLeadStatusChanged тип события - Изменение этапа продажи
+	EventTypeLeadStatusChanged EventType = "lead_status_changed"
+	// EventTypeLeadLinked тип события - Прикрепление сделки
+	EventTypeLeadLinked EventType = "lead_linked"
+	// EventTypeLeadUnlinked тип события - Открепление сделки
+	EventTypeLeadUnlinked EventType = "lead_unlinked"
+	// EventTypeContactAdded тип события - Новый контакт
+	EventTypeContactAdded EventType = "contact_added"
+	// EventTypeContactDeleted тип события - Контакт удален
+	EventTypeContactDeleted EventType = "contact_deleted"
+	// EventTypeContactRestored тип события - Контакт восстановлен
+	EventTypeContactRestored EventType = "contact_restored"
+	// EventTypeCompanyAdded тип события - Новая компания
+	EventTypeCompanyAdded EventType = "company_added"
+	// EventTypeCompanyDeleted тип события - Компания удалена
+	EventTypeCompanyDeleted EventType = "company_deleted"
+	// EventTypeCompanyRestored тип события - Компания восстановлена
+	EventTypeCompanyRestored EventType = "company_restored"
+	// EventTypeCustomerAdded тип события - Новый покупатель
+	EventTypeCustomerAdded EventType = "customer_added"
+	// EventTypeCustomerDeleted тип события - Покупатель удален
+	EventTypeCustomerDeleted EventType = "customer_deleted"
+	// EventTypeCustomerStatusChanged тип события - Изменение этапа покупателя
+	EventTypeCustomerStatusChanged EventType = "customer_status_changed"
+	// EventTypeCustomerLinked тип события - Прикрепление покупателя
+	EventTypeCustomerLinked EventType = "customer_linked"
+	// EventTypeCustomerUnlinked тип события - Открепление покупателя
+	EventTypeCustomerUnlinked EventType = "customer_unlinked"
+	// EventTypeTaskAdded тип события - Новая задача
+	EventTypeTaskAdded EventType = "task_added"
+	// EventTypeTaskDeleted тип события - Задача удалена
+	EventTypeTaskDeleted EventType = "task_deleted"
+	// EventTypeTaskCompleted тип события - Завершение задачи
+	EventTypeTaskCompleted EventType = "task_completed"
+	// EventTypeTaskTypeChanged тип события - Изменение типа задачи
+	EventTypeTaskTypeChanged EventType = "task_type_changed"
+	// EventTypeTaskTextChanged тип события - Изменение текста задачи
+	EventTypeTaskTextChanged EventType = "task_text_changed"
+	// EventTypeTaskDeadlineChanged тип события - Изменение срока выполнения задачи
+	EventTypeTaskDeadlineChanged EventType = "task_deadline_changed"
+	// EventTypeTaskResultAdded тип события - Результат по задаче
+	EventTypeTaskResultAdded EventType = "task_result_added"
+	// EventTypeIncomingCall тип события - Входящий звонок
+	EventTypeIncomingCall EventType = "incoming_call"
+	// EventTypeOutgoingCall тип события - Исходящий звонок
+	EventTypeOutgoingCall EventType = "outgoing_call"
+	// EventTypeIncomingChatMessage тип события - Входящее сообщение
+	EventTypeIncomingChatMessage EventType = "incoming_chat_message"
+	// EventTypeOutgoingChatMessage тип события - Исходящее сообщение
+	EventTypeOutgoingChatMessage EventType = "outgoing_chat_message"
+	// EventTypeEntityDirectMessage тип события - Сообщение внутреннего чата
+	EventTypeEntityDirectMessage EventType = "entity_direct_message"
+	// EventTypeIncomingSMS тип события - Входящее SMS
+	EventTypeIncomingSMS EventType = "incoming_sms"
+	// EventTypeOutgoingSMS тип события - Исходящее SMS
+	EventTypeOutgoingSMS EventType = "outgoing_sms"
+	// EventTypeTalkCreated тип события - Новая беседа
+	EventTypeTalkCreated EventType = "talk_created"
+	// EventTypeEntityTagAdded тип события - Теги добавлены
+	EventTypeEntityTagAdded EventType = "entity_tag_added"
+	// EventTypeEntityTagDeleted тип события - Теги убраны
+	EventTypeEntityTagDeleted EventType = "entity_tag_deleted"
+	// EventTypeEntityLinked тип события - Прикрепление
+	EventTypeEntityLinked EventType = "entity_linked"
+	// EventTypeEntityUnlinked тип события - Открепление
+	EventTypeEntityUnlinked EventType = "entity_unlinked"
+	// EventTypeEntityMerged тип события - Выполнено объединение
+	EventTypeEntityMerged EventType = "entity_merged"
+	// EventTypeEntityResponsibleChanged тип события - Ответственный изменен
+	EventTypeEntityResponsibleChanged EventType = "entity_responsible_changed"
+	// EventTypeSaleFieldChanged тип события - Изменение поля "Бюджет"
+	EventTypeSaleFieldChanged EventType = "sale_field_changed"
+	// EventTypeNameFieldChanged тип события - Изменение поля "Название"
+	EventTypeNameFieldChanged EventType = "name_field_changed"
+	// EventTypeLTVFieldChanged тип события - Изменение поля "Сумма покупок"
+	EventTypeLTVFieldChanged EventType = "ltv_field_changed"
+	// EventTypeCustomFieldValueChanged тип события - Изменение дополнительного поля
+	EventTypeCustomFieldValueChanged EventType = "custom_field_value_changed"
+	// EventTypeCommonNoteAdded тип события - Новое примечание
+	EventTypeCommonNoteAdded EventType = "common_note_added"
+	// EventTypeCommonNoteDeleted тип события - Примечание удалено
+	EventTypeCommonNoteDeleted EventType = "common_note_deleted"
+	// EventTypeAttachmentNoteAdded тип события - Добавлен новый файл
+	EventTypeAttachmentNoteAdded EventType = "attachment_note_added"
+	// EventTypeGeoNoteAdded тип события - Новое примечание с гео-меткой
+	EventTypeGeoNoteAdded EventType = "geo_note_added"
+	// EventTypeServiceNoteAdded тип события - Новое системное примечание
+	EventTypeServiceNoteAdded EventType = "service_note_added"
+	// EventTypeSiteVisitNoteAdded тип события - Заход на сайт
+	EventTypeSiteVisitNoteAdded EventType = "site_visit_note_added"
+	// EventTypeMessageToCashierNoteAdded тип события - Сообщение кассиру
+	EventTypeMessageToCashierNoteAdded EventType = "message_to_cashier_note_added"
+	// EventTypeTargetingInNoteAdded тип события - Добавление в ретаргетинг
+	EventTypeTargetingInNoteAdded EventType = "targeting_in_note_added"
+	// EventTypeTargetingOutNoteAdded тип события - Удаление из ретаргетинга
+	EventTypeTargetingOutNoteAdded EventType = "targeting_out_note_added"
+	// EventTypeNPSRateAdded тип события - Новая оценка NPS
+	EventTypeNPSRateAdded EventType = "nps_rate_added"
+	// EventTypeLinkFollowed тип события - Переход по ссылке
+	EventTypeLinkFollowed EventType = "link_followed"
+	// EventTypeTransactionAdded тип события - Добавлена покупка
+	EventTypeTransactionAdded EventType = "transaction_added"
+	// EventTypeRobotReplied тип события - Ответ робота
+	EventTypeRobotReplied EventType = "robot_replied"
+	// EventTypeIntentIdentified тип события - Тема вопроса определена
+	EventTypeIntentIdentified EventType = "intent_identified"
+	// EventTypeKeyActionCompleted тип события - Ключевое действие
+	EventTypeKeyActionCompleted EventType = "key_action_completed"
 )
 
+// customFieldEventPrefix и customFieldEventSuffix окружают ID поля в типе события
+// изменения конкретного дополнительного поля: custom_field_{ID}_value_changed.
+const (
+	customFieldEventPrefix = "custom_field_"
+	customFieldEventSuffix = "_value_changed"
+)
+
+// CustomFieldEventType возвращает тип события изменения дополнительного поля с указанным ID.
+func CustomFieldEventType(fieldID int) EventType {
+	return EventType(customFieldEventPrefix + strconv.Itoa(fieldID) + customFieldEventSuffix)
+}
+
+// IsCustomFieldChange проверяет, что событие относится к изменению дополнительного поля.
+func (t EventType) IsCustomFieldChange() bool {
+	s := string(t)
+	return strings.HasPrefix(s, customFieldEventPrefix) && strings.HasSuffix(s, customFieldEventSuffix)
+}
+
 // EventEntityType тип сущности события.
 type EventEntityType string
 
@@ -184,11 +282,16 @@ func WithOrder(field, order string) WithOption {
 // Пример использования:
 //
 //	filter := map[string]string{
-//		"filter[type]": string(events.EventTypeNote),
+//		"filter[type]": string(events.EventTypeCommonNoteAdded),
 //		"filter[entity_type]": string(events.EventEntityTypeLead),
 //	}
 //	eventsList, err := events.GetEvents(apiClient, events.WithFilter(filter), events.WithLimit(50), events.WithPage(1))
 func GetEvents(apiClient *client.Client, options ...WithOption) ([]Event, error) {
+	return getEvents(apiClient, optionValues(options))
+}
+
+// optionValues применяет опции и возвращает параметры запроса
+func optionValues(options []WithOption) url.Values {
 	params := make(map[string]string)
 
 	// Применяем опции
@@ -196,18 +299,21 @@ func GetEvents(apiClient *client.Client, options ...WithOption) ([]Event, error)
 		option(params)
 	}
 
-	// Формируем URL с параметрами
-	url := "/api/v4/events"
-	if len(params) > 0 {
-		var queryParams []string
-		for key, value := range params {
-			queryParams = append(queryParams, fmt.Sprintf("%s=%s", key, value))
-		}
-		url += "?" + strings.Join(queryParams, "&")
+	values := url.Values{}
+	for key, value := range params {
+		values.Set(key, value)
 	}
+	return values
+}
 
+// getEvents выполняет запрос списка событий с указанными параметрами.
+// Если события не найдены, возвращается пустой список без ошибки.
+func getEvents(apiClient *client.Client, params url.Values) ([]Event, error) {
 	// Формируем полный URL с базовым URL клиента
-	fullURL := fmt.Sprintf("%s%s", apiClient.GetBaseURL(), url)
+	fullURL := fmt.Sprintf("%s/api/v4/events", apiClient.GetBaseURL())
+	if len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
 
 	// Создаем запрос
 	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
@@ -222,6 +328,16 @@ func GetEvents(apiClient *client.Client, options ...WithOption) ([]Event, error)
 	}
 	defer resp.Body.Close()
 
+	// amoCRM возвращает 204, если по запросу ничего не найдено
+	if resp.StatusCode == http.StatusNoContent {
+		return []Event{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
 	// Разбираем ответ
 	var eventsResponse GetEventsResponse
 	err = json.NewDecoder(resp.Body).Decode(&eventsResponse)
@@ -238,25 +354,11 @@ func GetEvents(apiClient *client.Client, options ...WithOption) ([]Event, error)
 //
 //	event, err := events.GetEvent(apiClient, 123, events.WithEntity())
 func GetEvent(apiClient *client.Client, eventID int, options ...WithOption) (*Event, error) {
-	params := make(map[string]string)
-
-	// Применяем опции
-	for _, option := range options {
-		option(params)
-	}
-
-	// Формируем URL с параметрами
-	url := fmt.Sprintf("/api/v4/events/%d", eventID)
-	if len(params) > 0 {
-		var queryParams []string
-		for key, value := range params {
-			queryParams = append(queryParams, fmt.Sprintf("%s=%s", key, value))
-		}
-		url += "?" + strings.Join(queryParams, "&")
-	}
-
 	// Формируем полный URL с базовым URL клиента
-	fullURL := fmt.Sprintf("%s%s", apiClient.GetBaseURL(), url)
+	fullURL := fmt.Sprintf("%s/api/v4/events/%d", apiClient.GetBaseURL(), eventID)
+	if params := optionValues(options); len(params) > 0 {
+		fullURL += "?" + params.Encode()
+	}
 
 	// Создаем запрос
 	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
@@ -271,6 +373,11 @@ func GetEvent(apiClient *client.Client, eventID int, options ...WithOption) (*Ev
 	}
 	defer resp.Body.Close()
 
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
 	// Разбираем ответ
 	var event Event
 	err = json.NewDecoder(resp.Body).Decode(&event)
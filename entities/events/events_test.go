@@ -17,7 +17,7 @@ func TestGetEvents(t *testing.T) {
 
 		// Подготавливаем фильтры и опции
 		filter := map[string]string{
-			"filter[type]":        string(EventTypeNote),
+			"filter[type]":        string(EventTypeCommonNoteAdded),
 			"filter[entity_type]": string(EventEntityTypeLead),
 		}
 
@@ -34,7 +34,7 @@ func setupGetEventsTestServer(t *testing.T, withQueryParams bool) *httptest.Serv
 			}
 
 			// Проверяем фильтры
-			expectedFilterType := "note"
+			expectedFilterType := "common_note_added"
 			if r.URL.Query().Get("filter[type]") != expectedFilterType {
 				t.Errorf("Ожидался параметр filter[type]=%s, получен %s", expectedFilterType, r.URL.Query().Get("filter[type]"))
 			}
@@ -68,13 +68,13 @@ func setupGetEventsTestServer(t *testing.T, withQueryParams bool) *httptest.Serv
 				"events": [
 					{
 						"id": 123,
-						"type": "note",
+						"type": "common_note_added",
 						"entity_id": 456,
 						"entity_type": "lead",
 						"created_by": 789,
 						"account_id": 12345,
 						"created_at": 1609459200,
-						"value_after": {"text": "Это тестовое примечание"},
+						"value_after": [{"note": {"id": 901}}],
 						"value_after_pretty": "Это тестовое примечание",
 						"_links": {
 							"self": {
@@ -84,13 +84,13 @@ func setupGetEventsTestServer(t *testing.T, withQueryParams bool) *httptest.Serv
 					},
 					{
 						"id": 124,
-						"type": "note",
+						"type": "common_note_added",
 						"entity_id": 457,
 						"entity_type": "lead",
 						"created_by": 789,
 						"account_id": 12345,
 						"created_at": 1609458200,
-						"value_after": {"text": "Еще одно тестовое примечание"},
+						"value_after": [{"note": {"id": 902}}],
 						"value_after_pretty": "Еще одно тестовое примечание",
 						"_links": {
 							"self": {
@@ -118,8 +118,8 @@ func verifyEventsList(t *testing.T, events []Event) {
 		t.Errorf("Ожидался ID 123, получен %d", events[0].ID)
 	}
 
-	if events[0].Type != EventTypeNote {
-		t.Errorf("Ожидался тип события note, получен %s", events[0].Type)
+	if events[0].Type != EventTypeCommonNoteAdded {
+		t.Errorf("Ожидался тип события common_note_added, получен %s", events[0].Type)
 	}
 
 	if events[0].EntityID != 456 {
@@ -174,13 +174,13 @@ func setupGetEventTestServer(t *testing.T, eventID int, withEntity bool) *httpte
 		if withEntity {
 			response = fmt.Sprintf(`{
 				"id": %d,
-				"type": "note",
+				"type": "common_note_added",
 				"entity_id": 456,
 				"entity_type": "lead",
 				"created_by": 789,
 				"account_id": 12345,
 				"created_at": 1609459200,
-				"value_after": {"text": "Это тестовое примечание"},
+				"value_after": [{"note": {"id": 901}}],
 				"value_after_pretty": "Это тестовое примечание",
 				"_embedded": {
 					"entity": {
@@ -199,13 +199,13 @@ func setupGetEventTestServer(t *testing.T, eventID int, withEntity bool) *httpte
 		} else {
 			response = fmt.Sprintf(`{
 				"id": %d,
-				"type": "note",
+				"type": "common_note_added",
 				"entity_id": 456,
 				"entity_type": "lead",
 				"created_by": 789,
 				"account_id": 12345,
 				"created_at": 1609459200,
-				"value_after": {"text": "Это тестовое примечание"},
+				"value_after": [{"note": {"id": 901}}],
 				"value_after_pretty": "Это тестовое примечание",
 				"_links": {
 					"self": {
@@ -225,8 +225,8 @@ func verifyEventDetails(t *testing.T, event *Event, eventID int, withEntity bool
 		t.Errorf("Ожидался ID %d, получен %d", eventID, event.ID)
 	}
 
-	if event.Type != EventTypeNote {
-		t.Errorf("Ожидался тип события note, получен %s", event.Type)
+	if event.Type != EventTypeCommonNoteAdded {
+		t.Errorf("Ожидался тип события common_note_added, получен %s", event.Type)
 	}
 
 	if event.EntityID != 456 {
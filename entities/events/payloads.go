@@ -0,0 +1,296 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Payload значение события до или после изменения.
+// Конкретный тип зависит от типа события, неизвестные значения возвращаются как RawPayload.
+type Payload interface{}
+
+// RawPayload исходное значение события, для которого нет типизированной структуры.
+type RawPayload json.RawMessage
+
+// LeadStatus этап сделки, значение событий lead_added и lead_status_changed.
+type LeadStatus struct {
+	StatusID   int `json:"id"`
+	PipelineID int `json:"pipeline_id"`
+}
+
+// CustomerStatus этап покупателя, значение события customer_status_changed.
+type CustomerStatus struct {
+	StatusID int `json:"id"`
+}
+
+// ResponsibleUser ответственный пользователь, значение события entity_responsible_changed.
+type ResponsibleUser struct {
+	UserID int `json:"id"`
+}
+
+// CustomFieldValue значение дополнительного поля в событии изменения поля.
+// Для полей с вариантами указывается EnumID, для остальных - Text.
+type CustomFieldValue struct {
+	FieldID   int    `json:"field_id"`
+	FieldType int    `json:"field_type"`
+	EnumID    int    `json:"enum_id,omitempty"`
+	Text      string `json:"text,omitempty"`
+}
+
+// CustomFieldValues значения дополнительного поля, значение события custom_field_value_changed.
+// Поле со множественным выбором передается несколькими значениями.
+type CustomFieldValues []CustomFieldValue
+
+// SaleFieldValue бюджет, значение события sale_field_changed.
+type SaleFieldValue struct {
+	Sale int `json:"sale"`
+}
+
+// NameFieldValue название, значение события name_field_changed.
+type NameFieldValue struct {
+	Name string `json:"name"`
+}
+
+// LTVFieldValue сумма покупок, значение события ltv_field_changed.
+type LTVFieldValue struct {
+	LTV int `json:"ltv"`
+}
+
+// Tag тег в событиях entity_tag_added и entity_tag_deleted.
+type Tag struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// Tags добавленные или убранные теги.
+type Tags []Tag
+
+// NoteRef ссылка на примечание.
+// Значение событий звонков, SMS и примечаний - сами данные звонка хранятся в примечании.
+type NoteRef struct {
+	ID int `json:"id"`
+}
+
+// Message сообщение чата, значение событий incoming_chat_message и outgoing_chat_message.
+type Message struct {
+	ID     string `json:"id"`
+	Origin string `json:"origin,omitempty"`
+	TalkID int    `json:"talk_id,omitempty"`
+}
+
+// Talk беседа, значение события talk_created.
+type Talk struct {
+	ID     int    `json:"id"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// LinkedEntity прикрепленная сущность, значение событий прикрепления и открепления.
+type LinkedEntity struct {
+	EntityType EventEntityType `json:"type"`
+	EntityID   int             `json:"id"`
+}
+
+// TaskDeadline срок выполнения задачи, значение события task_deadline_changed.
+type TaskDeadline struct {
+	Timestamp int64 `json:"timestamp"`
+}
+
+// TaskType тип задачи, значение события task_type_changed.
+type TaskType struct {
+	ID int `json:"id"`
+}
+
+// TaskText текст задачи, значение события task_text_changed.
+type TaskText struct {
+	Text string `json:"text"`
+}
+
+// valueItem элемент массива value_before или value_after
+type valueItem map[string]json.RawMessage
+
+// payloadDecoder преобразует элементы значения события в типизированное значение
+type payloadDecoder func(items []valueItem) (Payload, error)
+
+// single декодирует первый элемент с ключом key в значение, созданное newValue
+func single(key string, newValue func() Payload) payloadDecoder {
+	return func(items []valueItem) (Payload, error) {
+		for _, item := range items {
+			raw, ok := item[key]
+			if !ok {
+				continue
+			}
+			value := newValue()
+			if err := json.Unmarshal(raw, value); err != nil {
+				return nil, err
+			}
+			return value, nil
+		}
+		return nil, nil
+	}
+}
+
+// decodeTags собирает теги из всех элементов значения события
+func decodeTags(items []valueItem) (Payload, error) {
+	var tags Tags
+	for _, item := range items {
+		raw, ok := item["tag"]
+		if !ok {
+			continue
+		}
+		var tag Tag
+		if err := json.Unmarshal(raw, &tag); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if tags == nil {
+		return nil, nil
+	}
+	return tags, nil
+}
+
+// decodeCustomFieldValues собирает значения дополнительного поля из всех элементов значения события
+func decodeCustomFieldValues(items []valueItem) (Payload, error) {
+	var values CustomFieldValues
+	for _, item := range items {
+		raw, ok := item["custom_field_value"]
+		if !ok {
+			continue
+		}
+		var value CustomFieldValue
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	if values == nil {
+		return nil, nil
+	}
+	return values, nil
+}
+
+// decodeLinkedEntity декодирует прикрепленную сущность
+func decodeLinkedEntity(items []valueItem) (Payload, error) {
+	var link struct {
+		Entity *LinkedEntity `json:"entity"`
+	}
+	value, err := single("link", func() Payload { return &link })(items)
+	if value == nil || link.Entity == nil || err != nil {
+		return nil, err
+	}
+	return link.Entity, nil
+}
+
+var (
+	leadStatusDecoder = single("lead_status", func() Payload { return &LeadStatus{} })
+	noteDecoder       = single("note", func() Payload { return &NoteRef{} })
+	messageDecoder    = single("message", func() Payload { return &Message{} })
+)
+
+// payloadDecoders декодеры значений по типу события
+var payloadDecoders = map[EventType]payloadDecoder{
+	EventTypeLeadAdded:                 leadStatusDecoder,
+	EventTypeLeadRestored:              leadStatusDecoder,
+	EventTypeLeadStatusChanged:         leadStatusDecoder,
+	EventTypeCustomerStatusChanged:     single("customer_status", func() Payload { return &CustomerStatus{} }),
+	EventTypeEntityResponsibleChanged:  single("responsible_user", func() Payload { return &ResponsibleUser{} }),
+	EventTypeCustomFieldValueChanged:   decodeCustomFieldValues,
+	EventTypeSaleFieldChanged:          single("sale_field_value", func() Payload { return &SaleFieldValue{} }),
+	EventTypeNameFieldChanged:          single("name_field_value", func() Payload { return &NameFieldValue{} }),
+	EventTypeLTVFieldChanged:           single("ltv_field_value", func() Payload { return &LTVFieldValue{} }),
+	EventTypeEntityTagAdded:            decodeTags,
+	EventTypeEntityTagDeleted:          decodeTags,
+	EventTypeLeadLinked:                decodeLinkedEntity,
+	EventTypeLeadUnlinked:              decodeLinkedEntity,
+	EventTypeCustomerLinked:            decodeLinkedEntity,
+	EventTypeCustomerUnlinked:          decodeLinkedEntity,
+	EventTypeEntityLinked:              decodeLinkedEntity,
+	EventTypeEntityUnlinked:            decodeLinkedEntity,
+	EventTypeIncomingCall:              noteDecoder,
+	EventTypeOutgoingCall:              noteDecoder,
+	EventTypeIncomingSMS:               noteDecoder,
+	EventTypeOutgoingSMS:               noteDecoder,
+	EventTypeCommonNoteAdded:           noteDecoder,
+	EventTypeCommonNoteDeleted:         noteDecoder,
+	EventTypeAttachmentNoteAdded:       noteDecoder,
+	EventTypeGeoNoteAdded:              noteDecoder,
+	EventTypeServiceNoteAdded:          noteDecoder,
+	EventTypeSiteVisitNoteAdded:        noteDecoder,
+	EventTypeMessageToCashierNoteAdded: noteDecoder,
+	EventTypeIncomingChatMessage:       messageDecoder,
+	EventTypeOutgoingChatMessage:       messageDecoder,
+	EventTypeTalkCreated:               single("talk", func() Payload { return &Talk{} }),
+	EventTypeTaskDeadlineChanged:       single("task_deadline", func() Payload { return &TaskDeadline{} }),
+	EventTypeTaskTypeChanged:           single("task_type", func() Payload { return &TaskType{} }),
+	EventTypeTaskTextChanged:           single("task", func() Payload { return &TaskText{} }),
+}
+
+// Decode возвращает типизированные значения события до и после изменения.
+//
+// Тип значения зависит от типа события: *LeadStatus для lead_status_changed,
+// CustomFieldValues для изменения дополнительного поля, Tags для тегов, *NoteRef для звонков
+// и примечаний и т.д. Если тип события неизвестен или значение имеет другой формат,
+// возвращается RawPayload. Для пустого значения возвращается nil.
+//
+// Пример использования:
+//
+//	_, after := event.Decode()
+//	if status, ok := after.(*events.LeadStatus); ok {
+//		fmt.Println(status.PipelineID, status.StatusID)
+//	}
+func (e *Event) Decode() (before, after Payload) {
+	decode, ok := payloadDecoders[e.Type]
+	if !ok && e.Type.IsCustomFieldChange() {
+		decode, ok = decodeCustomFieldValues, true
+	}
+	if !ok {
+		return rawPayload(e.ValueBefore), rawPayload(e.ValueAfter)
+	}
+
+	return decodePayload(e.ValueBefore, decode), decodePayload(e.ValueAfter, decode)
+}
+
+// decodePayload разбирает value_before или value_after.
+// amoCRM передает значение массивом элементов, одиночный объект считается массивом из одного элемента.
+// Значение, которое не удалось разобрать, возвращается как RawPayload.
+func decodePayload(raw json.RawMessage, decode payloadDecoder) Payload {
+	trimmed := bytes.TrimSpace(raw)
+	if isEmptyValue(trimmed) {
+		return nil
+	}
+
+	var items []valueItem
+	switch trimmed[0] {
+	case '[':
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return rawPayload(raw)
+		}
+	case '{':
+		var item valueItem
+		if err := json.Unmarshal(trimmed, &item); err != nil {
+			return rawPayload(raw)
+		}
+		items = []valueItem{item}
+	default:
+		return rawPayload(raw)
+	}
+
+	value, err := decode(items)
+	if err != nil || value == nil {
+		return rawPayload(raw)
+	}
+	return value
+}
+
+// rawPayload возвращает исходное значение или nil для пустого значения
+func rawPayload(raw json.RawMessage) Payload {
+	if isEmptyValue(bytes.TrimSpace(raw)) {
+		return nil
+	}
+	return RawPayload(raw)
+}
+
+// isEmptyValue проверяет, что значение события не передано
+func isEmptyValue(raw []byte) bool {
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null")) || bytes.Equal(raw, []byte("[]"))
+}
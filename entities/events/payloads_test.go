@@ -0,0 +1,134 @@
+package events
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEventDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		event  string
+		before Payload
+		after  Payload
+	}{
+		{
+			name: "Изменение этапа сделки",
+			event: `{"id": 1, "type": "lead_status_changed", "entity_type": "lead",
+				"value_before": [{"lead_status": {"id": 100, "pipeline_id": 10}}],
+				"value_after": [{"lead_status": {"id": 142, "pipeline_id": 10}}]}`,
+			before: &LeadStatus{StatusID: 100, PipelineID: 10},
+			after:  &LeadStatus{StatusID: 142, PipelineID: 10},
+		},
+		{
+			name: "Изменение дополнительного поля",
+			event: `{"id": 2, "type": "custom_field_555_value_changed", "entity_type": "lead",
+				"value_before": [],
+				"value_after": [{"custom_field_value": {"field_id": 555, "field_type": 5, "enum_id": 7, "text": "Сайт"}},
+					{"custom_field_value": {"field_id": 555, "field_type": 5, "enum_id": 8, "text": "Реклама"}}]}`,
+			after: CustomFieldValues{
+				{FieldID: 555, FieldType: 5, EnumID: 7, Text: "Сайт"},
+				{FieldID: 555, FieldType: 5, EnumID: 8, Text: "Реклама"},
+			},
+		},
+		{
+			name: "Смена ответственного",
+			event: `{"id": 3, "type": "entity_responsible_changed", "entity_type": "contact",
+				"value_before": [{"responsible_user": {"id": 11}}],
+				"value_after": [{"responsible_user": {"id": 12}}]}`,
+			before: &ResponsibleUser{UserID: 11},
+			after:  &ResponsibleUser{UserID: 12},
+		},
+		{
+			name: "Добавление тегов",
+			event: `{"id": 4, "type": "entity_tag_added", "entity_type": "lead",
+				"value_after": [{"tag": {"name": "vip"}}, {"tag": {"name": "опт"}}]}`,
+			after: Tags{{Name: "vip"}, {Name: "опт"}},
+		},
+		{
+			name: "Изменение бюджета",
+			event: `{"id": 5, "type": "sale_field_changed", "entity_type": "lead",
+				"value_before": [{"sale_field_value": {"sale": 1000}}],
+				"value_after": [{"sale_field_value": {"sale": 1500}}]}`,
+			before: &SaleFieldValue{Sale: 1000},
+			after:  &SaleFieldValue{Sale: 1500},
+		},
+		{
+			name: "Входящий звонок",
+			event: `{"id": 6, "type": "incoming_call", "entity_type": "contact",
+				"value_after": [{"note": {"id": 42}}]}`,
+			after: &NoteRef{ID: 42},
+		},
+		{
+			name: "Прикрепление контакта",
+			event: `{"id": 7, "type": "entity_linked", "entity_type": "lead",
+				"value_after": [{"link": {"entity": {"type": "contact", "id": 33}}}]}`,
+			after: &LinkedEntity{EntityType: EventEntityTypeContact, EntityID: 33},
+		},
+		{
+			name: "Неизвестный тип события",
+			event: `{"id": 8, "type": "robot_replied", "entity_type": "lead",
+				"value_after": [{"robot": {"id": 1}}]}`,
+			after: RawPayload(`[{"robot": {"id": 1}}]`),
+		},
+		{
+			name: "Неожиданный формат значения",
+			event: `{"id": 9, "type": "lead_status_changed", "entity_type": "lead",
+				"value_after": [{"something": {"id": 1}}]}`,
+			after: RawPayload(`[{"something": {"id": 1}}]`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event Event
+			if err := json.Unmarshal([]byte(tt.event), &event); err != nil {
+				t.Fatalf("Ошибка при разборе события: %v", err)
+			}
+
+			before, after := event.Decode()
+			if !reflect.DeepEqual(before, tt.before) {
+				t.Errorf("Ожидалось значение до %#v, получено %#v", tt.before, before)
+			}
+			if !reflect.DeepEqual(after, tt.after) {
+				t.Errorf("Ожидалось значение после %#v, получено %#v", tt.after, after)
+			}
+		})
+	}
+}
+
+func TestEventDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"Неверный тип значения этапа", `[{"lead_status": {"id": "сто"}}]`},
+		{"Неверный объект", `{"lead_status": `},
+		{"Неверный массив", `[{"lead_status": `},
+		{"Строка", `"сто"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := Event{ID: 1, Type: EventTypeLeadStatusChanged, ValueAfter: json.RawMessage(tt.value)}
+			_, after := event.Decode()
+			if !reflect.DeepEqual(after, RawPayload(tt.value)) {
+				t.Errorf("Ожидалось исходное значение %s, получено %#v", tt.value, after)
+			}
+		})
+	}
+}
+
+func TestCustomFieldEventType(t *testing.T) {
+	eventType := CustomFieldEventType(555)
+	if eventType != "custom_field_555_value_changed" {
+		t.Errorf("Ожидался тип custom_field_555_value_changed, получен %s", eventType)
+	}
+	if !eventType.IsCustomFieldChange() || !EventTypeCustomFieldValueChanged.IsCustomFieldChange() {
+		t.Error("Тип события изменения поля не распознан")
+	}
+	if EventTypeLeadStatusChanged.IsCustomFieldChange() {
+		t.Error("Тип lead_status_changed ошибочно распознан как изменение поля")
+	}
+}
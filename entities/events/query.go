@@ -0,0 +1,230 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// Поля сортировки списка событий
+const (
+	// OrderByCreatedAt - сортировка по дате создания
+	OrderByCreatedAt = "created_at"
+	// OrderByID - сортировка по ID
+	OrderByID = "id"
+)
+
+// MaxEntityIDs максимальное количество ID сущностей в одном запросе событий.
+const MaxEntityIDs = 10
+
+// StatusFilter этап сделки в фильтре по значению до или после изменения.
+type StatusFilter struct {
+	PipelineID int
+	StatusID   int
+}
+
+// PipelineStatuses возвращает фильтр по этапам воронки.
+// ID этапов воронки можно получить через pipelines.ListStatuses.
+func PipelineStatuses(pipelineID int, statusIDs ...int) []StatusFilter {
+	filters := make([]StatusFilter, 0, len(statusIDs))
+	for _, statusID := range statusIDs {
+		filters = append(filters, StatusFilter{PipelineID: pipelineID, StatusID: statusID})
+	}
+	return filters
+}
+
+// EventsQuery задает параметры фильтрации и сортировки списка событий.
+type EventsQuery struct {
+	// CreatedAtFrom, CreatedAtTo - диапазон даты создания события (Unix timestamp)
+	CreatedAtFrom int64
+	CreatedAtTo   int64
+	// CreatedBy - фильтр по ID пользователей, создавших события
+	CreatedBy []int
+	// EntityTypes - фильтр по типам сущностей
+	EntityTypes []EventEntityType
+	// EntityIDs - фильтр по ID сущностей, используется с одним типом сущности, не более MaxEntityIDs
+	EntityIDs []int
+	// Types - фильтр по типам событий
+	Types []EventType
+	// CustomFieldIDs - события изменения указанных дополнительных полей
+	CustomFieldIDs []int
+	// StatusesBefore, StatusesAfter - этапы сделки до и после изменения
+	StatusesBefore []StatusFilter
+	StatusesAfter  []StatusFilter
+	// ResponsibleBefore, ResponsibleAfter - ответственные до и после изменения
+	ResponsibleBefore []int
+	ResponsibleAfter  []int
+	// ValueBefore, ValueAfter - значение дополнительного поля, бюджета или названия до и после изменения.
+	// Используются с одним типом события, например с одним полем из CustomFieldIDs.
+	ValueBefore string
+	ValueAfter  string
+	// OrderBy - поле сортировки: OrderByCreatedAt или OrderByID
+	OrderBy string
+	// OrderDesc - сортировка по убыванию
+	OrderDesc bool
+}
+
+// Values преобразует запрос в параметры запроса API amoCRM.
+func (q *EventsQuery) Values() url.Values {
+	params := url.Values{}
+	if q == nil {
+		return params
+	}
+
+	if q.CreatedAtFrom > 0 {
+		params.Set("filter[created_at][from]", strconv.FormatInt(q.CreatedAtFrom, 10))
+	}
+	if q.CreatedAtTo > 0 {
+		params.Set("filter[created_at][to]", strconv.FormatInt(q.CreatedAtTo, 10))
+	}
+	for _, userID := range q.CreatedBy {
+		params.Add("filter[created_by][]", strconv.Itoa(userID))
+	}
+	for _, entityType := range q.EntityTypes {
+		params.Add("filter[entity][]", string(entityType))
+	}
+	for _, entityID := range q.EntityIDs {
+		params.Add("filter[entity_id][]", strconv.Itoa(entityID))
+	}
+	if types := q.types(); len(types) > 0 {
+		params.Set("filter[type]", strings.Join(types, ","))
+	}
+
+	addValueFilter(params, "value_before", q.StatusesBefore, q.ResponsibleBefore, q.ValueBefore)
+	addValueFilter(params, "value_after", q.StatusesAfter, q.ResponsibleAfter, q.ValueAfter)
+
+	if q.OrderBy != "" {
+		order := "asc"
+		if q.OrderDesc {
+			order = "desc"
+		}
+		params.Set("order["+q.OrderBy+"]", order)
+	}
+
+	return params
+}
+
+// types возвращает типы событий запроса вместе с событиями изменения дополнительных полей
+func (q *EventsQuery) types() []string {
+	var types []string
+	for _, eventType := range q.Types {
+		types = append(types, string(eventType))
+	}
+	for _, fieldID := range q.CustomFieldIDs {
+		types = append(types, string(CustomFieldEventType(fieldID)))
+	}
+	return types
+}
+
+// validate проверяет ограничения amoCRM на сочетание параметров
+func (q *EventsQuery) validate() error {
+	if q == nil || len(q.EntityIDs) == 0 {
+		return nil
+	}
+	if len(q.EntityTypes) != 1 {
+		return fmt.Errorf("фильтр по ID сущностей требует ровно один тип сущности, указано %d", len(q.EntityTypes))
+	}
+	if len(q.EntityIDs) > MaxEntityIDs {
+		return fmt.Errorf("фильтр по ID сущностей поддерживает не более %d ID, указано %d", MaxEntityIDs, len(q.EntityIDs))
+	}
+	return nil
+}
+
+// addValueFilter добавляет фильтр по значению до или после изменения
+func addValueFilter(params url.Values, field string, statuses []StatusFilter, responsible []int, value string) {
+	prefix := "filter[" + field + "]"
+	for i, status := range statuses {
+		statusPrefix := fmt.Sprintf("%s[leads_statuses][%d]", prefix, i)
+		if status.PipelineID > 0 {
+			params.Set(statusPrefix+"[pipeline_id]", strconv.Itoa(status.PipelineID))
+		}
+		if status.StatusID > 0 {
+			params.Set(statusPrefix+"[status_id]", strconv.Itoa(status.StatusID))
+		}
+	}
+	for _, userID := range responsible {
+		params.Add(prefix+"[responsible_user_id][]", strconv.Itoa(userID))
+	}
+	if value != "" {
+		params.Set(prefix+"[value]", value)
+	}
+}
+
+// FindEvents получает список событий, подходящих под запрос.
+// Если события не найдены, возвращается пустой список без ошибки.
+//
+// Пример использования:
+//
+//	list, err := events.FindEvents(apiClient, 1, 100, &events.EventsQuery{
+//		Types:         []events.EventType{events.EventTypeLeadStatusChanged},
+//		CreatedAtFrom: time.Now().AddDate(0, 0, -7).Unix(),
+//		StatusesAfter: events.PipelineStatuses(pipelineID, statusIDs...),
+//	})
+func FindEvents(apiClient *client.Client, page, limit int, query *EventsQuery) ([]Event, error) {
+	if err := query.validate(); err != nil {
+		return nil, err
+	}
+
+	params := query.Values()
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	return getEvents(apiClient, params)
+}
+
+// EventTypeInfo описание типа события с локализованным названием.
+type EventTypeInfo struct {
+	Key  EventType `json:"key"`
+	Type int       `json:"type"`
+	Name string    `json:"lang"`
+}
+
+// ListEventTypes получает типы событий, доступные в аккаунте, с названиями на указанном языке.
+// Поддерживаемые языки - "ru", "en", "es", пустая строка - язык аккаунта.
+func ListEventTypes(apiClient *client.Client, languageCode string) ([]EventTypeInfo, error) {
+	fullURL := fmt.Sprintf("%s/api/v4/events/types", apiClient.GetBaseURL())
+	if languageCode != "" {
+		fullURL += "?" + url.Values{"language_code": {languageCode}}.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при создании запроса: %w", err)
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выполнении запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			EventsTypes []EventTypeInfo `json:"events_types"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе ответа: %w", err)
+	}
+
+	return response.Embedded.EventsTypes, nil
+}
+
+// EventTypeNames возвращает названия типов событий по ключу.
+func EventTypeNames(types []EventTypeInfo) map[EventType]string {
+	names := make(map[EventType]string, len(types))
+	for _, eventType := range types {
+		names[eventType.Key] = eventType.Name
+	}
+	return names
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestEventsQueryValues(t *testing.T) {
+	query := &EventsQuery{
+		CreatedAtFrom:    1700000000,
+		CreatedAtTo:      1700600000,
+		CreatedBy:        []int{5},
+		EntityTypes:      []EventEntityType{EventEntityTypeLead},
+		EntityIDs:        []int{1, 2},
+		Types:            []EventType{EventTypeLeadStatusChanged},
+		CustomFieldIDs:   []int{555},
+		StatusesAfter:    PipelineStatuses(10, 100, 142),
+		ResponsibleAfter: []int{7},
+		ValueBefore:      "Сайт",
+		OrderBy:          OrderByCreatedAt,
+		OrderDesc:        true,
+	}
+
+	expected := map[string][]string{
+		"filter[created_at][from]": {"1700000000"},
+		"filter[created_at][to]":   {"1700600000"},
+		"filter[created_by][]":     {"5"},
+		"filter[entity][]":         {"lead"},
+		"filter[entity_id][]":      {"1", "2"},
+		"filter[type]":             {"lead_status_changed,custom_field_555_value_changed"},
+		"filter[value_after][leads_statuses][0][pipeline_id]": {"10"},
+		"filter[value_after][leads_statuses][0][status_id]":   {"100"},
+		"filter[value_after][leads_statuses][1][pipeline_id]": {"10"},
+		"filter[value_after][leads_statuses][1][status_id]":   {"142"},
+		"filter[value_after][responsible_user_id][]":          {"7"},
+		"filter[value_before][value]":                         {"Сайт"},
+		"order[created_at]":                                   {"desc"},
+	}
+
+	values := query.Values()
+	if len(values) != len(expected) {
+		t.Errorf("Ожидалось %d параметров, получено %d: %v", len(expected), len(values), values)
+	}
+	for key, want := range expected {
+		got := values[key]
+		if len(got) != len(want) {
+			t.Errorf("Параметр %s: ожидалось %v, получено %v", key, want, got)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("Параметр %s: ожидалось %v, получено %v", key, want, got)
+			}
+		}
+	}
+
+	var empty *EventsQuery
+	if len(empty.Values()) != 0 {
+		t.Error("Пустой запрос не должен добавлять параметры")
+	}
+}
+
+func TestFindEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/events" {
+			t.Errorf("Ожидался путь /api/v4/events, получен %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("filter[type]") != "entity_responsible_changed" {
+			t.Errorf("Ожидался фильтр по типу события, получен %s", r.URL.RawQuery)
+		}
+		if r.URL.Query().Get("page") != "2" || r.URL.Query().Get("limit") != "100" {
+			t.Errorf("Неверные параметры пагинации: %s", r.URL.RawQuery)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	list, err := FindEvents(apiClient, 2, 100, &EventsQuery{
+		Types: []EventType{EventTypeEntityResponsibleChanged},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при получении событий: %v", err)
+	}
+	if list == nil || len(list) != 0 {
+		t.Errorf("Ожидался пустой список, получено %v", list)
+	}
+
+	_, err = FindEvents(apiClient, 1, 100, &EventsQuery{EntityIDs: []int{1}})
+	if err == nil {
+		t.Error("Ожидалась ошибка при фильтре по ID без типа сущности, но ее не было")
+	}
+}
+
+func TestListEventTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/events/types" {
+			t.Errorf("Ожидался путь /api/v4/events/types, получен %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("language_code") != "ru" {
+			t.Errorf("Ожидался параметр language_code=ru, получен %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"_embedded": {"events_types": [
+			{"key": "lead_added", "type": 1, "lang": "Новая сделка"},
+			{"key": "lead_status_changed", "type": 14, "lang": "Изменение этапа продажи"}
+		]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	types, err := ListEventTypes(apiClient, "ru")
+	if err != nil {
+		t.Fatalf("Ошибка при получении типов событий: %v", err)
+	}
+	if len(types) != 2 {
+		t.Fatalf("Ожидалось 2 типа событий, получено %d", len(types))
+	}
+
+	names := EventTypeNames(types)
+	if names[EventTypeLeadStatusChanged] != "Изменение этапа продажи" {
+		t.Errorf("Ожидалось название 'Изменение этапа продажи', получено '%s'", names[EventTypeLeadStatusChanged])
+	}
+}
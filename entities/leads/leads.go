@@ -97,8 +97,46 @@ func GetLead(apiClient *client.Client, leadID int, withOptions ...WithOption) (*
 	return &lead, nil
 }
 
+// Validator проверяет лид перед отправкой в amoCRM, например по схеме аккаунта.
+type Validator interface {
+	ValidateLead(lead *Lead) error
+}
+
+// SaveOption задает дополнительные параметры создания и обновления лида
+type SaveOption func(*saveOptions)
+
+// saveOptions содержит параметры создания и обновления лида
+type saveOptions struct {
+	validator Validator
+}
+
+// WithValidator включает проверку лида перед отправкой запроса.
+// Если проверка не пройдена, запрос не выполняется и возвращается ошибка валидатора.
+func WithValidator(validator Validator) SaveOption {
+	return func(options *saveOptions) {
+		options.validator = validator
+	}
+}
+
+// applySaveOptions применяет параметры сохранения и выполняет проверку лида
+func applySaveOptions(lead *Lead, opts []SaveOption) error {
+	var options saveOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.validator != nil {
+		return options.validator.ValidateLead(lead)
+	}
+	return nil
+}
+
 // CreateLead создает новый лид в amoCRM.
-func CreateLead(apiClient *client.Client, lead *Lead) (*Lead, error) {
+func CreateLead(apiClient *client.Client, lead *Lead, opts ...SaveOption) (*Lead, error) {
+	if err := applySaveOptions(lead, opts); err != nil {
+		return nil, err
+	}
+
 	url := fmt.Sprintf("%s/api/v4/leads", apiClient.GetBaseURL())
 
 	leadData, err := json.Marshal([]*Lead{lead})
@@ -137,11 +175,15 @@ func CreateLead(apiClient *client.Client, lead *Lead) (*Lead, error) {
 }
 
 // UpdateLead обновляет существующий лид в amoCRM.
-func UpdateLead(apiClient *client.Client, lead *Lead) (*Lead, error) {
+func UpdateLead(apiClient *client.Client, lead *Lead, opts ...SaveOption) (*Lead, error) {
 	if lead.ID == 0 {
 		return nil, fmt.Errorf("ID лида не указан")
 	}
 
+	if err := applySaveOptions(lead, opts); err != nil {
+		return nil, err
+	}
+
 	url := fmt.Sprintf("%s/api/v4/leads/%d", apiClient.GetBaseURL(), lead.ID)
 
 	leadData, err := json.Marshal(lead)
@@ -286,6 +328,11 @@ func GetLeads(apiClient *client.Client, page, limit int, filter map[string]strin
 	}
 	defer resp.Body.Close()
 
+	// Если лидов нет, amoCRM возвращает 204 без тела
+	if resp.StatusCode == http.StatusNoContent {
+		return []Lead{}, nil
+	}
+
 	// Проверяем статус-код ответа
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
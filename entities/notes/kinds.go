@@ -0,0 +1,225 @@
+package notes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// NoteType - тип примечания
+type NoteType string
+
+// Типы примечаний
+const (
+	NoteTypeCommon                 NoteType = "common"
+	NoteTypeCallIn                 NoteType = "call_in"
+	NoteTypeCallOut                NoteType = "call_out"
+	NoteTypeServiceMessage         NoteType = "service_message"
+	NoteTypeExtendedServiceMessage NoteType = "extended_service_message"
+	NoteTypeSMSIn                  NoteType = "sms_in"
+	NoteTypeSMSOut                 NoteType = "sms_out"
+	NoteTypeGeolocation            NoteType = "geolocation"
+	NoteTypeAttachment             NoteType = "attachment"
+)
+
+// CommonParams - параметры обычного примечания
+type CommonParams struct {
+	Text string `json:"text"`
+}
+
+// CallParams - параметры примечания о входящем или исходящем звонке
+type CallParams struct {
+	// UniqueID - уникальный идентификатор звонка в телефонии
+	UniqueID string `json:"uniq"`
+	// Duration - длительность звонка в секундах
+	Duration int `json:"duration"`
+	// Source - источник звонка (название интеграции)
+	Source string `json:"source"`
+	// Link - ссылка на запись звонка
+	Link string `json:"link,omitempty"`
+	// Phone - номер телефона собеседника
+	Phone      string `json:"phone"`
+	CallResult string `json:"call_result,omitempty"`
+	CallStatus int    `json:"call_status,omitempty"`
+}
+
+// ServiceMessageParams - параметры системного примечания
+type ServiceMessageParams struct {
+	// Service - название сервиса, от имени которого добавлено примечание
+	Service string `json:"service"`
+	Text    string `json:"text"`
+}
+
+// SMSParams - параметры примечания о входящем или исходящем SMS
+type SMSParams struct {
+	Text  string `json:"text"`
+	Phone string `json:"phone"`
+}
+
+// GeolocationParams - параметры примечания с геолокацией
+type GeolocationParams struct {
+	Text      string `json:"text"`
+	Address   string `json:"address"`
+	Longitude string `json:"longitude"`
+	Latitude  string `json:"latitude"`
+}
+
+// AttachmentParams - параметры примечания с файлом из файлового хранилища amoCRM
+type AttachmentParams struct {
+	FileUUID     string `json:"file_uuid"`
+	VersionUUID  string `json:"version_uuid,omitempty"`
+	FileName     string `json:"file_name"`
+	OriginalName string `json:"original_name,omitempty"`
+}
+
+// NewNote создает примечание указанного типа с параметрами params.
+func NewNote(noteType NoteType, params interface{}) (*Note, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	return &Note{NoteType: noteType, Params: data}, nil
+}
+
+// newNote создает примечание с параметрами из этого пакета, сериализация которых не завершается ошибкой
+func newNote(noteType NoteType, params interface{}) *Note {
+	data, _ := json.Marshal(params)
+	return &Note{NoteType: noteType, Params: data}
+}
+
+// CommonNote создает обычное текстовое примечание.
+func CommonNote(text string) *Note {
+	return newNote(NoteTypeCommon, CommonParams{Text: text})
+}
+
+// CallInNote создает примечание о входящем звонке.
+func CallInNote(params CallParams) *Note {
+	return newNote(NoteTypeCallIn, params)
+}
+
+// CallOutNote создает примечание об исходящем звонке.
+func CallOutNote(params CallParams) *Note {
+	return newNote(NoteTypeCallOut, params)
+}
+
+// ServiceMessageNote создает системное примечание от имени сервиса.
+func ServiceMessageNote(service, text string) *Note {
+	return newNote(NoteTypeServiceMessage, ServiceMessageParams{Service: service, Text: text})
+}
+
+// ExtendedServiceMessageNote создает расширенное системное примечание от имени сервиса.
+func ExtendedServiceMessageNote(service, text string) *Note {
+	return newNote(NoteTypeExtendedServiceMessage, ServiceMessageParams{Service: service, Text: text})
+}
+
+// SMSInNote создает примечание о входящем SMS.
+func SMSInNote(phone, text string) *Note {
+	return newNote(NoteTypeSMSIn, SMSParams{Text: text, Phone: phone})
+}
+
+// SMSOutNote создает примечание об исходящем SMS.
+func SMSOutNote(phone, text string) *Note {
+	return newNote(NoteTypeSMSOut, SMSParams{Text: text, Phone: phone})
+}
+
+// GeolocationNote создает примечание с геолокацией.
+func GeolocationNote(params GeolocationParams) *Note {
+	return newNote(NoteTypeGeolocation, params)
+}
+
+// AttachmentNote создает примечание с файлом.
+func AttachmentNote(params AttachmentParams) *Note {
+	return newNote(NoteTypeAttachment, params)
+}
+
+// For привязывает примечание к сущности и возвращает его.
+func (n *Note) For(entityType string, entityID int) *Note {
+	n.EntityType = entityType
+	n.EntityID = entityID
+	return n
+}
+
+// DecodeParams декодирует параметры примечания в v.
+func (n *Note) DecodeParams(v interface{}) error {
+	if len(n.Params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(n.Params, v); err != nil {
+		return fmt.Errorf("ошибка разбора параметров примечания %d типа %s: %v", n.ID, n.NoteType, err)
+	}
+	return nil
+}
+
+// decodeParams проверяет тип примечания и декодирует его параметры
+func (n *Note) decodeParams(v interface{}, types ...NoteType) error {
+	for _, noteType := range types {
+		if n.NoteType == noteType {
+			return n.DecodeParams(v)
+		}
+	}
+	return fmt.Errorf("примечание %d имеет тип %s, ожидался %v", n.ID, n.NoteType, types)
+}
+
+// CommonParams возвращает параметры обычного примечания.
+func (n *Note) CommonParams() (*CommonParams, error) {
+	var params CommonParams
+	if err := n.decodeParams(&params, NoteTypeCommon); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// CallParams возвращает параметры примечания о звонке.
+func (n *Note) CallParams() (*CallParams, error) {
+	var params CallParams
+	if err := n.decodeParams(&params, NoteTypeCallIn, NoteTypeCallOut); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// ServiceMessageParams возвращает параметры системного примечания.
+func (n *Note) ServiceMessageParams() (*ServiceMessageParams, error) {
+	var params ServiceMessageParams
+	if err := n.decodeParams(&params, NoteTypeServiceMessage, NoteTypeExtendedServiceMessage); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// SMSParams возвращает параметры примечания о SMS.
+func (n *Note) SMSParams() (*SMSParams, error) {
+	var params SMSParams
+	if err := n.decodeParams(&params, NoteTypeSMSIn, NoteTypeSMSOut); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// GeolocationParams возвращает параметры примечания с геолокацией.
+func (n *Note) GeolocationParams() (*GeolocationParams, error) {
+	var params GeolocationParams
+	if err := n.decodeParams(&params, NoteTypeGeolocation); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// AttachmentParams возвращает параметры примечания с файлом.
+func (n *Note) AttachmentParams() (*AttachmentParams, error) {
+	var params AttachmentParams
+	if err := n.decodeParams(&params, NoteTypeAttachment); err != nil {
+		return nil, err
+	}
+	return &params, nil
+}
+
+// Text возвращает текст примечания любого типа, если он есть в параметрах.
+func (n *Note) Text() string {
+	var params struct {
+		Text string `json:"text"`
+	}
+	if err := n.DecodeParams(&params); err != nil {
+		return ""
+	}
+	return params.Text
+}
@@ -0,0 +1,105 @@
+package notes
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// amoCRMNotesJSON - примечания разных типов в формате ответа amoCRM
+const amoCRMNotesJSON = `[
+	{"id": 1, "entity_id": 10, "note_type": "common", "created_by": 5, "created_at": 1588231683, "updated_at": 1588231690,
+		"params": {"text": "Клиент просил перезвонить"}},
+	{"id": 2, "entity_id": 10, "note_type": "call_in", "created_at": 1588231700,
+		"params": {"uniq": "8f52d38a-5fb3-406d-93a3-a4832dc28f8b", "duration": 60, "source": "onlinePBX",
+			"link": "https://example.com/call.mp3", "phone": "+79001234567", "call_status": 4}},
+	{"id": 3, "entity_id": 10, "note_type": "service_message", "created_at": 1588231710,
+		"params": {"service": "Интеграция", "text": "Счет оплачен"}},
+	{"id": 4, "entity_id": 10, "note_type": "sms_in", "created_at": 1588231720,
+		"params": {"text": "Да, удобно", "phone": "+79001234567"}},
+	{"id": 5, "entity_id": 10, "note_type": "geolocation", "created_at": 1588231730,
+		"params": {"text": "Встреча", "address": "Москва", "longitude": "37.6173", "latitude": "55.7558"}},
+	{"id": 6, "entity_id": 10, "note_type": "attachment", "created_at": 1588231740,
+		"params": {"file_uuid": "c2a1e4b6", "version_uuid": "d3b2f5c7", "file_name": "contract.pdf", "original_name": "Договор.pdf"}}
+]`
+
+func TestDecodeNoteKinds(t *testing.T) {
+	var list []Note
+	if err := json.Unmarshal([]byte(amoCRMNotesJSON), &list); err != nil {
+		t.Fatalf("Ошибка при разборе примечаний: %v", err)
+	}
+
+	if list[0].CreatedAt != 1588231683 || list[0].UpdatedAt != 1588231690 {
+		t.Errorf("Неверные даты примечания: %d, %d", list[0].CreatedAt, list[0].UpdatedAt)
+	}
+	if list[0].Text() != "Клиент просил перезвонить" {
+		t.Errorf("Неверный текст примечания: %q", list[0].Text())
+	}
+
+	call, err := list[1].CallParams()
+	if err != nil {
+		t.Fatalf("Ошибка при чтении параметров звонка: %v", err)
+	}
+	if call.UniqueID != "8f52d38a-5fb3-406d-93a3-a4832dc28f8b" || call.Duration != 60 || call.Source != "onlinePBX" ||
+		call.Link != "https://example.com/call.mp3" || call.Phone != "+79001234567" || call.CallStatus != 4 {
+		t.Errorf("Неверные параметры звонка: %+v", call)
+	}
+
+	if service, err := list[2].ServiceMessageParams(); err != nil || service.Service != "Интеграция" {
+		t.Errorf("Неверные параметры системного примечания: %+v, %v", service, err)
+	}
+	if sms, err := list[3].SMSParams(); err != nil || sms.Phone != "+79001234567" {
+		t.Errorf("Неверные параметры SMS: %+v, %v", sms, err)
+	}
+	if geo, err := list[4].GeolocationParams(); err != nil || geo.Latitude != "55.7558" {
+		t.Errorf("Неверные параметры геолокации: %+v, %v", geo, err)
+	}
+	if file, err := list[5].AttachmentParams(); err != nil || file.FileName != "contract.pdf" {
+		t.Errorf("Неверные параметры файла: %+v, %v", file, err)
+	}
+
+	// Параметры другого типа не читаются
+	if _, err := list[0].CallParams(); err == nil {
+		t.Error("Ожидалась ошибка при чтении параметров звонка у обычного примечания")
+	}
+}
+
+func TestNoteConstructors(t *testing.T) {
+	tests := []struct {
+		name     string
+		note     *Note
+		expected string
+	}{
+		{
+			name:     "Обычное",
+			note:     CommonNote("Текст").For(EntityTypeLead, 10),
+			expected: `{"entity_id":10,"entity_type":"leads","note_type":"common","params":{"text":"Текст"}}`,
+		},
+		{
+			name:     "Исходящий звонок",
+			note:     CallOutNote(CallParams{UniqueID: "abc", Duration: 30, Source: "pbx", Phone: "+79001234567"}),
+			expected: `{"note_type":"call_out","params":{"uniq":"abc","duration":30,"source":"pbx","phone":"+79001234567"}}`,
+		},
+		{
+			name:     "Системное",
+			note:     ExtendedServiceMessageNote("Интеграция", "Текст"),
+			expected: `{"note_type":"extended_service_message","params":{"service":"Интеграция","text":"Текст"}}`,
+		},
+		{
+			name:     "Исходящее SMS",
+			note:     SMSOutNote("+79001234567", "Текст"),
+			expected: `{"note_type":"sms_out","params":{"text":"Текст","phone":"+79001234567"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.note)
+			if err != nil {
+				t.Fatalf("Ошибка при сериализации: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Ожидалось %s, получено %s", tt.expected, data)
+			}
+		})
+	}
+}
@@ -5,31 +5,37 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"github.com/chudno/amo_crm_sdk/client"
 	"net/http"
-	"time"
+	"net/url"
+	"strconv"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// Типы сущностей, к которым привязываются примечания
+const (
+	EntityTypeLead     = "leads"
+	EntityTypeContact  = "contacts"
+	EntityTypeCompany  = "companies"
+	EntityTypeCustomer = "customers"
 )
 
 // Note представляет собой структуру примечания в amoCRM.
+// Содержимое примечания зависит от типа и хранится в Params. Для создания примечаний
+// используйте конструкторы CommonNote, CallInNote и другие, для чтения - методы CommonParams, CallParams и другие.
 type Note struct {
-	ID         int        `json:"id"`
-	EntityID   int        `json:"entity_id"`
-	EntityType string     `json:"entity_type"` // leads, contacts, companies, customers
-	NoteType   int        `json:"note_type"`
-	Text       string     `json:"text,omitempty"`
-	CreatedBy  int        `json:"created_by"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
-	Params     NoteParams `json:"params,omitempty"`
-}
-
-// NoteParams содержит дополнительные параметры примечания в зависимости от типа
-type NoteParams struct {
-	Text        string `json:"text,omitempty"`
-	Service     string `json:"service,omitempty"`
-	PhoneNumber string `json:"phone_number,omitempty"`
-	Email       string `json:"email,omitempty"`
-	Link        string `json:"link,omitempty"`
+	ID                int             `json:"id,omitempty"`
+	EntityID          int             `json:"entity_id,omitempty"`
+	EntityType        string          `json:"entity_type,omitempty"` // leads, contacts, companies, customers
+	NoteType          NoteType        `json:"note_type"`
+	CreatedBy         int             `json:"created_by,omitempty"`
+	UpdatedBy         int             `json:"updated_by,omitempty"`
+	CreatedAt         int64           `json:"created_at,omitempty"`
+	UpdatedAt         int64           `json:"updated_at,omitempty"`
+	ResponsibleUserID int             `json:"responsible_user_id,omitempty"`
+	GroupID           int             `json:"group_id,omitempty"`
+	AccountID         int             `json:"account_id,omitempty"`
+	Params            json.RawMessage `json:"params,omitempty"`
 }
 
 // GetNote получает примечание по его ID.
@@ -120,14 +126,104 @@ func ListNotes(apiClient *client.Client, entityType string, entityID int, limit
 		return nil, err
 	}
 
+	return getNotes(apiClient, req)
+}
+
+// Поля сортировки списка примечаний
+const (
+	OrderByUpdatedAt = "updated_at"
+	OrderByID        = "id"
+)
+
+// NotesFilter задает параметры фильтрации и сортировки списка примечаний.
+type NotesFilter struct {
+	// IDs - фильтр по ID примечаний
+	IDs []int
+	// EntityIDs - фильтр по ID сущностей
+	EntityIDs []int
+	// NoteTypes - фильтр по типам примечаний
+	NoteTypes []NoteType
+	// UpdatedAtFrom, UpdatedAtTo - диапазон даты изменения (Unix timestamp)
+	UpdatedAtFrom int64
+	UpdatedAtTo   int64
+	// OrderBy - поле сортировки: OrderByUpdatedAt или OrderByID
+	OrderBy string
+	// OrderDesc - сортировка по убыванию
+	OrderDesc bool
+}
+
+// Values преобразует фильтр в параметры запроса API amoCRM.
+func (f *NotesFilter) Values() url.Values {
+	params := url.Values{}
+	if f == nil {
+		return params
+	}
+
+	for _, id := range f.IDs {
+		params.Add("filter[id][]", strconv.Itoa(id))
+	}
+	for _, entityID := range f.EntityIDs {
+		params.Add("filter[entity_id][]", strconv.Itoa(entityID))
+	}
+	for _, noteType := range f.NoteTypes {
+		params.Add("filter[note_type][]", string(noteType))
+	}
+	if f.UpdatedAtFrom > 0 {
+		params.Set("filter[updated_at][from]", strconv.FormatInt(f.UpdatedAtFrom, 10))
+	}
+	if f.UpdatedAtTo > 0 {
+		params.Set("filter[updated_at][to]", strconv.FormatInt(f.UpdatedAtTo, 10))
+	}
+
+	if f.OrderBy != "" {
+		order := "asc"
+		if f.OrderDesc {
+			order = "desc"
+		}
+		params.Set("order["+f.OrderBy+"]", order)
+	}
+
+	return params
+}
+
+// FindNotes получает примечания всех сущностей указанного типа, подходящие под фильтр.
+// Если примечания не найдены, возвращается пустой список без ошибки.
+func FindNotes(apiClient *client.Client, entityType string, page, limit int, filter *NotesFilter) ([]Note, error) {
+	params := filter.Values()
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	url := fmt.Sprintf("%s/api/v4/%s/notes?%s", apiClient.GetBaseURL(), entityType, params.Encode())
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return getNotes(apiClient, req)
+}
+
+// getNotes выполняет запрос списка примечаний
+func getNotes(apiClient *client.Client, req *http.Request) ([]Note, error) {
 	resp, err := apiClient.DoRequest(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	// amoCRM возвращает 204, если примечаний нет
+	if resp.StatusCode == http.StatusNoContent {
+		return []Note{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	// amoCRM возвращает список в _embedded.notes, items поддерживается для совместимости
 	var notes struct {
 		Embedded struct {
+			Notes []Note `json:"notes"`
 			Items []Note `json:"items"`
 		} `json:"_embedded"`
 	}
@@ -136,9 +232,86 @@ func ListNotes(apiClient *client.Client, entityType string, entityID int, limit
 		return nil, err
 	}
 
+	if len(notes.Embedded.Notes) > 0 {
+		return notes.Embedded.Notes, nil
+	}
 	return notes.Embedded.Items, nil
 }
 
+// MaxBatchSize - максимальное количество примечаний в одном запросе CreateNotes
+const MaxBatchSize = 50
+
+// CreateNotes создает примечания для нескольких сущностей одного типа.
+// У каждого примечания должен быть указан EntityID. Примечания отправляются запросами по MaxBatchSize,
+// при ошибке возвращаются примечания, созданные предыдущими запросами.
+func CreateNotes(apiClient *client.Client, entityType string, notes []*Note) ([]Note, error) {
+	payload := make([]Note, len(notes))
+	for i, note := range notes {
+		if note.EntityID == 0 {
+			return nil, fmt.Errorf("примечание #%d: не указан ID сущности", i)
+		}
+		if note.EntityType != "" && note.EntityType != entityType {
+			return nil, fmt.Errorf("примечание #%d: тип сущности %s не совпадает с %s", i, note.EntityType, entityType)
+		}
+		payload[i] = *note
+		payload[i].EntityType = ""
+	}
+
+	var created []Note
+	for start := 0; start < len(payload); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		chunk, err := createNotes(apiClient, entityType, payload[start:end])
+		if err != nil {
+			return created, fmt.Errorf("ошибка при создании примечаний %d-%d: %v", start, end-1, err)
+		}
+		created = append(created, chunk...)
+	}
+
+	return created, nil
+}
+
+// createNotes выполняет один запрос пакетного создания примечаний
+func createNotes(apiClient *client.Client, entityType string, notes []Note) ([]Note, error) {
+	url := fmt.Sprintf("%s/api/v4/%s/notes", apiClient.GetBaseURL(), entityType)
+	notesJSON, err := json.Marshal(notes)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(notesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Notes []Note `json:"notes"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Notes, nil
+}
+
 // DeleteNote удаляет примечание по его ID.
 func DeleteNote(apiClient *client.Client, entityType string, entityID int, noteID int) error {
 	url := fmt.Sprintf("%s/api/v4/%s/%d/notes/%d", apiClient.GetBaseURL(), entityType, entityID, noteID)
@@ -2,10 +2,10 @@ package notes
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 
 	"github.com/chudno/amo_crm_sdk/client"
 )
@@ -31,11 +31,11 @@ func TestGetNote(t *testing.T) {
 			"id": 456,
 			"entity_id": 123,
 			"entity_type": "leads",
-			"note_type": 4,
-			"text": "Тестовое примечание",
+			"note_type": "common",
+			"params": {"text": "Тестовое примечание"},
 			"created_by": 789,
-			"created_at": "2023-01-01T10:00:00Z",
-			"updated_at": "2023-01-01T11:00:00Z"
+			"created_at": 1672567200,
+			"updated_at": 1672570800
 		}`))
 	}))
 	defer server.Close()
@@ -63,8 +63,8 @@ func TestGetNote(t *testing.T) {
 		t.Errorf("Ожидался тип сущности 'leads', получен '%s'", note.EntityType)
 	}
 
-	if note.Text != "Тестовое примечание" {
-		t.Errorf("Ожидался текст примечания 'Тестовое примечание', получен '%s'", note.Text)
+	if note.Text() != "Тестовое примечание" {
+		t.Errorf("Ожидался текст примечания 'Тестовое примечание', получен '%s'", note.Text())
 	}
 }
 
@@ -89,8 +89,8 @@ func TestCreateNote(t *testing.T) {
 		}
 
 		// Проверяем содержимое запроса
-		if requestNote.Text != "Новое примечание" {
-			t.Errorf("Ожидался текст примечания 'Новое примечание', получен '%s'", requestNote.Text)
+		if requestNote.Text() != "Новое примечание" {
+			t.Errorf("Ожидался текст примечания 'Новое примечание', получен '%s'", requestNote.Text())
 		}
 
 		// Отправляем ответ
@@ -100,11 +100,11 @@ func TestCreateNote(t *testing.T) {
 			"id": 123,
 			"entity_id": 789,
 			"entity_type": "contacts",
-			"note_type": 4,
-			"text": "Новое примечание",
+			"note_type": "common",
+			"params": {"text": "Новое примечание"},
 			"created_by": 456,
-			"created_at": "2023-01-01T10:00:00Z",
-			"updated_at": "2023-01-01T10:00:00Z"
+			"created_at": 1672567200,
+			"updated_at": 1672567200
 		}`))
 	}))
 	defer server.Close()
@@ -113,17 +113,7 @@ func TestCreateNote(t *testing.T) {
 	apiClient := client.NewClient(server.URL, "test_api_key")
 
 	// Создаем примечание для теста
-	createdAt, _ := time.Parse(time.RFC3339, "2023-01-01T10:00:00Z")
-	updatedAt, _ := time.Parse(time.RFC3339, "2023-01-01T10:00:00Z")
-	noteToCreate := &Note{
-		EntityID:   789,
-		EntityType: "contacts",
-		NoteType:   4,
-		Text:       "Новое примечание",
-		CreatedBy:  456,
-		CreatedAt:  createdAt,
-		UpdatedAt:  updatedAt,
-	}
+	noteToCreate := CommonNote("Новое примечание").For(EntityTypeContact, 789)
 
 	// Вызываем тестируемый метод
 	createdNote, err := CreateNote(apiClient, "contacts", 789, noteToCreate)
@@ -137,8 +127,8 @@ func TestCreateNote(t *testing.T) {
 		t.Errorf("Ожидался ID примечания 123, получен %d", createdNote.ID)
 	}
 
-	if createdNote.Text != "Новое примечание" {
-		t.Errorf("Ожидался текст примечания 'Новое примечание', получен '%s'", createdNote.Text)
+	if createdNote.Text() != "Новое примечание" {
+		t.Errorf("Ожидался текст примечания 'Новое примечание', получен '%s'", createdNote.Text())
 	}
 }
 
@@ -163,8 +153,8 @@ func TestUpdateNote(t *testing.T) {
 		}
 
 		// Проверяем содержимое запроса
-		if requestNote.Text != "Обновленное примечание" {
-			t.Errorf("Ожидался текст примечания 'Обновленное примечание', получен '%s'", requestNote.Text)
+		if requestNote.Text() != "Обновленное примечание" {
+			t.Errorf("Ожидался текст примечания 'Обновленное примечание', получен '%s'", requestNote.Text())
 		}
 
 		// Отправляем ответ
@@ -174,11 +164,11 @@ func TestUpdateNote(t *testing.T) {
 			"id": 123,
 			"entity_id": 456,
 			"entity_type": "leads",
-			"note_type": 4,
-			"text": "Обновленное примечание",
+			"note_type": "common",
+			"params": {"text": "Обновленное примечание"},
 			"created_by": 789,
-			"created_at": "2023-01-01T10:00:00Z",
-			"updated_at": "2023-01-01T12:00:00Z"
+			"created_at": 1672567200,
+			"updated_at": 1672574400
 		}`))
 	}))
 	defer server.Close()
@@ -187,18 +177,8 @@ func TestUpdateNote(t *testing.T) {
 	apiClient := client.NewClient(server.URL, "test_api_key")
 
 	// Создаем примечание для обновления
-	createdAt, _ := time.Parse(time.RFC3339, "2023-01-01T10:00:00Z")
-	updatedAt, _ := time.Parse(time.RFC3339, "2023-01-01T12:00:00Z")
-	noteToUpdate := &Note{
-		ID:         123,
-		EntityID:   456,
-		EntityType: "leads",
-		NoteType:   4,
-		Text:       "Обновленное примечание",
-		CreatedBy:  789,
-		CreatedAt:  createdAt,
-		UpdatedAt:  updatedAt,
-	}
+	noteToUpdate := CommonNote("Обновленное примечание").For(EntityTypeLead, 456)
+	noteToUpdate.ID = 123
 
 	// Вызываем тестируемый метод
 	updatedNote, err := UpdateNote(apiClient, "leads", 456, noteToUpdate)
@@ -212,8 +192,8 @@ func TestUpdateNote(t *testing.T) {
 		t.Errorf("Ожидался ID примечания 123, получен %d", updatedNote.ID)
 	}
 
-	if updatedNote.Text != "Обновленное примечание" {
-		t.Errorf("Ожидался текст примечания 'Обновленное примечание', получен '%s'", updatedNote.Text)
+	if updatedNote.Text() != "Обновленное примечание" {
+		t.Errorf("Ожидался текст примечания 'Обновленное примечание', получен '%s'", updatedNote.Text())
 	}
 }
 
@@ -250,21 +230,21 @@ func TestListNotes(t *testing.T) {
 						"id": 456,
 						"entity_id": 123,
 						"entity_type": "companies",
-						"note_type": 4,
-						"text": "Примечание 1",
+						"note_type": "common",
+						"params": {"text": "Примечание 1"},
 						"created_by": 789,
-						"created_at": "2023-01-01T10:00:00Z",
-						"updated_at": "2023-01-01T11:00:00Z"
+						"created_at": 1672567200,
+						"updated_at": 1672570800
 					},
 					{
 						"id": 789,
 						"entity_id": 123,
 						"entity_type": "companies",
-						"note_type": 4,
-						"text": "Примечание 2",
+						"note_type": "common",
+						"params": {"text": "Примечание 2"},
 						"created_by": 789,
-						"created_at": "2023-01-02T10:00:00Z",
-						"updated_at": "2023-01-02T11:00:00Z"
+						"created_at": 1672653600,
+						"updated_at": 1672657200
 					}
 				]
 			}
@@ -347,3 +327,100 @@ func TestDeleteNoteError(t *testing.T) {
 		t.Fatal("Ожидалась ошибка при удалении несуществующего примечания, но её не было")
 	}
 }
+
+func TestFindNotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/leads/notes" {
+			t.Errorf("Ожидался путь /api/v4/leads/notes, получен %s", r.URL.Path)
+		}
+
+		query := r.URL.Query()
+		if fmt.Sprint(query["filter[note_type][]"]) != "[call_in call_out]" ||
+			fmt.Sprint(query["filter[entity_id][]"]) != "[1 2]" ||
+			query.Get("filter[updated_at][from]") != "1700000000" ||
+			query.Get("order[updated_at]") != "asc" ||
+			query.Get("page") != "3" {
+			t.Errorf("Неверные параметры запроса: %s", r.URL.RawQuery)
+		}
+
+		if query.Get("page") == "3" {
+			_, _ = w.Write([]byte(`{"_embedded":{"notes":[{"id":1,"entity_id":1,"note_type":"call_in","params":{"phone":"+79001234567"}}]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	list, err := FindNotes(apiClient, EntityTypeLead, 3, 250, &NotesFilter{
+		NoteTypes:     []NoteType{NoteTypeCallIn, NoteTypeCallOut},
+		EntityIDs:     []int{1, 2},
+		UpdatedAtFrom: 1700000000,
+		OrderBy:       OrderByUpdatedAt,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при получении примечаний: %v", err)
+	}
+	if len(list) != 1 || list[0].NoteType != NoteTypeCallIn {
+		t.Errorf("Неверный список примечаний: %+v", list)
+	}
+}
+
+func TestCreateNotes(t *testing.T) {
+	var chunkSizes []int
+	nextID := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v4/contacts/notes" {
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+
+		var request []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка при чтении тела запроса: %v", err)
+		}
+		chunkSizes = append(chunkSizes, len(request))
+		if request[0]["entity_id"] == nil || request[0]["entity_type"] != nil {
+			t.Errorf("Неверное примечание в запросе: %v", request[0])
+		}
+
+		var response struct {
+			Embedded struct {
+				Notes []Note `json:"notes"`
+			} `json:"_embedded"`
+		}
+		for _, item := range request {
+			nextID++
+			response.Embedded.Notes = append(response.Embedded.Notes, Note{ID: nextID, EntityID: int(item["entity_id"].(float64))})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	var batch []*Note
+	for i := 1; i <= 60; i++ {
+		batch = append(batch, CallInNote(CallParams{UniqueID: fmt.Sprint(i), Phone: "+79001234567"}).For(EntityTypeContact, i))
+	}
+
+	created, err := CreateNotes(apiClient, EntityTypeContact, batch)
+	if err != nil {
+		t.Fatalf("Ошибка при создании примечаний: %v", err)
+	}
+	if len(created) != 60 || created[59].EntityID != 60 {
+		t.Errorf("Ожидалось 60 созданных примечаний, получено %d", len(created))
+	}
+	if fmt.Sprint(chunkSizes) != "[50 10]" {
+		t.Errorf("Ожидались запросы по 50 и 10 примечаний, получено %v", chunkSizes)
+	}
+
+	// Примечание без сущности или для другой сущности не отправляется
+	if _, err := CreateNotes(apiClient, EntityTypeContact, []*Note{CommonNote("Текст")}); err == nil {
+		t.Error("Ожидалась ошибка для примечания без ID сущности")
+	}
+	if _, err := CreateNotes(apiClient, EntityTypeContact, []*Note{CommonNote("Текст").For(EntityTypeLead, 1)}); err == nil {
+		t.Error("Ожидалась ошибка для примечания другой сущности")
+	}
+}
@@ -11,23 +11,48 @@ import (
 
 // Pipeline представляет собой структуру воронки в amoCRM.
 type Pipeline struct {
-	ID       int      `json:"id"`
-	Name     string   `json:"name"`
-	Sort     int      `json:"sort"`
-	IsMain   bool     `json:"is_main"`
-	IsActive bool     `json:"is_active"`
-	Statuses []Status `json:"statuses,omitempty"`
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Sort         int      `json:"sort"`
+	IsMain       bool     `json:"is_main"`
+	IsActive     bool     `json:"is_active"`
+	IsUnsortedOn bool     `json:"is_unsorted_on,omitempty"`
+	IsArchive    bool     `json:"is_archive,omitempty"`
+	Statuses     []Status `json:"statuses,omitempty"`
+}
+
+// UnmarshalJSON читает воронку вместе с этапами.
+// amoCRM возвращает этапы в _embedded.statuses, поле statuses поддерживается для совместимости.
+func (p *Pipeline) UnmarshalJSON(data []byte) error {
+	type plainPipeline Pipeline
+	var aux struct {
+		plainPipeline
+		Embedded struct {
+			Statuses []Status `json:"statuses"`
+		} `json:"_embedded"`
+	}
+	aux.plainPipeline = plainPipeline(*p)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	*p = Pipeline(aux.plainPipeline)
+	if len(aux.Embedded.Statuses) > 0 {
+		p.Statuses = aux.Embedded.Statuses
+	}
+	return nil
 }
 
 // Status представляет собой структуру статуса в воронке amoCRM.
 type Status struct {
-	ID         int    `json:"id"`
-	Name       string `json:"name"`
-	Sort       int    `json:"sort"`
-	Color      string `json:"color"`
-	Type       int    `json:"type"`
-	PipelineID int    `json:"pipeline_id"`
-	IsEditable bool   `json:"is_editable"`
+	ID           int                 `json:"id,omitempty"`
+	Name         string              `json:"name"`
+	Sort         int                 `json:"sort"`
+	Color        string              `json:"color"`
+	Type         int                 `json:"type"`
+	PipelineID   int                 `json:"pipeline_id,omitempty"`
+	IsEditable   bool                `json:"is_editable"`
+	Descriptions []StatusDescription `json:"descriptions,omitempty"`
 }
 
 // GetPipeline получает воронку по её ID.
@@ -81,10 +106,88 @@ func CreatePipeline(apiClient *client.Client, pipeline *Pipeline) (*Pipeline, er
 	return &newPipeline, nil
 }
 
+// CreatePipelines создает несколько воронок вместе с этапами одним запросом.
+// Этапы передаются в Statuses каждой воронки, этапы "Успешно реализовано" и "Закрыто и не реализовано"
+// amoCRM добавляет сама.
+func CreatePipelines(apiClient *client.Client, pipelines []Pipeline) ([]Pipeline, error) {
+	url := fmt.Sprintf("%s/api/v4/leads/pipelines", apiClient.GetBaseURL())
+
+	// Этапы новой воронки передаются в _embedded.statuses
+	type pipelineRequest struct {
+		Name         string `json:"name"`
+		Sort         int    `json:"sort"`
+		IsMain       bool   `json:"is_main,omitempty"`
+		IsUnsortedOn bool   `json:"is_unsorted_on,omitempty"`
+		Embedded     struct {
+			Statuses []Status `json:"statuses,omitempty"`
+		} `json:"_embedded"`
+	}
+
+	requests := make([]pipelineRequest, len(pipelines))
+	for i, pipeline := range pipelines {
+		requests[i].Name = pipeline.Name
+		requests[i].Sort = pipeline.Sort
+		requests[i].IsMain = pipeline.IsMain
+		requests[i].IsUnsortedOn = pipeline.IsUnsortedOn
+		requests[i].Embedded.Statuses = pipeline.Statuses
+	}
+
+	pipelinesJSON, err := json.Marshal(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(pipelinesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Pipelines []Pipeline `json:"pipelines"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Pipelines, nil
+}
+
 // UpdatePipeline обновляет существующую воронку в amoCRM.
+// Передаются только заполненные изменяемые поля воронки. Снять признак главной воронки нельзя,
+// можно только назначить главной другую воронку.
 func UpdatePipeline(apiClient *client.Client, pipeline *Pipeline) (*Pipeline, error) {
 	url := fmt.Sprintf("%s/api/v4/leads/pipelines/%d", apiClient.GetBaseURL(), pipeline.ID)
-	pipelineJSON, err := json.Marshal(pipeline)
+
+	data := map[string]interface{}{}
+	if pipeline.Name != "" {
+		data["name"] = pipeline.Name
+	}
+	if pipeline.Sort != 0 {
+		data["sort"] = pipeline.Sort
+	}
+	if pipeline.IsMain {
+		data["is_main"] = true
+	}
+	if pipeline.IsUnsortedOn {
+		data["is_unsorted_on"] = true
+	}
+
+	pipelineJSON, err := json.Marshal(data)
 	if err != nil {
 		return nil, err
 	}
@@ -124,9 +227,11 @@ func ListPipelines(apiClient *client.Client) ([]Pipeline, error) {
 	}
 	defer resp.Body.Close()
 
+	// amoCRM возвращает список в _embedded.pipelines, items поддерживается для совместимости
 	var pipelines struct {
 		Embedded struct {
-			Items []Pipeline `json:"items"`
+			Pipelines []Pipeline `json:"pipelines"`
+			Items     []Pipeline `json:"items"`
 		} `json:"_embedded"`
 	}
 
@@ -134,6 +239,9 @@ func ListPipelines(apiClient *client.Client) ([]Pipeline, error) {
 		return nil, err
 	}
 
+	if len(pipelines.Embedded.Pipelines) > 0 {
+		return pipelines.Embedded.Pipelines, nil
+	}
 	return pipelines.Embedded.Items, nil
 }
 
@@ -0,0 +1,216 @@
+package pipelines
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// Системные этапы, которые есть в каждой воронке
+const (
+	// StatusWon - этап "Успешно реализовано"
+	StatusWon = 142
+	// StatusLost - этап "Закрыто и не реализовано"
+	StatusLost = 143
+)
+
+// Типы этапов
+const (
+	// StatusTypeRegular - обычный этап
+	StatusTypeRegular = 0
+	// StatusTypeUnsorted - этап "Неразобранное"
+	StatusTypeUnsorted = 1
+)
+
+// Уровни сотрудников для описаний этапов
+const (
+	DescriptionLevelNewbie    = "newbie"
+	DescriptionLevelCandidate = "candidate"
+	DescriptionLevelMaster    = "master"
+)
+
+// StatusDescription представляет описание этапа для сотрудников определенного уровня.
+type StatusDescription struct {
+	ID          int    `json:"id,omitempty"`
+	AccountID   int    `json:"account_id,omitempty"`
+	Level       string `json:"level"`
+	Description string `json:"description"`
+}
+
+// IsWon проверяет, является ли этап этапом "Успешно реализовано".
+func (s Status) IsWon() bool {
+	return s.ID == StatusWon
+}
+
+// IsLost проверяет, является ли этап этапом "Закрыто и не реализовано".
+func (s Status) IsLost() bool {
+	return s.ID == StatusLost
+}
+
+// IsClosed проверяет, является ли этап одним из завершающих этапов.
+func (s Status) IsClosed() bool {
+	return s.IsWon() || s.IsLost()
+}
+
+// IsUnsorted проверяет, является ли этап этапом "Неразобранное".
+func (s Status) IsUnsorted() bool {
+	return s.Type == StatusTypeUnsorted
+}
+
+// ListStatuses получает список этапов воронки.
+func ListStatuses(apiClient *client.Client, pipelineID int) ([]Status, error) {
+	url := fmt.Sprintf("%s/api/v4/leads/pipelines/%d/statuses", apiClient.GetBaseURL(), pipelineID)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Statuses []Status `json:"statuses"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Statuses, nil
+}
+
+// CreateStatuses создает несколько этапов в воронке одним запросом.
+func CreateStatuses(apiClient *client.Client, pipelineID int, statuses []Status) ([]Status, error) {
+	url := fmt.Sprintf("%s/api/v4/leads/pipelines/%d/statuses", apiClient.GetBaseURL(), pipelineID)
+	statusesJSON, err := json.Marshal(statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(statusesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Statuses []Status `json:"statuses"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Statuses, nil
+}
+
+// UpdateStatus обновляет название, сортировку, цвет и описания этапа воронки.
+// Передаются только заполненные поля, пустые поля этапа не изменяются.
+// Системные этапы 142 и 143 изменить нельзя.
+func UpdateStatus(apiClient *client.Client, pipelineID int, status *Status) (*Status, error) {
+	if status.ID == 0 {
+		return nil, fmt.Errorf("не указан ID этапа")
+	}
+	if status.IsClosed() {
+		return nil, fmt.Errorf("системный этап %d нельзя изменить", status.ID)
+	}
+
+	url := fmt.Sprintf("%s/api/v4/leads/pipelines/%d/statuses/%d", apiClient.GetBaseURL(), pipelineID, status.ID)
+
+	// Передаются только заполненные изменяемые поля этапа
+	data := map[string]interface{}{}
+	if status.Name != "" {
+		data["name"] = status.Name
+	}
+	if status.Sort != 0 {
+		data["sort"] = status.Sort
+	}
+	if status.Color != "" {
+		data["color"] = status.Color
+	}
+	if status.Descriptions != nil {
+		data["descriptions"] = status.Descriptions
+	}
+
+	statusJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(statusJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var updatedStatus Status
+	if err := json.NewDecoder(resp.Body).Decode(&updatedStatus); err != nil {
+		return nil, err
+	}
+
+	return &updatedStatus, nil
+}
+
+// DeleteStatus удаляет этап воронки.
+// Сделки удаляемого этапа amoCRM переносит в первый этап воронки, системные этапы удалить нельзя.
+func DeleteStatus(apiClient *client.Client, pipelineID int, statusID int) error {
+	if statusID == StatusWon || statusID == StatusLost {
+		return fmt.Errorf("системный этап %d нельзя удалить", statusID)
+	}
+
+	url := fmt.Sprintf("%s/api/v4/leads/pipelines/%d/statuses/%d", apiClient.GetBaseURL(), pipelineID, statusID)
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package pipelines
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestStatusHelpers(t *testing.T) {
+	won := Status{ID: StatusWon}
+	lost := Status{ID: StatusLost}
+	unsorted := Status{ID: 100, Type: StatusTypeUnsorted}
+	regular := Status{ID: 101}
+
+	if !won.IsWon() || won.IsLost() || !won.IsClosed() {
+		t.Error("Неверная проверка этапа 142")
+	}
+	if !lost.IsLost() || lost.IsWon() || !lost.IsClosed() {
+		t.Error("Неверная проверка этапа 143")
+	}
+	if !unsorted.IsUnsorted() || regular.IsUnsorted() || regular.IsClosed() {
+		t.Error("Неверная проверка типа этапа")
+	}
+}
+
+func TestStatusesAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v4/leads/pipelines/10/statuses":
+			_, _ = w.Write([]byte(`{"_embedded":{"statuses":[
+				{"id":100,"name":"Неразобранное","type":1,"pipeline_id":10},
+				{"id":101,"name":"Первичный контакт","type":0,"pipeline_id":10},
+				{"id":142,"name":"Успешно реализовано","type":0,"pipeline_id":10}]}}`))
+		case "POST /api/v4/leads/pipelines/10/statuses":
+			if !strings.HasPrefix(string(body), "[") || strings.Contains(string(body), `"id"`) {
+				t.Errorf("Ожидался массив новых этапов, получено %s", body)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"statuses":[{"id":102,"name":"Переговоры","pipeline_id":10}]}}`))
+		case "PATCH /api/v4/leads/pipelines/10/statuses/101":
+			var data map[string]interface{}
+			_ = json.Unmarshal(body, &data)
+			if data["name"] != "Первый контакт" || data["descriptions"] == nil || data["pipeline_id"] != nil ||
+				data["sort"] != nil || data["color"] != nil {
+				t.Errorf("Неверное тело запроса обновления: %s", body)
+			}
+			_, _ = w.Write([]byte(`{"id":101,"name":"Первый контакт","pipeline_id":10}`))
+		case "DELETE /api/v4/leads/pipelines/10/statuses/101":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	statuses, err := ListStatuses(apiClient, 10)
+	if err != nil {
+		t.Fatalf("Ошибка при получении этапов: %v", err)
+	}
+	if len(statuses) != 3 || !statuses[0].IsUnsorted() || !statuses[2].IsWon() {
+		t.Errorf("Неверный список этапов: %+v", statuses)
+	}
+
+	created, err := CreateStatuses(apiClient, 10, []Status{{Name: "Переговоры", Sort: 20, Color: "#ffcc66"}})
+	if err != nil {
+		t.Fatalf("Ошибка при создании этапов: %v", err)
+	}
+	if len(created) != 1 || created[0].ID != 102 {
+		t.Errorf("Неверный результат создания этапов: %+v", created)
+	}
+
+	updated, err := UpdateStatus(apiClient, 10, &Status{
+		ID:           101,
+		Name:         "Первый контакт",
+		PipelineID:   10,
+		Descriptions: []StatusDescription{{Level: DescriptionLevelNewbie, Description: "Позвонить клиенту"}},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при обновлении этапа: %v", err)
+	}
+	if updated.Name != "Первый контакт" {
+		t.Errorf("Ожидалось название 'Первый контакт', получено '%s'", updated.Name)
+	}
+
+	if err := DeleteStatus(apiClient, 10, 101); err != nil {
+		t.Errorf("Ошибка при удалении этапа: %v", err)
+	}
+
+	// Системные этапы не изменяются и не удаляются, запрос не выполняется
+	if _, err := UpdateStatus(apiClient, 10, &Status{ID: StatusWon, Name: "Победа"}); err == nil {
+		t.Error("Ожидалась ошибка при изменении этапа 142, но ее не было")
+	}
+	if err := DeleteStatus(apiClient, 10, StatusLost); err == nil {
+		t.Error("Ожидалась ошибка при удалении этапа 143, но ее не было")
+	}
+}
+
+func TestCreatePipelines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v4/leads/pipelines" {
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+
+		var request []map[string]json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка при чтении тела запроса: %v", err)
+		}
+		if len(request) != 1 || !strings.Contains(string(request[0]["_embedded"]), `"Переговоры"`) {
+			t.Errorf("Этапы должны передаваться в _embedded.statuses: %v", request)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"_embedded":{"pipelines":[{"id":20,"name":"Партнеры","_embedded":{"statuses":[
+			{"id":200,"name":"Переговоры","pipeline_id":20},
+			{"id":142,"name":"Успешно реализовано","pipeline_id":20},
+			{"id":143,"name":"Закрыто и не реализовано","pipeline_id":20}]}}]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	created, err := CreatePipelines(apiClient, []Pipeline{{
+		Name: "Партнеры",
+		Sort: 20,
+		Statuses: []Status{{
+			Name:         "Переговоры",
+			Color:        "#ffcc66",
+			Descriptions: []StatusDescription{{Level: DescriptionLevelMaster, Description: "Согласовать условия"}},
+		}},
+	}})
+	if err != nil {
+		t.Fatalf("Ошибка при создании воронок: %v", err)
+	}
+
+	if len(created) != 1 || created[0].ID != 20 {
+		t.Fatalf("Неверный результат создания воронок: %+v", created)
+	}
+	// Этапы читаются из _embedded.statuses
+	if len(created[0].Statuses) != 3 || !created[0].Statuses[1].IsWon() {
+		t.Errorf("Неверные этапы созданной воронки: %+v", created[0].Statuses)
+	}
+}
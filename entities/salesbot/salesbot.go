@@ -0,0 +1,194 @@
+// Пакет salesbot предоставляет методы для запуска и остановки Salesbot в API amoCRM.
+package salesbot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/tasks"
+)
+
+// MaxBatchSize максимальное количество запусков в одном запросе.
+const MaxBatchSize = 100
+
+// entityTypeCodes числовые типы сущностей API Salesbot
+var entityTypeCodes = map[string]int{
+	tasks.EntityTypeContact: 1,
+	tasks.EntityTypeLead:    2,
+}
+
+// Item запуск или остановка бота BotID для сущности EntityID типа EntityType.
+// EntityType задается константами типов сущностей пакета tasks, например tasks.EntityTypeLead.
+type Item struct {
+	BotID      int
+	EntityID   int
+	EntityType string
+}
+
+// Validate проверяет параметры запуска.
+// Salesbot запускается только для сделок и контактов.
+func (i Item) Validate() error {
+	if i.BotID <= 0 {
+		return fmt.Errorf("не задан ID бота")
+	}
+	if i.EntityID <= 0 {
+		return fmt.Errorf("не задан ID сущности")
+	}
+	if _, ok := entityTypeCodes[i.EntityType]; ok {
+		return nil
+	}
+	switch i.EntityType {
+	case tasks.EntityTypeCompany, tasks.EntityTypeCustomer:
+		return fmt.Errorf("тип сущности %s не поддерживается Salesbot", i.EntityType)
+	}
+	return fmt.Errorf("неизвестный тип сущности: %q", i.EntityType)
+}
+
+// Result результат запуска или остановки бота для одной сущности.
+// Error равен nil, если amoCRM принял запрос.
+type Result struct {
+	Item  Item
+	Error error
+}
+
+// runItem элемент запроса запуска
+type runItem struct {
+	BotID      int `json:"bot_id"`
+	EntityID   int `json:"entity_id"`
+	EntityType int `json:"entity_type"`
+}
+
+// Run запускает ботов для сущностей. Запуски отправляются пачками по MaxBatchSize.
+// Для каждого элемента возвращается результат: элементы с неверными параметрами не отправляются,
+// а при ошибке запроса ошибку получают все элементы пачки.
+// Ошибка возвращается, если хотя бы один запуск не принят.
+func Run(apiClient *client.Client, items []Item) ([]Result, error) {
+	results := make([]Result, len(items))
+
+	var batch []int
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		payload := make([]runItem, len(batch))
+		for i, index := range batch {
+			item := items[index]
+			payload[i] = runItem{BotID: item.BotID, EntityID: item.EntityID, EntityType: entityTypeCodes[item.EntityType]}
+		}
+		err := post(apiClient, fmt.Sprintf("%s/api/v2/salesbot/run", apiClient.GetBaseURL()), payload)
+		for _, index := range batch {
+			results[index].Error = err
+		}
+		batch = batch[:0]
+	}
+
+	for i, item := range items {
+		results[i].Item = item
+		if err := item.Validate(); err != nil {
+			results[i].Error = err
+			continue
+		}
+		batch = append(batch, i)
+		if len(batch) == MaxBatchSize {
+			flush()
+		}
+	}
+	flush()
+
+	return results, resultsError("запустить", results)
+}
+
+// Stop останавливает ботов для сущностей.
+// API amoCRM не поддерживает пакетную остановку: /api/v2/salesbot/{bot_id}/stop принимает одну сущность,
+// поэтому для каждого элемента выполняется отдельный запрос.
+// Ошибка возвращается, если хотя бы одна остановка не принята.
+func Stop(apiClient *client.Client, items []Item) ([]Result, error) {
+	results := make([]Result, len(items))
+	for i, item := range items {
+		results[i].Item = item
+		if err := item.Validate(); err != nil {
+			results[i].Error = err
+			continue
+		}
+		payload := map[string]int{
+			"entity_id":   item.EntityID,
+			"entity_type": entityTypeCodes[item.EntityType],
+		}
+		results[i].Error = post(apiClient, fmt.Sprintf("%s/api/v2/salesbot/%d/stop", apiClient.GetBaseURL(), item.BotID), payload)
+	}
+	return results, resultsError("остановить", results)
+}
+
+// RunBot запускает бота для одной сущности.
+func RunBot(apiClient *client.Client, botID, entityID int, entityType string) error {
+	_, err := Run(apiClient, []Item{{BotID: botID, EntityID: entityID, EntityType: entityType}})
+	return err
+}
+
+// StopBot останавливает бота для одной сущности.
+func StopBot(apiClient *client.Client, botID, entityID int, entityType string) error {
+	_, err := Stop(apiClient, []Item{{BotID: botID, EntityID: entityID, EntityType: entityType}})
+	return err
+}
+
+// post отправляет запрос к API Salesbot
+func post(apiClient *client.Client, url string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	// amoCRM может вернуть {"success": false}, если запрос не выполнен
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var response struct {
+		Success *bool `json:"success"`
+	}
+	if json.Unmarshal(body, &response) == nil && response.Success != nil && !*response.Success {
+		return fmt.Errorf("amoCRM не выполнил запрос: %s", bytes.TrimSpace(body))
+	}
+
+	return nil
+}
+
+// resultsError возвращает ошибку, если хотя бы один элемент не выполнен
+func resultsError(action string, results []Result) error {
+	failed := 0
+	var first error
+	for _, result := range results {
+		if result.Error != nil {
+			if first == nil {
+				first = result.Error
+			}
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("не удалось %s ботов для %d из %d сущностей: %w", action, failed, len(results), first)
+}
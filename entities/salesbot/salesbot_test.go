@@ -0,0 +1,138 @@
+package salesbot
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/tasks"
+)
+
+func TestItemValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		item  Item
+		valid bool
+	}{
+		{"Сделка", Item{BotID: 1, EntityID: 10, EntityType: tasks.EntityTypeLead}, true},
+		{"Контакт", Item{BotID: 1, EntityID: 10, EntityType: tasks.EntityTypeContact}, true},
+		{"Компания", Item{BotID: 1, EntityID: 10, EntityType: tasks.EntityTypeCompany}, false},
+		{"Неизвестный тип", Item{BotID: 1, EntityID: 10, EntityType: "lead"}, false},
+		{"Без бота", Item{EntityID: 10, EntityType: tasks.EntityTypeLead}, false},
+		{"Без сущности", Item{BotID: 1, EntityType: tasks.EntityTypeLead}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.item.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Ожидалась корректность %v, получена ошибка %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	var batches [][]runItem
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v2/salesbot/run" {
+			t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+		}
+		var batch []runItem
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		batches = append(batches, batch)
+
+		// Вторая пачка отклоняется
+		if len(batches) == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	items := []Item{{BotID: 5, EntityID: 1, EntityType: tasks.EntityTypeContact}, {BotID: 5, EntityID: 2, EntityType: tasks.EntityTypeCustomer}}
+	for i := 0; i < MaxBatchSize; i++ {
+		items = append(items, Item{BotID: 7, EntityID: 100 + i, EntityType: tasks.EntityTypeLead})
+	}
+
+	results, err := Run(apiClient, items)
+	if err == nil {
+		t.Error("Ожидалась ошибка при частичном запуске")
+	}
+	if len(results) != len(items) {
+		t.Fatalf("Ожидалось %d результатов, получено %d", len(items), len(results))
+	}
+
+	if len(batches) != 2 || len(batches[0]) != MaxBatchSize || len(batches[1]) != 1 {
+		t.Fatalf("Неверное разбиение на пачки: %d", len(batches))
+	}
+	if first := batches[0][0]; first != (runItem{BotID: 5, EntityID: 1, EntityType: 1}) {
+		t.Errorf("Неверный элемент запроса: %+v", first)
+	}
+	if second := batches[0][1]; second.EntityType != 2 {
+		t.Errorf("Ожидался числовой тип сделки 2, получен %d", second.EntityType)
+	}
+
+	if results[0].Error != nil || results[2].Error != nil {
+		t.Errorf("Запуски первой пачки должны быть приняты: %v, %v", results[0].Error, results[2].Error)
+	}
+	if results[1].Error == nil || results[1].Item.EntityID != 2 {
+		t.Error("Запуск для покупателя должен завершиться ошибкой проверки")
+	}
+	if results[len(results)-1].Error == nil {
+		t.Error("Запуск из отклоненной пачки должен завершиться ошибкой")
+	}
+}
+
+func TestStop(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		var body map[string]int
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+		}
+		if body["entity_id"] != 10 || body["entity_type"] != 2 {
+			t.Errorf("Неверное тело запроса: %v", body)
+		}
+		if r.URL.Path == "/api/v2/salesbot/6/stop" {
+			_, _ = w.Write([]byte(`{"success":false}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	if err := StopBot(apiClient, 5, 10, tasks.EntityTypeLead); err != nil {
+		t.Fatalf("Ошибка при остановке бота: %v", err)
+	}
+
+	results, err := Stop(apiClient, []Item{{BotID: 6, EntityID: 10, EntityType: tasks.EntityTypeLead}})
+	if err == nil || results[0].Error == nil {
+		t.Error("Ожидалась ошибка при ответе success=false")
+	}
+
+	if len(paths) != 2 || paths[0] != "/api/v2/salesbot/5/stop" {
+		t.Errorf("Неверные пути запросов: %v", paths)
+	}
+}
+
+func TestRunBotValidation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Запрос с неверными параметрами не должен отправляться")
+	}))
+	defer server.Close()
+
+	if err := RunBot(client.NewClient(server.URL, "test_api_key"), 5, 10, tasks.EntityTypeCompany); err == nil {
+		t.Error("Ожидалась ошибка для компании")
+	}
+}
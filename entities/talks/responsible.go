@@ -0,0 +1,162 @@
+package talks
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/contacts"
+	"github.com/chudno/amo_crm_sdk/entities/customers"
+	"github.com/chudno/amo_crm_sdk/entities/leads"
+)
+
+// pageSize размер страницы при загрузке бесед и связанных сущностей
+const pageSize = 250
+
+// idsChunkSize количество ID в одном запросе связанных сущностей
+const idsChunkSize = 50
+
+// ListOpenTalks получает все открытые беседы.
+func ListOpenTalks(apiClient *client.Client) ([]Talk, error) {
+	inWork := true
+	filter := &TalksFilter{IsInWork: &inWork}
+
+	var result []Talk
+	for page := 1; ; page++ {
+		talks, err := ListTalks(apiClient, page, pageSize, filter)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, talks...)
+		if len(talks) < pageSize {
+			return result, nil
+		}
+	}
+}
+
+// CountOpenByResponsible возвращает количество открытых бесед по ID ответственных пользователей.
+// У беседы нет ответственного, поэтому он берется из сделки или покупателя беседы, а если их нет - из контакта.
+// Беседы, для которых ответственного определить не удалось, учитываются под ключом 0.
+func CountOpenByResponsible(apiClient *client.Client) (map[int]int, error) {
+	talks, err := ListOpenTalks(apiClient)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении открытых бесед: %w", err)
+	}
+
+	var leadIDs, customerIDs, contactIDs []int
+	for i := range talks {
+		links := talks[i].Links()
+		switch {
+		case links.LeadID > 0:
+			leadIDs = append(leadIDs, links.LeadID)
+		case links.CustomerID > 0:
+			customerIDs = append(customerIDs, links.CustomerID)
+		case links.ContactID > 0:
+			contactIDs = append(contactIDs, links.ContactID)
+		}
+	}
+
+	leadUsers, err := leadResponsibles(apiClient, uniqueIDs(leadIDs))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении сделок бесед: %w", err)
+	}
+	customerUsers, err := customerResponsibles(apiClient, uniqueIDs(customerIDs))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении покупателей бесед: %w", err)
+	}
+	contactUsers, err := contactResponsibles(apiClient, uniqueIDs(contactIDs))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении контактов бесед: %w", err)
+	}
+
+	counts := make(map[int]int)
+	for i := range talks {
+		links := talks[i].Links()
+		switch {
+		case links.LeadID > 0:
+			counts[leadUsers[links.LeadID]]++
+		case links.CustomerID > 0:
+			counts[customerUsers[links.CustomerID]]++
+		case links.ContactID > 0:
+			counts[contactUsers[links.ContactID]]++
+		default:
+			counts[0]++
+		}
+	}
+	return counts, nil
+}
+
+// leadResponsibles возвращает ответственных по ID сделок
+func leadResponsibles(apiClient *client.Client, ids []int) (map[int]int, error) {
+	result := make(map[int]int, len(ids))
+	for _, chunk := range chunkIDs(ids) {
+		filter := make(map[string]string, len(chunk))
+		for i, id := range chunk {
+			filter["filter[id]["+strconv.Itoa(i)+"]"] = strconv.Itoa(id)
+		}
+		found, err := leads.GetLeads(apiClient, 1, idsChunkSize, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, lead := range found {
+			result[lead.ID] = lead.ResponsibleUserID
+		}
+	}
+	return result, nil
+}
+
+// customerResponsibles возвращает ответственных по ID покупателей
+func customerResponsibles(apiClient *client.Client, ids []int) (map[int]int, error) {
+	result := make(map[int]int, len(ids))
+	for _, chunk := range chunkIDs(ids) {
+		found, err := customers.GetCustomers(apiClient, 1, idsChunkSize, &customers.CustomersFilter{IDs: chunk})
+		if err != nil {
+			return nil, err
+		}
+		for _, customer := range found {
+			result[customer.ID] = customer.ResponsibleUserID
+		}
+	}
+	return result, nil
+}
+
+// contactResponsibles возвращает ответственных по ID контактов
+func contactResponsibles(apiClient *client.Client, ids []int) (map[int]int, error) {
+	result := make(map[int]int, len(ids))
+	for _, chunk := range chunkIDs(ids) {
+		found, err := contacts.FindContacts(apiClient, 1, idsChunkSize, &contacts.ContactsFilter{IDs: chunk})
+		if err != nil {
+			return nil, err
+		}
+		for _, contact := range found {
+			result[contact.ID] = contact.ResponsibleUserID
+		}
+	}
+	return result, nil
+}
+
+// uniqueIDs возвращает ID без повторов в исходном порядке
+func uniqueIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// chunkIDs разбивает ID на части для запросов
+func chunkIDs(ids []int) [][]int {
+	var chunks [][]int
+	for len(ids) > idsChunkSize {
+		chunks = append(chunks, ids[:idsChunkSize])
+		ids = ids[idsChunkSize:]
+	}
+	if len(ids) > 0 {
+		chunks = append(chunks, ids)
+	}
+	return chunks
+}
@@ -0,0 +1,89 @@
+package talks
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestCountOpenByResponsible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/api/v4/talks":
+			if query.Get("filter[is_in_work]") != "1" {
+				t.Errorf("Ожидался фильтр открытых бесед: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"talks":[
+				{"talk_id":1,"contact_id":11,"entity_id":21,"entity_type":"lead","is_in_work":true},
+				{"talk_id":2,"contact_id":12,"entity_id":21,"entity_type":"lead","is_in_work":true},
+				{"talk_id":3,"contact_id":13,"entity_id":22,"entity_type":"lead","is_in_work":true},
+				{"talk_id":4,"contact_id":14,"entity_id":31,"entity_type":"customer","is_in_work":true},
+				{"talk_id":5,"contact_id":15,"is_in_work":true},
+				{"talk_id":6,"is_in_work":true}
+			]}}`))
+		case "/api/v4/leads":
+			ids := []string{query.Get("filter[id][0]"), query.Get("filter[id][1]")}
+			if strings.Join(ids, ",") != "21,22" {
+				t.Errorf("Неверный фильтр сделок: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"leads":[{"id":21,"responsible_user_id":100},{"id":22,"responsible_user_id":200}]}}`))
+		case "/api/v4/customers":
+			if query.Get("filter[id][]") != "31" {
+				t.Errorf("Неверный фильтр покупателей: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"customers":[{"id":31,"responsible_user_id":200}]}}`))
+		case "/api/v4/contacts":
+			if query.Get("filter[id][]") != "15" {
+				t.Errorf("Неверный фильтр контактов: %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"contacts":[{"id":15,"responsible_user_id":300}]}}`))
+		default:
+			t.Errorf("Неожиданный запрос: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	counts, err := CountOpenByResponsible(client.NewClient(server.URL, "test_api_key"))
+	if err != nil {
+		t.Fatalf("Ошибка при подсчете открытых бесед: %v", err)
+	}
+
+	expected := map[int]int{100: 2, 200: 2, 300: 1, 0: 1}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Ожидалось %v, получено %v", expected, counts)
+	}
+}
+
+func TestListOpenTalksPages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+
+		count := pageSize
+		if page == "2" {
+			count = 1
+		}
+		items := make([]string, count)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"talk_id":%s%03d,"is_in_work":true}`, page, i)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"talks":[` + strings.Join(items, ",") + `]}}`))
+	}))
+	defer server.Close()
+
+	talks, err := ListOpenTalks(client.NewClient(server.URL, "test_api_key"))
+	if err != nil {
+		t.Fatalf("Ошибка при получении открытых бесед: %v", err)
+	}
+	if len(talks) != pageSize+1 || !reflect.DeepEqual(pages, []string{"1", "2"}) {
+		t.Errorf("Ожидалось %d бесед на страницах 1 и 2, получено %d на %v", pageSize+1, len(talks), pages)
+	}
+}
@@ -0,0 +1,247 @@
+// Пакет talks предоставляет методы для работы с беседами в API amoCRM.
+//
+// Беседа объединяет сообщения чата с клиентом и привязана к контакту и сделке или покупателю.
+package talks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/internal/filter"
+)
+
+// EntityType тип сущности, к которой привязана беседа.
+type EntityType string
+
+const (
+	// EntityTypeLead беседа привязана к сделке
+	EntityTypeLead EntityType = "lead"
+	// EntityTypeCustomer беседа привязана к покупателю
+	EntityTypeCustomer EntityType = "customer"
+)
+
+// Talk беседа в amoCRM.
+type Talk struct {
+	ID         int           `json:"talk_id"`
+	CreatedAt  int64         `json:"created_at,omitempty"`
+	UpdatedAt  int64         `json:"updated_at,omitempty"`
+	Rate       int           `json:"rate,omitempty"`
+	ContactID  int           `json:"contact_id,omitempty"`
+	ChatID     string        `json:"chat_id,omitempty"`
+	EntityID   int           `json:"entity_id,omitempty"`
+	EntityType EntityType    `json:"entity_type,omitempty"`
+	IsInWork   bool          `json:"is_in_work"`
+	IsRead     bool          `json:"is_read"`
+	Origin     string        `json:"origin,omitempty"`
+	MissedAt   int64         `json:"missed_at,omitempty"`
+	AccountID  int           `json:"account_id,omitempty"`
+	Embedded   *TalkEmbedded `json:"_embedded,omitempty"`
+}
+
+// EntityRef ссылка на связанную сущность.
+type EntityRef struct {
+	ID int `json:"id"`
+}
+
+// TalkEmbedded связанные с беседой сущности.
+type TalkEmbedded struct {
+	Contacts  []EntityRef `json:"contacts,omitempty"`
+	Leads     []EntityRef `json:"leads,omitempty"`
+	Customers []EntityRef `json:"customers,omitempty"`
+}
+
+// Links типизированные связи беседы. Нулевое значение означает, что связи нет.
+// ChatID - ID чата в API чатов (amojo), совпадает с ConversationRefID пакета chats.
+type Links struct {
+	ContactID  int
+	LeadID     int
+	CustomerID int
+	ChatID     string
+}
+
+// Links возвращает связи беседы с контактом, сделкой или покупателем и чатом.
+// Если поля беседы не заполнены, связи берутся из _embedded.
+func (t *Talk) Links() Links {
+	links := Links{ContactID: t.ContactID, ChatID: t.ChatID}
+
+	switch t.EntityType {
+	case EntityTypeLead:
+		links.LeadID = t.EntityID
+	case EntityTypeCustomer:
+		links.CustomerID = t.EntityID
+	}
+
+	if t.Embedded != nil {
+		if links.ContactID == 0 && len(t.Embedded.Contacts) > 0 {
+			links.ContactID = t.Embedded.Contacts[0].ID
+		}
+		if links.LeadID == 0 && len(t.Embedded.Leads) > 0 {
+			links.LeadID = t.Embedded.Leads[0].ID
+		}
+		if links.CustomerID == 0 && len(t.Embedded.Customers) > 0 {
+			links.CustomerID = t.Embedded.Customers[0].ID
+		}
+	}
+
+	return links
+}
+
+// IsClosed проверяет, закрыта ли беседа.
+func (t *Talk) IsClosed() bool {
+	return !t.IsInWork
+}
+
+// TalksFilter задает параметры фильтрации списка бесед.
+type TalksFilter struct {
+	// IsInWork - фильтр по открытым (true) или закрытым (false) беседам
+	IsInWork *bool
+	// IsRead - фильтр по прочитанным (true) или непрочитанным (false) беседам
+	IsRead *bool
+	// ContactIDs - фильтр по ID контактов
+	ContactIDs []int
+	// EntityType, EntityIDs - фильтр по сделкам или покупателям
+	EntityType EntityType
+	EntityIDs  []int
+	// CreatedAtFrom, CreatedAtTo - диапазон даты создания (Unix timestamp)
+	CreatedAtFrom int64
+	CreatedAtTo   int64
+	// UpdatedAtFrom, UpdatedAtTo - диапазон даты изменения (Unix timestamp)
+	UpdatedAtFrom int64
+	UpdatedAtTo   int64
+}
+
+// Values преобразует фильтр в параметры запроса API amoCRM.
+func (f *TalksFilter) Values() url.Values {
+	params := url.Values{}
+	if f == nil {
+		return params
+	}
+
+	if f.IsInWork != nil {
+		params.Set("filter[is_in_work]", filter.Bool(*f.IsInWork))
+	}
+	if f.IsRead != nil {
+		params.Set("filter[is_read]", filter.Bool(*f.IsRead))
+	}
+	for _, id := range f.ContactIDs {
+		params.Add("filter[contact_id][]", strconv.Itoa(id))
+	}
+	if f.EntityType != "" {
+		params.Set("filter[entity_type]", string(f.EntityType))
+	}
+	for _, id := range f.EntityIDs {
+		params.Add("filter[entity_id][]", strconv.Itoa(id))
+	}
+	filter.AddRange(params, "created_at", f.CreatedAtFrom, f.CreatedAtTo)
+	filter.AddRange(params, "updated_at", f.UpdatedAtFrom, f.UpdatedAtTo)
+
+	return params
+}
+
+// GetTalk получает беседу по ее ID.
+func GetTalk(apiClient *client.Client, talkID int) (*Talk, error) {
+	url := fmt.Sprintf("%s/api/v4/talks/%d", apiClient.GetBaseURL(), talkID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var talk Talk
+	if err := json.NewDecoder(resp.Body).Decode(&talk); err != nil {
+		return nil, err
+	}
+
+	return &talk, nil
+}
+
+// ListTalks получает список бесед с возможностью фильтрации и пагинации.
+// Если беседы не найдены, возвращается пустой список без ошибки.
+func ListTalks(apiClient *client.Client, page, limit int, filter *TalksFilter) ([]Talk, error) {
+	baseURL := fmt.Sprintf("%s/api/v4/talks", apiClient.GetBaseURL())
+
+	// Добавляем параметры запроса
+	params := filter.Values()
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// amoCRM возвращает 204, если по запросу ничего не найдено
+	if resp.StatusCode == http.StatusNoContent {
+		return []Talk{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Talks []Talk `json:"talks"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Talks, nil
+}
+
+// CloseTalk закрывает беседу.
+// Если в аккаунте включен NPS-бот, без forceClose беседа закрывается после запроса оценки у клиента,
+// а с forceClose - сразу, без запроса оценки.
+func CloseTalk(apiClient *client.Client, talkID int, forceClose bool) error {
+	url := fmt.Sprintf("%s/api/v4/talks/%d/close", apiClient.GetBaseURL(), talkID)
+
+	data, err := json.Marshal(map[string]bool{"force_close": forceClose})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package talks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+const talkJSON = `{
+	"talk_id": 4077,
+	"created_at": 1685620800,
+	"updated_at": 1685620900,
+	"rate": 0,
+	"contact_id": 11,
+	"chat_id": "8e3e7640-49af-4448-a2c6-d5a421f7f217",
+	"entity_id": 21,
+	"entity_type": "lead",
+	"is_in_work": true,
+	"is_read": false,
+	"origin": "telegram",
+	"missed_at": 1685620950,
+	"account_id": 28805383,
+	"_embedded": {
+		"contacts": [{"id": 11}],
+		"leads": [{"id": 21}]
+	}
+}`
+
+func TestGetTalk(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v4/talks/4077" {
+			t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(talkJSON))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	talk, err := GetTalk(apiClient, 4077)
+	if err != nil {
+		t.Fatalf("Ошибка при получении беседы: %v", err)
+	}
+	if talk.ID != 4077 || talk.Origin != "telegram" || !talk.IsInWork || talk.IsRead || talk.IsClosed() {
+		t.Errorf("Неверные данные беседы: %+v", talk)
+	}
+
+	links := talk.Links()
+	expected := Links{ContactID: 11, LeadID: 21, ChatID: "8e3e7640-49af-4448-a2c6-d5a421f7f217"}
+	if links != expected {
+		t.Errorf("Ожидались связи %+v, получено %+v", expected, links)
+	}
+}
+
+func TestTalkLinks(t *testing.T) {
+	customerTalk := Talk{ID: 1, EntityType: EntityTypeCustomer, EntityID: 31}
+	if links := customerTalk.Links(); links.CustomerID != 31 || links.LeadID != 0 {
+		t.Errorf("Неверные связи беседы с покупателем: %+v", links)
+	}
+
+	embeddedTalk := Talk{ID: 2, Embedded: &TalkEmbedded{Contacts: []EntityRef{{ID: 12}}, Leads: []EntityRef{{ID: 22}}}}
+	if links := embeddedTalk.Links(); links.ContactID != 12 || links.LeadID != 22 {
+		t.Errorf("Связи должны браться из _embedded: %+v", links)
+	}
+}
+
+func TestListTalks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/api/v4/talks" || query.Get("page") != "2" || query.Get("limit") != "50" {
+			t.Errorf("Неверный запрос: %s", r.URL.String())
+		}
+		if query.Get("filter[is_in_work]") != "1" || query.Get("filter[is_read]") != "0" {
+			t.Errorf("Неверный фильтр: %s", r.URL.RawQuery)
+		}
+		if query.Get("filter[entity_type]") != "lead" || query["filter[entity_id][]"][1] != "22" {
+			t.Errorf("Неверный фильтр по сущностям: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"talks":[` + talkJSON + `]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	inWork, read := true, false
+	talks, err := ListTalks(apiClient, 2, 50, &TalksFilter{
+		IsInWork:   &inWork,
+		IsRead:     &read,
+		EntityType: EntityTypeLead,
+		EntityIDs:  []int{21, 22},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при получении списка бесед: %v", err)
+	}
+	if len(talks) != 1 || talks[0].ID != 4077 {
+		t.Errorf("Неверный список бесед: %+v", talks)
+	}
+}
+
+func TestTalksFilterValues(t *testing.T) {
+	var filter *TalksFilter
+	if len(filter.Values()) != 0 {
+		t.Error("Пустой фильтр не должен добавлять параметры")
+	}
+
+	filter = &TalksFilter{ContactIDs: []int{1, 2}, CreatedAtFrom: 100, UpdatedAtTo: 200}
+	expected := url.Values{
+		"filter[contact_id][]":     {"1", "2"},
+		"filter[created_at][from]": {"100"},
+		"filter[updated_at][to]":   {"200"},
+	}
+	if values := filter.Values(); values.Encode() != expected.Encode() {
+		t.Errorf("Ожидались параметры %s, получено %s", expected.Encode(), values.Encode())
+	}
+}
+
+func TestListTalksNoContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	talks, err := ListTalks(client.NewClient(server.URL, "test_api_key"), 1, 50, nil)
+	if err != nil {
+		t.Fatalf("Ошибка при получении списка бесед: %v", err)
+	}
+	if len(talks) != 0 {
+		t.Errorf("Ожидался пустой список, получено %d", len(talks))
+	}
+}
+
+func TestCloseTalk(t *testing.T) {
+	for _, forceClose := range []bool{false, true} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "POST" || r.URL.Path != "/api/v4/talks/4077/close" {
+				t.Errorf("Неверный запрос: %s %s", r.Method, r.URL.Path)
+			}
+			var body map[string]bool
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatalf("Ошибка при разборе тела запроса: %v", err)
+			}
+			if body["force_close"] != forceClose {
+				t.Errorf("Ожидалось force_close=%v, получено %v", forceClose, body["force_close"])
+			}
+			w.WriteHeader(http.StatusAccepted)
+		}))
+
+		if err := CloseTalk(client.NewClient(server.URL, "test_api_key"), 4077, forceClose); err != nil {
+			t.Errorf("Ошибка при закрытии беседы: %v", err)
+		}
+		server.Close()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := CloseTalk(client.NewClient(server.URL, "test_api_key"), 1, false); err == nil {
+		t.Error("Ожидалась ошибка при статусе 404")
+	}
+}
@@ -0,0 +1,366 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// MaxBatchSize - максимальное количество задач в одном запросе пакетных методов
+const MaxBatchSize = 50
+
+// BatchResult - результат пакетной операции для одной задачи.
+type BatchResult struct {
+	// Task - задача из ответа amoCRM (ID и время изменения), nil при ошибке
+	Task *Task
+	// Err - ошибка для задачи, nil при успехе
+	Err error
+}
+
+// BatchResults - результаты пакетной операции в порядке переданных задач.
+type BatchResults []BatchResult
+
+// Failed возвращает количество задач, которые не удалось обработать.
+func (r BatchResults) Failed() int {
+	failed := 0
+	for _, result := range r {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Err возвращает ошибку, описывающую все неудачные задачи, или nil, если ошибок нет.
+func (r BatchResults) Err() error {
+	var messages []string
+	for i, result := range r {
+		if result.Err != nil {
+			messages = append(messages, fmt.Sprintf("задача #%d: %v", i, result.Err))
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return fmt.Errorf("не обработано задач: %d из %d: %s", len(messages), len(r), strings.Join(messages, "; "))
+}
+
+// CreateTasks создает задачи, разбивая их на запросы по MaxBatchSize.
+// Ошибка в одной задаче не прерывает обработку остальных.
+func CreateTasks(apiClient *client.Client, tasks []Task) BatchResults {
+	payloads := make([]interface{}, len(tasks))
+	for i := range tasks {
+		payloads[i] = tasks[i]
+	}
+	return sendBatch(apiClient, "POST", payloads)
+}
+
+// UpdateTasks обновляет задачи, разбивая их на запросы по MaxBatchSize. У каждой задачи должен быть указан ID.
+// Ошибка в одной задаче не прерывает обработку остальных.
+func UpdateTasks(apiClient *client.Client, tasks []Task) BatchResults {
+	results := make(BatchResults, len(tasks))
+
+	var payloads []interface{}
+	var indexes []int
+	for i := range tasks {
+		if tasks[i].ID == 0 {
+			results[i].Err = fmt.Errorf("ID задачи не указан")
+			continue
+		}
+		payloads = append(payloads, tasks[i])
+		indexes = append(indexes, i)
+	}
+
+	sendSelected(apiClient, "PATCH", payloads, indexes, results)
+	return results
+}
+
+// CompleteTasks отмечает задачи выполненными с указанным результатом.
+// Ошибка в одной задаче не прерывает обработку остальных.
+func CompleteTasks(apiClient *client.Client, taskIDs []int, result string) BatchResults {
+	results := make(BatchResults, len(taskIDs))
+
+	var payloads []interface{}
+	var indexes []int
+	for i, taskID := range taskIDs {
+		if taskID == 0 {
+			results[i].Err = fmt.Errorf("ID задачи не указан")
+			continue
+		}
+
+		payload := map[string]interface{}{
+			"id":           taskID,
+			"is_completed": true,
+		}
+		if result != "" {
+			payload["result"] = map[string]string{"text": result}
+		}
+		payloads = append(payloads, payload)
+		indexes = append(indexes, i)
+	}
+
+	sendSelected(apiClient, "PATCH", payloads, indexes, results)
+	return results
+}
+
+// Rescheduler вычисляет новый срок выполнения задачи по текущему.
+type Rescheduler func(completeTill time.Time) time.Time
+
+// ShiftBy возвращает Rescheduler, сдвигающий срок выполнения на duration.
+func ShiftBy(duration time.Duration) Rescheduler {
+	return func(completeTill time.Time) time.Time {
+		return completeTill.Add(duration)
+	}
+}
+
+// NextBusinessSlot возвращает Rescheduler, переносящий срок выполнения в ближайшее рабочее время.
+// Просроченные задачи переносятся в ближайшее рабочее время, начиная с текущего момента.
+func NextBusinessSlot(hours BusinessHours) Rescheduler {
+	return func(completeTill time.Time) time.Time {
+		if now := time.Now(); completeTill.Before(now) {
+			completeTill = now
+		}
+		return hours.Next(completeTill)
+	}
+}
+
+// RescheduleTasks переносит срок выполнения задач, вычисляя новый срок функцией reschedule.
+// У задач должен быть заполнен текущий срок CompleteTill, для задач без срока возвращается ошибка.
+// Задачи, срок которых не изменился, не отправляются, для них возвращается результат без ошибки.
+func RescheduleTasks(apiClient *client.Client, tasks []Task, reschedule Rescheduler) BatchResults {
+	results := make(BatchResults, len(tasks))
+
+	var payloads []interface{}
+	var indexes []int
+	for i, task := range tasks {
+		if task.ID == 0 {
+			results[i].Err = fmt.Errorf("ID задачи не указан")
+			continue
+		}
+		if task.CompleteTill == 0 {
+			results[i].Err = fmt.Errorf("не указан срок выполнения задачи %d", task.ID)
+			continue
+		}
+
+		completeTill := reschedule(time.Unix(task.CompleteTill, 0)).Unix()
+		if completeTill == task.CompleteTill {
+			results[i].Task = &tasks[i]
+			continue
+		}
+
+		payloads = append(payloads, map[string]interface{}{
+			"id":            task.ID,
+			"complete_till": completeTill,
+		})
+		indexes = append(indexes, i)
+	}
+
+	sendSelected(apiClient, "PATCH", payloads, indexes, results)
+	return results
+}
+
+// BusinessHours описывает рабочее время для переноса задач.
+type BusinessHours struct {
+	// Start, End - начало и конец рабочего дня от полуночи, например 9*time.Hour и 18*time.Hour
+	Start time.Duration
+	End   time.Duration
+	// Weekends - выходные дни, по умолчанию суббота и воскресенье
+	Weekends []time.Weekday
+	// Location - часовой пояс рабочего времени, по умолчанию time.Local
+	Location *time.Location
+}
+
+// Next возвращает ближайший к t момент рабочего времени. Если t попадает в рабочее время, возвращается t.
+// Если рабочее время не задано (Start не меньше End), t возвращается без изменений.
+func (h BusinessHours) Next(t time.Time) time.Time {
+	if h.Start >= h.End {
+		return t
+	}
+
+	location := h.Location
+	if location == nil {
+		location = time.Local
+	}
+	t = t.In(location)
+
+	// Ищем в пределах двух недель, чтобы не зациклиться при некорректных настройках
+	for i := 0; i < 14; i++ {
+		midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+		if !h.isWeekend(midnight.Weekday()) {
+			start := midnight.Add(h.Start)
+			if t.Before(start) {
+				return start
+			}
+			if t.Before(midnight.Add(h.End)) {
+				return t
+			}
+		}
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+	}
+	return t
+}
+
+// isWeekend проверяет, является ли день недели выходным
+func (h BusinessHours) isWeekend(day time.Weekday) bool {
+	if h.Weekends == nil {
+		return day == time.Saturday || day == time.Sunday
+	}
+	for _, weekend := range h.Weekends {
+		if day == weekend {
+			return true
+		}
+	}
+	return false
+}
+
+// sendBatch отправляет задачи частями по MaxBatchSize и собирает результаты по каждой задаче
+func sendBatch(apiClient *client.Client, method string, payloads []interface{}) BatchResults {
+	results := make(BatchResults, len(payloads))
+	for start := 0; start < len(payloads); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(payloads) {
+			end = len(payloads)
+		}
+		sendChunk(apiClient, method, payloads[start:end], results[start:end])
+	}
+	return results
+}
+
+// sendSelected отправляет часть задач и записывает результаты по их индексам в исходном списке
+func sendSelected(apiClient *client.Client, method string, payloads []interface{}, indexes []int, results BatchResults) {
+	for i, result := range sendBatch(apiClient, method, payloads) {
+		results[indexes[i]] = result
+	}
+}
+
+// sendChunk отправляет одну часть задач. Если amoCRM отклонила часть задач,
+// ошибки записываются для них, а остальные задачи отправляются повторно
+func sendChunk(apiClient *client.Client, method string, payloads []interface{}, results []BatchResult) {
+	tasks, itemErrors, err := sendTasks(apiClient, method, payloads)
+	if err == nil {
+		setResults(results, tasks)
+		return
+	}
+	if len(itemErrors) == 0 {
+		for i := range results {
+			results[i].Err = err
+		}
+		return
+	}
+
+	var retryPayloads []interface{}
+	var retryIndexes []int
+	for i := range payloads {
+		if itemErr, ok := itemErrors[i]; ok {
+			results[i].Err = itemErr
+			continue
+		}
+		retryPayloads = append(retryPayloads, payloads[i])
+		retryIndexes = append(retryIndexes, i)
+	}
+	if len(retryPayloads) == 0 {
+		return
+	}
+
+	retryResults := make([]BatchResult, len(retryPayloads))
+	tasks, _, err = sendTasks(apiClient, method, retryPayloads)
+	if err != nil {
+		for i := range retryResults {
+			retryResults[i].Err = err
+		}
+	} else {
+		setResults(retryResults, tasks)
+	}
+	for i, index := range retryIndexes {
+		results[index] = retryResults[i]
+	}
+}
+
+// setResults записывает задачи из ответа в результаты по порядку
+func setResults(results []BatchResult, tasks []Task) {
+	for i := range results {
+		if i < len(tasks) {
+			results[i].Task = &tasks[i]
+		} else {
+			results[i].Err = fmt.Errorf("задача отсутствует в ответе")
+		}
+	}
+}
+
+// validationErrorsResponse - ответ amoCRM с ошибками проверки отдельных элементов запроса
+type validationErrorsResponse struct {
+	ValidationErrors []struct {
+		RequestID string `json:"request_id"`
+		Errors    []struct {
+			Code   string `json:"code"`
+			Path   string `json:"path"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	} `json:"validation-errors"`
+}
+
+// sendTasks выполняет пакетный запрос. При ответе 400 возвращает ошибки по индексам задач, если они указаны
+func sendTasks(apiClient *client.Client, method string, payloads []interface{}) ([]Task, map[int]error, error) {
+	url := fmt.Sprintf("%s/api/v4/tasks", apiClient.GetBaseURL())
+
+	data, err := json.Marshal(payloads)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		var response validationErrorsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+			return nil, nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+		}
+
+		itemErrors := make(map[int]error)
+		for _, item := range response.ValidationErrors {
+			index, err := strconv.Atoi(item.RequestID)
+			if err != nil || index < 0 || index >= len(payloads) {
+				continue
+			}
+			var details []string
+			for _, itemErr := range item.Errors {
+				details = append(details, fmt.Sprintf("%s: %s", itemErr.Path, itemErr.Detail))
+			}
+			itemErrors[index] = fmt.Errorf("ошибка проверки: %s", strings.Join(details, "; "))
+		}
+		return nil, itemErrors, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Tasks []Task `json:"tasks"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, nil, err
+	}
+
+	return response.Embedded.Tasks, nil, nil
+}
@@ -0,0 +1,177 @@
+package tasks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestCreateTasksChunks(t *testing.T) {
+	var chunkSizes []int
+	nextID := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v4/tasks" {
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+
+		var request []Task
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("Ошибка при чтении тела запроса: %v", err)
+		}
+		chunkSizes = append(chunkSizes, len(request))
+
+		// Задача без текста отклоняется, остальные задачи запроса не создаются
+		for i, task := range request {
+			if task.Text == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = fmt.Fprintf(w, `{"validation-errors":[{"request_id":"%d","errors":[{"code":"NotBlank","path":"text","detail":"This value should not be blank."}]}],"status":400}`, i)
+				return
+			}
+		}
+
+		var response struct {
+			Embedded struct {
+				Tasks []Task `json:"tasks"`
+			} `json:"_embedded"`
+		}
+		for range request {
+			nextID++
+			response.Embedded.Tasks = append(response.Embedded.Tasks, Task{ID: nextID})
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	newTasks := make([]Task, 120)
+	for i := range newTasks {
+		newTasks[i] = Task{Text: fmt.Sprintf("Задача %d", i), EntityType: EntityTypeLead, EntityID: i + 1}
+	}
+	newTasks[51].Text = ""
+
+	results := CreateTasks(apiClient, newTasks)
+
+	if fmt.Sprint(chunkSizes) != "[50 50 49 20]" {
+		t.Errorf("Ожидались запросы по 50 задач с повтором части без ошибочной задачи, получено %v", chunkSizes)
+	}
+	if len(results) != 120 || results.Failed() != 1 {
+		t.Fatalf("Ожидалась 1 ошибка из 120 результатов, получено %d из %d", results.Failed(), len(results))
+	}
+	if results[51].Err == nil || results[51].Task != nil {
+		t.Errorf("Ожидалась ошибка для задачи 51, получено %+v", results[51])
+	}
+	if results[50].Task == nil || results[52].Task == nil || results[119].Task == nil {
+		t.Error("Задачи рядом с ошибочной должны быть созданы")
+	}
+	if results.Err() == nil {
+		t.Error("Ожидалась общая ошибка, но ее не было")
+	}
+}
+
+func TestCompleteTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/api/v4/tasks" {
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+
+		var request []map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if len(request) != 2 || request[0]["is_completed"] != true || fmt.Sprint(request[0]["result"]) != "map[text:Готово]" {
+			t.Errorf("Неверное тело запроса: %v", request)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"tasks":[{"id":1},{"id":3}]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	results := CompleteTasks(apiClient, []int{1, 0, 3}, "Готово")
+	if results[0].Task == nil || results[0].Task.ID != 1 || results[2].Task == nil || results[2].Task.ID != 3 {
+		t.Errorf("Неверные результаты: %+v", results)
+	}
+	if results[1].Err == nil {
+		t.Error("Ожидалась ошибка для задачи без ID")
+	}
+}
+
+func TestRescheduleTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request []map[string]int64
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		if len(request) != 1 || request[0]["id"] != 1 || request[0]["complete_till"] != 1700003600 {
+			t.Errorf("Неверное тело запроса: %v", request)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"tasks":[{"id":1}]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	// Срок задачи 2 уже в будущем: сдвиг только для просроченных
+	reschedule := func(completeTill time.Time) time.Time {
+		if completeTill.Unix() < 1700000001 {
+			return completeTill.Add(time.Hour)
+		}
+		return completeTill
+	}
+
+	results := RescheduleTasks(apiClient, []Task{
+		{ID: 1, CompleteTill: 1700000000},
+		{ID: 2, CompleteTill: 1800000000},
+		{ID: 3},
+	}, reschedule)
+	if results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", results.Err())
+	}
+	if results[1].Task == nil || results[1].Task.ID != 2 {
+		t.Errorf("Для задачи без изменений ожидался результат без запроса: %+v", results[1])
+	}
+	if results[2].Err == nil {
+		t.Error("Ожидалась ошибка для задачи без срока выполнения")
+	}
+
+	if shifted := ShiftBy(time.Hour)(time.Unix(1700000000, 0)); shifted.Unix() != 1700003600 {
+		t.Errorf("Ожидался сдвиг на час, получено %v", shifted)
+	}
+}
+
+func TestBusinessHoursNext(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	hours := BusinessHours{Start: 9 * time.Hour, End: 18 * time.Hour, Location: moscow}
+
+	tests := []struct {
+		name     string
+		t        time.Time
+		expected time.Time
+	}{
+		{"В рабочее время", time.Date(2024, 3, 13, 11, 30, 0, 0, moscow), time.Date(2024, 3, 13, 11, 30, 0, 0, moscow)},
+		{"До начала дня", time.Date(2024, 3, 13, 7, 0, 0, 0, moscow), time.Date(2024, 3, 13, 9, 0, 0, 0, moscow)},
+		{"После конца дня", time.Date(2024, 3, 13, 19, 0, 0, 0, moscow), time.Date(2024, 3, 14, 9, 0, 0, 0, moscow)},
+		{"Пятница вечер", time.Date(2024, 3, 15, 18, 0, 0, 0, moscow), time.Date(2024, 3, 18, 9, 0, 0, 0, moscow)},
+		{"Другой часовой пояс", time.Date(2024, 3, 13, 5, 0, 0, 0, time.UTC), time.Date(2024, 3, 13, 9, 0, 0, 0, moscow)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := hours.Next(tt.t); !actual.Equal(tt.expected) {
+				t.Errorf("Ожидалось %v, получено %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBusinessHoursNextEmpty(t *testing.T) {
+	saturday := time.Date(2024, 3, 16, 23, 0, 0, 0, time.UTC)
+	for _, hours := range []BusinessHours{{}, {Start: 18 * time.Hour, End: 9 * time.Hour}} {
+		if actual := hours.Next(saturday); !actual.Equal(saturday) {
+			t.Errorf("Без рабочего времени ожидалось %v, получено %v", saturday, actual)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package tasks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// Системные типы задач
+const (
+	// TaskTypeCall - тип задачи "Связаться"
+	TaskTypeCall = 1
+	// TaskTypeMeeting - тип задачи "Встреча"
+	TaskTypeMeeting = 2
+)
+
+// TaskType представляет собой тип задачи аккаунта amoCRM.
+type TaskType struct {
+	ID     int    `json:"id,omitempty"`
+	Name   string `json:"name"`
+	Color  string `json:"color,omitempty"`
+	IconID int    `json:"icon_id,omitempty"`
+	Code   string `json:"code,omitempty"`
+}
+
+// ListTaskTypes получает типы задач аккаунта, включая системные.
+func ListTaskTypes(apiClient *client.Client) ([]TaskType, error) {
+	url := fmt.Sprintf("%s/api/v4/account?with=task_types", apiClient.GetBaseURL())
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var account struct {
+		Embedded struct {
+			TaskTypes []TaskType `json:"task_types"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&account); err != nil {
+		return nil, err
+	}
+
+	return account.Embedded.TaskTypes, nil
+}
+
+// CreateTaskTypes создает пользовательские типы задач.
+func CreateTaskTypes(apiClient *client.Client, taskTypes []TaskType) ([]TaskType, error) {
+	url := fmt.Sprintf("%s/api/v4/tasks/types", apiClient.GetBaseURL())
+
+	taskTypesData, err := json.Marshal(taskTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(taskTypesData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			TaskTypes []TaskType `json:"task_types"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.TaskTypes, nil
+}
+
+// DeleteTaskType удаляет пользовательский тип задачи. Системные типы удалить нельзя.
+func DeleteTaskType(apiClient *client.Client, taskTypeID int) error {
+	if taskTypeID == TaskTypeCall || taskTypeID == TaskTypeMeeting {
+		return fmt.Errorf("системный тип задачи %d нельзя удалить", taskTypeID)
+	}
+
+	url := fmt.Sprintf("%s/api/v4/tasks/types/%d", apiClient.GetBaseURL(), taskTypeID)
+
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// FindTaskType ищет тип задачи по коду или названию без учета регистра.
+func FindTaskType(taskTypes []TaskType, codeOrName string) *TaskType {
+	for i := range taskTypes {
+		if taskTypes[i].Code != "" && strings.EqualFold(taskTypes[i].Code, codeOrName) {
+			return &taskTypes[i]
+		}
+	}
+	for i := range taskTypes {
+		if strings.EqualFold(taskTypes[i].Name, codeOrName) {
+			return &taskTypes[i]
+		}
+	}
+	return nil
+}
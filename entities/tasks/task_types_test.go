@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestTaskTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v4/account":
+			if r.URL.Query().Get("with") != "task_types" {
+				t.Errorf("Ожидался параметр with=task_types, получен %s", r.URL.Query().Get("with"))
+			}
+			_, _ = w.Write([]byte(`{"id":1,"_embedded":{"task_types":[
+				{"id":1,"name":"Связаться","color":null,"icon_id":null,"code":"FOLLOW_UP"},
+				{"id":2,"name":"Встреча","color":null,"icon_id":null,"code":"MEETING"},
+				{"id":3,"name":"Отправить КП","color":"#ffcc66","icon_id":5,"code":null}]}}`))
+		case "POST /api/v4/tasks/types":
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != `[{"name":"Выставить счет","color":"#99ccff"}]` {
+				t.Errorf("Неверное тело запроса: %s", body)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"task_types":[{"id":4,"name":"Выставить счет","color":"#99ccff"}]}}`))
+		case "DELETE /api/v4/tasks/types/4":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	taskTypes, err := ListTaskTypes(apiClient)
+	if err != nil {
+		t.Fatalf("Ошибка при получении типов задач: %v", err)
+	}
+	if len(taskTypes) != 3 || taskTypes[2].IconID != 5 {
+		t.Fatalf("Неверный список типов задач: %+v", taskTypes)
+	}
+
+	if taskType := FindTaskType(taskTypes, "meeting"); taskType == nil || taskType.ID != TaskTypeMeeting {
+		t.Errorf("Ожидался тип задачи 'Встреча', получено %+v", taskType)
+	}
+	if taskType := FindTaskType(taskTypes, "отправить кп"); taskType == nil || taskType.ID != 3 {
+		t.Errorf("Ожидался тип задачи 3, получено %+v", taskType)
+	}
+	if FindTaskType(taskTypes, "Обед") != nil {
+		t.Error("Тип задачи 'Обед' не должен быть найден")
+	}
+
+	created, err := CreateTaskTypes(apiClient, []TaskType{{Name: "Выставить счет", Color: "#99ccff"}})
+	if err != nil {
+		t.Fatalf("Ошибка при создании типа задачи: %v", err)
+	}
+	if len(created) != 1 || created[0].ID != 4 {
+		t.Errorf("Неверный результат создания: %+v", created)
+	}
+
+	if err := DeleteTaskType(apiClient, 4); err != nil {
+		t.Errorf("Ошибка при удалении типа задачи: %v", err)
+	}
+	if err := DeleteTaskType(apiClient, TaskTypeCall); err == nil || !strings.Contains(err.Error(), "системный") {
+		t.Errorf("Ожидалась ошибка удаления системного типа, получено %v", err)
+	}
+}
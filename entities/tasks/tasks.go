@@ -5,10 +5,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"github.com/chudno/amo_crm_sdk/client"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strconv"
 	"time"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/internal/filter"
 )
 
 // Task представляет собой структуру задачи в amoCRM.
@@ -147,26 +151,133 @@ func CompleteTask(apiClient *client.Client, taskID int, result string) (*Task, e
 }
 
 // ListTasks получает список задач с возможностью фильтрации и пагинации.
+// Ключи filter передаются как filter[ключ], срезы - как filter[ключ][].
+// Для типизированной фильтрации используйте FindTasks.
 func ListTasks(apiClient *client.Client, limit int, page int, filter map[string]interface{}) ([]*Task, error) {
-	baseURL := fmt.Sprintf("%s/api/v4/tasks", apiClient.GetBaseURL())
-
 	// Добавляем параметры запроса
 	params := url.Values{}
-	params.Add("limit", fmt.Sprintf("%d", limit))
-	params.Add("page", fmt.Sprintf("%d", page))
-
-	// Если указаны фильтры, добавляем их в запрос
-	if len(filter) > 0 {
-		filterData, err := json.Marshal(filter)
-		if err != nil {
-			return nil, err
+	for key, value := range filter {
+		addFilterValue(params, key, value)
+	}
+
+	tasks, err := getTasks(apiClient, page, limit, params)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Task, len(tasks))
+	for i := range tasks {
+		result[i] = &tasks[i]
+	}
+	return result, nil
+}
+
+// addFilterValue добавляет в параметры значение фильтра из map
+func addFilterValue(params url.Values, key string, value interface{}) {
+	list := reflect.ValueOf(value)
+	if list.Kind() == reflect.Slice || list.Kind() == reflect.Array {
+		for i := 0; i < list.Len(); i++ {
+			params.Add("filter["+key+"][]", fmt.Sprint(list.Index(i).Interface()))
 		}
-		params.Add("filter", string(filterData))
+		return
 	}
 
-	url := baseURL + "?" + params.Encode()
+	if flag, ok := value.(bool); ok {
+		if flag {
+			value = 1
+		} else {
+			value = 0
+		}
+	}
+	params.Set("filter["+key+"]", fmt.Sprint(value))
+}
 
-	req, err := http.NewRequest("GET", url, nil)
+// Поля сортировки списка задач
+const (
+	OrderByCreatedAt    = "created_at"
+	OrderByCompleteTill = "complete_till"
+	OrderByID           = "id"
+)
+
+// TasksFilter задает параметры фильтрации и сортировки списка задач.
+type TasksFilter struct {
+	// IDs - фильтр по ID задач
+	IDs []int
+	// ResponsibleUserIDs - фильтр по ID ответственных пользователей
+	ResponsibleUserIDs []int
+	// IsCompleted - фильтр по статусу выполнения, nil - все задачи
+	IsCompleted *bool
+	// TaskTypeIDs - фильтр по типам задач
+	TaskTypeIDs []int
+	// EntityType - тип сущности, к которой привязаны задачи
+	EntityType string
+	// EntityIDs - фильтр по ID сущностей, используется вместе с EntityType
+	EntityIDs []int
+	// CompleteTillFrom, CompleteTillTo - диапазон срока выполнения (Unix timestamp)
+	CompleteTillFrom int64
+	CompleteTillTo   int64
+	// UpdatedAtFrom, UpdatedAtTo - диапазон даты изменения (Unix timestamp)
+	UpdatedAtFrom int64
+	UpdatedAtTo   int64
+	// OrderBy - поле сортировки: OrderByCreatedAt, OrderByCompleteTill или OrderByID
+	OrderBy string
+	// OrderDesc - сортировка по убыванию
+	OrderDesc bool
+}
+
+// Values преобразует фильтр в параметры запроса API amoCRM.
+func (f *TasksFilter) Values() url.Values {
+	params := url.Values{}
+	if f == nil {
+		return params
+	}
+
+	for _, id := range f.IDs {
+		params.Add("filter[id][]", strconv.Itoa(id))
+	}
+	for _, userID := range f.ResponsibleUserIDs {
+		params.Add("filter[responsible_user_id][]", strconv.Itoa(userID))
+	}
+	if f.IsCompleted != nil {
+		params.Set("filter[is_completed]", filter.Bool(*f.IsCompleted))
+	}
+	for _, typeID := range f.TaskTypeIDs {
+		params.Add("filter[task_type][]", strconv.Itoa(typeID))
+	}
+	if f.EntityType != "" {
+		params.Set("filter[entity_type]", f.EntityType)
+	}
+	for _, entityID := range f.EntityIDs {
+		params.Add("filter[entity_id][]", strconv.Itoa(entityID))
+	}
+	filter.AddRange(params, "complete_till", f.CompleteTillFrom, f.CompleteTillTo)
+	filter.AddRange(params, "updated_at", f.UpdatedAtFrom, f.UpdatedAtTo)
+
+	if f.OrderBy != "" {
+		order := "asc"
+		if f.OrderDesc {
+			order = "desc"
+		}
+		params.Set("order["+f.OrderBy+"]", order)
+	}
+
+	return params
+}
+
+// FindTasks получает список задач, подходящих под фильтр.
+// Если задачи не найдены, возвращается пустой список без ошибки.
+func FindTasks(apiClient *client.Client, page, limit int, filter *TasksFilter) ([]Task, error) {
+	return getTasks(apiClient, page, limit, filter.Values())
+}
+
+// getTasks выполняет запрос списка задач с указанными параметрами
+func getTasks(apiClient *client.Client, page, limit int, params url.Values) ([]Task, error) {
+	baseURL := fmt.Sprintf("%s/api/v4/tasks", apiClient.GetBaseURL())
+
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("page", strconv.Itoa(page))
+
+	req, err := http.NewRequest("GET", baseURL+"?"+params.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -177,6 +288,11 @@ func ListTasks(apiClient *client.Client, limit int, page int, filter map[string]
 	}
 	defer resp.Body.Close()
 
+	// amoCRM возвращает 204, если по запросу ничего не найдено
+	if resp.StatusCode == http.StatusNoContent {
+		return []Task{}, nil
+	}
+
 	// Проверяем статус-код ответа
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
@@ -184,7 +300,7 @@ func ListTasks(apiClient *client.Client, limit int, page int, filter map[string]
 
 	var response struct {
 		Embedded struct {
-			Tasks []*Task `json:"tasks"`
+			Tasks []Task `json:"tasks"`
 		} `json:"_embedded"`
 	}
 
@@ -214,6 +330,7 @@ func DeleteTask(apiClient *client.Client, taskID int) error {
 }
 
 // CreateTaskForEntity создает новую задачу, привязанную к сущности (лид, контакт, компания).
+// Тип задачи - TaskTypeCall, TaskTypeMeeting или ID из ListTaskTypes.
 func CreateTaskForEntity(apiClient *client.Client, entityType string, entityID int, taskTypeID int, text string, completeTill time.Time, responsibleUserID int) (*Task, error) {
 	task := &Task{
 		EntityType:        entityType,
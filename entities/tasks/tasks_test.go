@@ -321,3 +321,80 @@ func TestCreateTaskForEntity(t *testing.T) {
 		t.Errorf("Ожидался тип сущности 'leads', получен '%s'", createdTask.EntityType)
 	}
 }
+
+func TestFindTasks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		expected := map[string][]string{
+			"filter[responsible_user_id][]": {"7", "8"},
+			"filter[is_completed]":          {"0"},
+			"filter[task_type][]":           {"1"},
+			"filter[entity_type]":           {"leads"},
+			"filter[entity_id][]":           {"100"},
+			"filter[complete_till][from]":   {"1700000000"},
+			"filter[complete_till][to]":     {"1700086400"},
+			"filter[updated_at][from]":      {"1690000000"},
+			"order[complete_till]":          {"desc"},
+			"page":                          {"2"},
+			"limit":                         {"50"},
+		}
+		for key, values := range expected {
+			if fmt.Sprint(query[key]) != fmt.Sprint(values) {
+				t.Errorf("Параметр %s: ожидалось %v, получено %v", key, values, query[key])
+			}
+		}
+		if query.Get("filter") != "" {
+			t.Errorf("Фильтр не должен передаваться одним параметром: %s", query.Get("filter"))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	isCompleted := false
+	tasks, err := FindTasks(apiClient, 2, 50, &TasksFilter{
+		ResponsibleUserIDs: []int{7, 8},
+		IsCompleted:        &isCompleted,
+		TaskTypeIDs:        []int{TaskTypeCall},
+		EntityType:         EntityTypeLead,
+		EntityIDs:          []int{100},
+		CompleteTillFrom:   1700000000,
+		CompleteTillTo:     1700086400,
+		UpdatedAtFrom:      1690000000,
+		OrderBy:            OrderByCompleteTill,
+		OrderDesc:          true,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при поиске задач: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("Ожидался пустой список задач, получено %d", len(tasks))
+	}
+}
+
+func TestListTasksFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("filter[entity_type]") != "contacts" || fmt.Sprint(query["filter[entity_id][]"]) != "[1 2]" || query.Get("filter[is_completed]") != "1" {
+			t.Errorf("Неверные параметры фильтра: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"tasks":[{"id":1}]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	tasks, err := ListTasks(apiClient, 50, 1, map[string]interface{}{
+		"entity_type":  EntityTypeContact,
+		"entity_id":    []int{1, 2},
+		"is_completed": true,
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при получении задач: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != 1 {
+		t.Errorf("Неверный список задач: %+v", tasks)
+	}
+}
@@ -103,8 +103,10 @@ func ListUsers(apiClient *client.Client, limit int, page int) ([]User, error) {
 		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
 	}
 
+	// amoCRM возвращает список в _embedded.users, items поддерживается для совместимости
 	var users struct {
 		Embedded struct {
+			Users []User `json:"users"`
 			Items []User `json:"items"`
 		} `json:"_embedded"`
 	}
@@ -113,5 +115,8 @@ func ListUsers(apiClient *client.Client, limit int, page int) ([]User, error) {
 		return nil, err
 	}
 
+	if len(users.Embedded.Users) > 0 {
+		return users.Embedded.Users, nil
+	}
 	return users.Embedded.Items, nil
 }
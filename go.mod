@@ -1,3 +1,5 @@
 module github.com/chudno/amo_crm_sdk
 
 go 1.18
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,26 @@
+// Пакет filter содержит общие функции формирования параметров фильтров API amoCRM.
+package filter
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// AddRange добавляет в параметры фильтр filter[field][from] и filter[field][to] по диапазону дат.
+// Нулевые границы не передаются.
+func AddRange(params url.Values, field string, from, to int64) {
+	if from > 0 {
+		params.Set("filter["+field+"][from]", strconv.FormatInt(from, 10))
+	}
+	if to > 0 {
+		params.Set("filter["+field+"][to]", strconv.FormatInt(to, 10))
+	}
+}
+
+// Bool преобразует логическое значение в значение параметра фильтра: 1 или 0.
+func Bool(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,23 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestAddRange(t *testing.T) {
+	params := url.Values{}
+	AddRange(params, "created_at", 100, 0)
+	AddRange(params, "updated_at", 0, 200)
+
+	expected := "filter%5Bcreated_at%5D%5Bfrom%5D=100&filter%5Bupdated_at%5D%5Bto%5D=200"
+	if encoded := params.Encode(); encoded != expected {
+		t.Errorf("Ожидались параметры %s, получено %s", expected, encoded)
+	}
+}
+
+func TestBool(t *testing.T) {
+	if Bool(true) != "1" || Bool(false) != "0" {
+		t.Errorf("Неверные значения: %s, %s", Bool(true), Bool(false))
+	}
+}
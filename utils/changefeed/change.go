@@ -0,0 +1,127 @@
+// Пакет changefeed предоставляет единый поток изменений аккаунта amoCRM:
+// типизированные изменения, обработчики по типу изменения и опрос событий как альтернативу вебхукам.
+package changefeed
+
+import (
+	"context"
+
+	"github.com/chudno/amo_crm_sdk/entities/events"
+)
+
+// Source источник изменения
+type Source string
+
+const (
+	// SourcePolling - изменение получено опросом событий
+	SourcePolling Source = "polling"
+	// SourceWebhook - изменение получено вебхуком
+	SourceWebhook Source = "webhook"
+)
+
+// Change изменение сущности в аккаунте amoCRM.
+//
+// Тип изменения совпадает с типом события amoCRM, а значения Before и After - с результатом
+// events.Event.Decode: *events.LeadStatus для смены этапа, *events.ResponsibleUser для смены
+// ответственного, events.Tags для тегов и т.д. Поэтому один обработчик работает одинаково
+// для изменений из опроса событий и из вебхуков.
+type Change struct {
+	Type       events.EventType
+	EntityType events.EventEntityType
+	EntityID   int
+	AccountID  int
+	CreatedBy  int
+	CreatedAt  int64
+	Before     events.Payload
+	After      events.Payload
+	Source     Source
+	// Event - исходное событие, если изменение получено опросом событий
+	Event *events.Event
+}
+
+// FromEvent создает изменение по событию amoCRM.
+func FromEvent(event *events.Event) *Change {
+	before, after := event.Decode()
+
+	return &Change{
+		Type:       event.Type,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+		AccountID:  event.AccountID,
+		CreatedBy:  event.CreatedBy,
+		CreatedAt:  event.CreatedAt,
+		Before:     before,
+		After:      after,
+		Source:     SourcePolling,
+		Event:      event,
+	}
+}
+
+// Handler обрабатывает изменение.
+type Handler func(ctx context.Context, change *Change) error
+
+// Router вызывает обработчики, зарегистрированные для типа изменения.
+type Router struct {
+	handlers map[events.EventType][]Handler
+	all      []Handler
+}
+
+// NewRouter создает пустой маршрутизатор изменений.
+func NewRouter() *Router {
+	return &Router{handlers: make(map[events.EventType][]Handler)}
+}
+
+// Handle регистрирует обработчик изменений указанных типов.
+func (r *Router) Handle(handler Handler, types ...events.EventType) {
+	for _, eventType := range types {
+		r.handlers[eventType] = append(r.handlers[eventType], handler)
+	}
+}
+
+// HandleAll регистрирует обработчик всех изменений.
+func (r *Router) HandleAll(handler Handler) {
+	r.all = append(r.all, handler)
+}
+
+// Handles проверяет, есть ли обработчики для типа изменения.
+func (r *Router) Handles(eventType events.EventType) bool {
+	return len(r.all) > 0 || len(r.handlers[r.key(eventType)]) > 0
+}
+
+// Types возвращает типы изменений, для которых зарегистрированы обработчики.
+// Если есть обработчик всех изменений, возвращается nil.
+func (r *Router) Types() []events.EventType {
+	if len(r.all) > 0 {
+		return nil
+	}
+	types := make([]events.EventType, 0, len(r.handlers))
+	for eventType := range r.handlers {
+		types = append(types, eventType)
+	}
+	return types
+}
+
+// Dispatch вызывает обработчики изменения по порядку регистрации.
+// Обработка прекращается на первой ошибке, ошибка возвращается вызывающему коду.
+func (r *Router) Dispatch(ctx context.Context, change *Change) error {
+	for _, handler := range r.handlers[r.key(change.Type)] {
+		if err := handler(ctx, change); err != nil {
+			return err
+		}
+	}
+	for _, handler := range r.all {
+		if err := handler(ctx, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// key возвращает тип, под которым зарегистрированы обработчики.
+// Изменения конкретного дополнительного поля обрабатываются как custom_field_value_changed,
+// если для самого поля обработчиков нет.
+func (r *Router) key(eventType events.EventType) events.EventType {
+	if _, ok := r.handlers[eventType]; !ok && eventType.IsCustomFieldChange() {
+		return events.EventTypeCustomFieldValueChanged
+	}
+	return eventType
+}
@@ -0,0 +1,263 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/events"
+)
+
+// fakeEvents имитирует API событий amoCRM с фильтром по дате создания и пагинацией
+type fakeEvents struct {
+	mu       sync.Mutex
+	events   []events.Event
+	requests int
+}
+
+func (f *fakeEvents) add(list ...events.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, list...)
+}
+
+func (f *fakeEvents) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	query := r.URL.Query()
+	if query.Get("order[created_at]") != "asc" {
+		http.Error(w, "ожидалась сортировка по возрастанию даты", http.StatusBadRequest)
+		return
+	}
+	from, _ := strconv.ParseInt(query.Get("filter[created_at][from]"), 10, 64)
+	page, _ := strconv.Atoi(query.Get("page"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	var matched []events.Event
+	for _, event := range f.events {
+		if event.CreatedAt >= from {
+			matched = append(matched, event)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].CreatedAt < matched[j].CreatedAt })
+
+	start := (page - 1) * limit
+	if start >= len(matched) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	end := start + limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	var response events.GetEventsResponse
+	response.Embedded.Events = matched[start:end]
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// statusEvent создает событие смены этапа сделки
+func statusEvent(id int, createdAt int64, statusID int) events.Event {
+	return events.Event{
+		ID:          id,
+		Type:        events.EventTypeLeadStatusChanged,
+		EntityType:  events.EventEntityTypeLead,
+		EntityID:    1000 + id,
+		CreatedAt:   createdAt,
+		ValueBefore: json.RawMessage(`[{"lead_status": {"id": 100, "pipeline_id": 10}}]`),
+		ValueAfter:  json.RawMessage(`[{"lead_status": {"id": ` + strconv.Itoa(statusID) + `, "pipeline_id": 10}}]`),
+	}
+}
+
+func TestRouter(t *testing.T) {
+	router := NewRouter()
+
+	var calls []string
+	router.Handle(func(ctx context.Context, change *Change) error {
+		calls = append(calls, "status")
+		return nil
+	}, events.EventTypeLeadStatusChanged)
+	router.Handle(func(ctx context.Context, change *Change) error {
+		calls = append(calls, "field")
+		return nil
+	}, events.EventTypeCustomFieldValueChanged)
+	router.HandleAll(func(ctx context.Context, change *Change) error {
+		calls = append(calls, "all")
+		return nil
+	})
+
+	ctx := context.Background()
+	_ = router.Dispatch(ctx, &Change{Type: events.EventTypeLeadStatusChanged})
+	_ = router.Dispatch(ctx, &Change{Type: events.CustomFieldEventType(555)})
+	_ = router.Dispatch(ctx, &Change{Type: events.EventTypeContactAdded})
+
+	expected := []string{"status", "all", "field", "all", "all"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Ожидались вызовы %v, получено %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Fatalf("Ожидались вызовы %v, получено %v", expected, calls)
+		}
+	}
+}
+
+func TestPoller(t *testing.T) {
+	feed := &fakeEvents{}
+	feed.add(
+		statusEvent(1, 100, 142),
+		statusEvent(2, 100, 143),
+		statusEvent(3, 101, 142),
+	)
+	server := httptest.NewServer(feed)
+	defer server.Close()
+
+	var handled []int
+	failOn := 0
+	router := NewRouter()
+	router.Handle(func(ctx context.Context, change *Change) error {
+		if change.Event.ID == failOn {
+			return errors.New("обработчик недоступен")
+		}
+		status, ok := change.After.(*events.LeadStatus)
+		if !ok || change.Source != SourcePolling {
+			t.Errorf("Ожидалось изменение этапа из опроса, получено %#v", change)
+		}
+		if status != nil && status.PipelineID != 10 {
+			t.Errorf("Ожидалась воронка 10, получена %d", status.PipelineID)
+		}
+		handled = append(handled, change.Event.ID)
+		return nil
+	}, events.EventTypeLeadStatusChanged)
+
+	store := &FileStore{Path: filepath.Join(t.TempDir(), "cursor.json")}
+	newPoller := func() *Poller {
+		return NewPoller(client.NewClient(server.URL, "test_api_key"), router,
+			WithStore(store), WithPageSize(2), WithStartFrom(time.Unix(50, 0)))
+	}
+
+	ctx := context.Background()
+	count, err := newPoller().Poll(ctx)
+	if err != nil {
+		t.Fatalf("Ошибка при опросе событий: %v", err)
+	}
+	if count != 3 || len(handled) != 3 {
+		t.Fatalf("Ожидалось 3 обработанных события, получено %d: %v", count, handled)
+	}
+
+	// Новые события с той же датой, что и последнее обработанное, и ошибка обработчика
+	feed.add(statusEvent(4, 101, 143), statusEvent(5, 102, 142))
+	failOn = 5
+	handled = nil
+
+	count, err = newPoller().Poll(ctx)
+	if err == nil {
+		t.Fatal("Ожидалась ошибка обработчика, но ее не было")
+	}
+	if count != 1 || len(handled) != 1 || handled[0] != 4 {
+		t.Fatalf("Ожидалась обработка только события 4, получено %v", handled)
+	}
+
+	// Повторный опрос после исправления обрабатывает только событие с ошибкой
+	failOn = 0
+	handled = nil
+
+	count, err = newPoller().Poll(ctx)
+	if err != nil {
+		t.Fatalf("Ошибка при опросе событий: %v", err)
+	}
+	if count != 1 || len(handled) != 1 || handled[0] != 5 {
+		t.Fatalf("Ожидалась обработка только события 5, получено %v", handled)
+	}
+
+	cursor, err := store.Load(ctx)
+	if err != nil {
+		t.Fatalf("Ошибка при чтении позиции: %v", err)
+	}
+	if cursor.CreatedAt != 102 || len(cursor.SeenIDs) != 1 || cursor.SeenIDs[0] != 5 {
+		t.Errorf("Неверная позиция опроса: %+v", cursor)
+	}
+}
+
+func TestPollerSkipAfter(t *testing.T) {
+	feed := &fakeEvents{}
+	feed.add(statusEvent(1, 100, 142), statusEvent(2, 101, 143), statusEvent(3, 102, 142))
+	server := httptest.NewServer(feed)
+	defer server.Close()
+
+	var handled []int
+	router := NewRouter()
+	router.Handle(func(ctx context.Context, change *Change) error {
+		if change.Event.ID == 2 {
+			return errors.New("событие не обрабатывается")
+		}
+		handled = append(handled, change.Event.ID)
+		return nil
+	}, events.EventTypeLeadStatusChanged)
+
+	var deadLetters []int
+	poller := NewPoller(client.NewClient(server.URL, "test_api_key"), router,
+		WithStartFrom(time.Unix(50, 0)),
+		WithEventErrorHandler(SkipAfter(2, func(ctx context.Context, event *events.Event, err error) {
+			deadLetters = append(deadLetters, event.ID)
+		})))
+
+	ctx := context.Background()
+
+	// Первая неудачная попытка останавливает опрос на событии 2
+	if _, err := poller.Poll(ctx); err == nil {
+		t.Fatal("Ожидалась ошибка обработчика, но ее не было")
+	}
+	if len(handled) != 1 || len(deadLetters) != 0 {
+		t.Fatalf("Ожидалась обработка только события 1, получено %v, пропущено %v", handled, deadLetters)
+	}
+
+	// Вторая попытка пропускает событие 2, опрос продолжается
+	count, err := poller.Poll(ctx)
+	if err != nil {
+		t.Fatalf("Ошибка при опросе событий: %v", err)
+	}
+	if count != 1 || len(handled) != 2 || handled[1] != 3 {
+		t.Errorf("Ожидалась обработка события 3, получено %d: %v", count, handled)
+	}
+	if len(deadLetters) != 1 || deadLetters[0] != 2 {
+		t.Errorf("Ожидался пропуск события 2, получено %v", deadLetters)
+	}
+
+	// Пропущенное событие не читается повторно
+	if count, err := poller.Poll(ctx); err != nil || count != 0 {
+		t.Errorf("Ожидался опрос без новых событий, получено %d, %v", count, err)
+	}
+}
+
+func TestPollerRun(t *testing.T) {
+	feed := &fakeEvents{}
+	server := httptest.NewServer(feed)
+	defer server.Close()
+
+	poller := NewPoller(client.NewClient(server.URL, "test_api_key"), NewRouter(), WithInterval(time.Millisecond))
+	if poller.interval != MinInterval {
+		t.Errorf("Ожидалась пауза %v, получена %v", MinInterval, poller.interval)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := poller.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ожидалась ошибка отмены контекста, получено %v", err)
+	}
+	if feed.requests != 1 {
+		t.Errorf("Ожидался 1 запрос, выполнено %d", feed.requests)
+	}
+}
@@ -0,0 +1,122 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cursor позиция опроса событий.
+// CreatedAt - дата создания последнего обработанного события, SeenIDs - ID обработанных событий
+// с этой датой: следующий опрос начинается с CreatedAt включительно и пропускает эти события.
+type Cursor struct {
+	CreatedAt int64 `json:"created_at"`
+	SeenIDs   []int `json:"seen_ids,omitempty"`
+}
+
+// seen проверяет, что событие уже обработано
+func (c *Cursor) seen(createdAt int64, id int) bool {
+	if createdAt < c.CreatedAt {
+		return true
+	}
+	if createdAt > c.CreatedAt {
+		return false
+	}
+	for _, seenID := range c.SeenIDs {
+		if seenID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// advance отмечает событие обработанным
+func (c *Cursor) advance(createdAt int64, id int) {
+	if createdAt > c.CreatedAt {
+		c.CreatedAt = createdAt
+		c.SeenIDs = nil
+	}
+	c.SeenIDs = append(c.SeenIDs, id)
+}
+
+// CursorStore хранит позицию опроса событий между запусками.
+type CursorStore interface {
+	Load(ctx context.Context) (Cursor, error)
+	Save(ctx context.Context, cursor Cursor) error
+}
+
+// MemoryStore хранит позицию опроса в памяти процесса.
+type MemoryStore struct {
+	mu     sync.Mutex
+	cursor Cursor
+}
+
+// Load возвращает сохраненную позицию.
+func (s *MemoryStore) Load(ctx context.Context) (Cursor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyCursor(s.cursor), nil
+}
+
+// Save сохраняет позицию.
+func (s *MemoryStore) Save(ctx context.Context, cursor Cursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = copyCursor(cursor)
+	return nil
+}
+
+// FileStore хранит позицию опроса в JSON-файле.
+type FileStore struct {
+	Path string
+}
+
+// Load читает позицию из файла. Если файла нет, возвращается начальная позиция.
+func (s *FileStore) Load(ctx context.Context) (Cursor, error) {
+	var cursor Cursor
+
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cursor, nil
+	}
+	if err != nil {
+		return cursor, err
+	}
+
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+// Save записывает позицию во временный файл и заменяет им прежний,
+// чтобы при сбое не остался частично записанный файл.
+func (s *FileStore) Save(ctx context.Context, cursor Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// copyCursor возвращает копию позиции, не разделяющую список ID с исходной
+func copyCursor(cursor Cursor) Cursor {
+	cursor.SeenIDs = append([]int(nil), cursor.SeenIDs...)
+	return cursor
+}
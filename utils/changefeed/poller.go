@@ -0,0 +1,278 @@
+package changefeed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/events"
+)
+
+// Параметры опроса событий по умолчанию
+const (
+	// DefaultInterval - пауза между опросами
+	DefaultInterval = 30 * time.Second
+	// MinInterval - минимальная пауза между опросами
+	MinInterval = time.Second
+	// DefaultPageSize - количество событий на странице, максимум для API событий
+	DefaultPageSize = 100
+	// MinRequestInterval - минимальная пауза между запросами: amoCRM допускает не более 7 запросов в секунду
+	MinRequestInterval = time.Second / 7
+)
+
+// Poller опрашивает события amoCRM и передает новые изменения в Router.
+//
+// Каждый опрос читает события с даты последнего обработанного события по возрастанию даты создания
+// и пропускает уже обработанные события, поэтому событие обрабатывается один раз, даже если опросы
+// пересекаются по времени. Позиция сохраняется в CursorStore после каждой страницы и при ошибке.
+type Poller struct {
+	apiClient       *client.Client
+	router          *Router
+	store           CursorStore
+	query           events.EventsQuery
+	interval        time.Duration
+	requestInterval time.Duration
+	pageSize        int
+	startFrom       time.Time
+	onError         func(error)
+	onEventError    EventErrorHandler
+	attempts        map[int]int
+	lastRequest     time.Time
+}
+
+// PollerOption задает параметры опроса событий.
+type PollerOption func(*Poller)
+
+// WithStore задает хранилище позиции опроса. По умолчанию позиция хранится в памяти.
+func WithStore(store CursorStore) PollerOption {
+	return func(p *Poller) {
+		p.store = store
+	}
+}
+
+// WithQuery задает фильтр событий. Даты создания и сортировку задает Poller.
+// Если типы событий не указаны, запрашиваются типы, для которых в Router есть обработчики.
+func WithQuery(query events.EventsQuery) PollerOption {
+	return func(p *Poller) {
+		p.query = query
+	}
+}
+
+// WithInterval задает паузу между опросами, не меньше MinInterval.
+func WithInterval(interval time.Duration) PollerOption {
+	return func(p *Poller) {
+		if interval < MinInterval {
+			interval = MinInterval
+		}
+		p.interval = interval
+	}
+}
+
+// WithRequestInterval задает паузу между запросами страниц, не меньше MinRequestInterval.
+// Увеличьте ее, если тот же токен используется другими интеграциями.
+func WithRequestInterval(interval time.Duration) PollerOption {
+	return func(p *Poller) {
+		if interval < MinRequestInterval {
+			interval = MinRequestInterval
+		}
+		p.requestInterval = interval
+	}
+}
+
+// WithPageSize задает количество событий на странице, от 1 до DefaultPageSize.
+func WithPageSize(pageSize int) PollerOption {
+	return func(p *Poller) {
+		if pageSize > 0 && pageSize <= DefaultPageSize {
+			p.pageSize = pageSize
+		}
+	}
+}
+
+// WithStartFrom задает дату, с которой читаются события при первом запуске.
+// По умолчанию первый опрос начинается с текущего времени.
+func WithStartFrom(startFrom time.Time) PollerOption {
+	return func(p *Poller) {
+		p.startFrom = startFrom
+	}
+}
+
+// WithErrorHandler задает функцию, получающую ошибки опроса в Run.
+func WithErrorHandler(onError func(error)) PollerOption {
+	return func(p *Poller) {
+		p.onError = onError
+	}
+}
+
+// EventErrorHandler решает, пропустить ли событие, которое не удалось разобрать или обработать.
+// attempts - количество неудачных попыток обработки события этим Poller, включая текущую.
+// Если функция возвращает true, позиция опроса сдвигается за событие и опрос продолжается.
+type EventErrorHandler func(ctx context.Context, event *events.Event, err error, attempts int) bool
+
+// WithEventErrorHandler задает функцию, которая решает, пропустить ли событие с ошибкой обработки.
+// По умолчанию событие не пропускается: опрос прекращается и событие обрабатывается повторно при следующем опросе.
+func WithEventErrorHandler(handler EventErrorHandler) PollerOption {
+	return func(p *Poller) {
+		p.onEventError = handler
+	}
+}
+
+// SkipAfter возвращает EventErrorHandler, пропускающий событие после maxAttempts неудачных попыток.
+// Пропущенное событие и последняя ошибка передаются в deadLetter, если она задана.
+func SkipAfter(maxAttempts int, deadLetter func(ctx context.Context, event *events.Event, err error)) EventErrorHandler {
+	return func(ctx context.Context, event *events.Event, err error, attempts int) bool {
+		if attempts < maxAttempts {
+			return false
+		}
+		if deadLetter != nil {
+			deadLetter(ctx, event, err)
+		}
+		return true
+	}
+}
+
+// NewPoller создает опрос событий, передающий изменения в router.
+func NewPoller(apiClient *client.Client, router *Router, options ...PollerOption) *Poller {
+	p := &Poller{
+		apiClient:       apiClient,
+		router:          router,
+		store:           &MemoryStore{},
+		interval:        DefaultInterval,
+		requestInterval: MinRequestInterval,
+		pageSize:        DefaultPageSize,
+		onError:         func(error) {},
+		attempts:        make(map[int]int),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Poll выполняет один опрос и возвращает количество обработанных событий.
+// Если обработчик вернул ошибку, опрос прекращается, а событие будет обработано повторно при следующем опросе,
+// если функция из WithEventErrorHandler не решила его пропустить.
+func (p *Poller) Poll(ctx context.Context) (int, error) {
+	cursor, err := p.store.Load(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при чтении позиции опроса: %w", err)
+	}
+	if cursor.CreatedAt == 0 {
+		cursor.CreatedAt = p.startTime().Unix()
+	}
+
+	query := p.query
+	query.CreatedAtFrom = cursor.CreatedAt
+	query.CreatedAtTo = 0
+	query.OrderBy = events.OrderByCreatedAt
+	query.OrderDesc = false
+	if len(query.Types) == 0 && len(query.CustomFieldIDs) == 0 {
+		query.Types = p.router.Types()
+	}
+
+	handled := 0
+	for page := 1; ; page++ {
+		if err := p.wait(ctx); err != nil {
+			return handled, err
+		}
+
+		list, err := events.FindEvents(p.apiClient, page, p.pageSize, &query)
+		if err != nil {
+			return handled, fmt.Errorf("ошибка при получении событий: %w", err)
+		}
+
+		for i := range list {
+			event := &list[i]
+			if cursor.seen(event.CreatedAt, event.ID) {
+				continue
+			}
+
+			if err := p.handle(ctx, event); err != nil {
+				if !p.skip(ctx, event, err) {
+					if saveErr := p.store.Save(ctx, cursor); saveErr != nil {
+						return handled, fmt.Errorf("ошибка при сохранении позиции опроса: %w", saveErr)
+					}
+					return handled, fmt.Errorf("ошибка при обработке события %d: %w", event.ID, err)
+				}
+			} else {
+				delete(p.attempts, event.ID)
+				handled++
+			}
+
+			cursor.advance(event.CreatedAt, event.ID)
+		}
+
+		if err := p.store.Save(ctx, cursor); err != nil {
+			return handled, fmt.Errorf("ошибка при сохранении позиции опроса: %w", err)
+		}
+
+		if len(list) < p.pageSize {
+			return handled, nil
+		}
+	}
+}
+
+// Run опрашивает события с заданной паузой до отмены ctx.
+// Ошибки опроса передаются в функцию из WithErrorHandler и не прерывают работу.
+func (p *Poller) Run(ctx context.Context) error {
+	for {
+		if _, err := p.Poll(ctx); err != nil && ctx.Err() == nil {
+			p.onError(err)
+		}
+
+		if err := sleep(ctx, p.interval); err != nil {
+			return err
+		}
+	}
+}
+
+// handle преобразует событие в изменение и передает его обработчикам
+func (p *Poller) handle(ctx context.Context, event *events.Event) error {
+	return p.router.Dispatch(ctx, FromEvent(event))
+}
+
+// skip учитывает неудачную попытку обработки события и проверяет, нужно ли его пропустить
+func (p *Poller) skip(ctx context.Context, event *events.Event, err error) bool {
+	if p.onEventError == nil {
+		return false
+	}
+
+	p.attempts[event.ID]++
+	if !p.onEventError(ctx, event, err, p.attempts[event.ID]) {
+		return false
+	}
+	delete(p.attempts, event.ID)
+	return true
+}
+
+// startTime возвращает дату, с которой читаются события при первом запуске
+func (p *Poller) startTime() time.Time {
+	if p.startFrom.IsZero() {
+		p.startFrom = time.Now()
+	}
+	return p.startFrom
+}
+
+// wait выдерживает паузу между запросами к API
+func (p *Poller) wait(ctx context.Context) error {
+	if delay := p.requestInterval - time.Since(p.lastRequest); delay > 0 {
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+	p.lastRequest = time.Now()
+	return nil
+}
+
+// sleep ожидает указанное время или отмену ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
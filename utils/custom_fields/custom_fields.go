@@ -3,38 +3,52 @@ package custom_fields
 
 // CustomFieldValue представляет значение пользовательского поля
 type CustomFieldValue struct {
-	FieldID   int          `json:"field_id"`
+	FieldID   int          `json:"field_id,omitempty"`
 	FieldName string       `json:"field_name,omitempty"`
+	FieldCode string       `json:"field_code,omitempty"`
+	FieldType string       `json:"field_type,omitempty"`
 	Values    []FieldValue `json:"values"`
 }
 
 // FieldValue представляет конкретное значение поля
 type FieldValue struct {
-	Value     interface{} `json:"value"`
+	Value     interface{} `json:"value,omitempty"`
 	EnumID    int         `json:"enum_id,omitempty"`
 	EnumCode  string      `json:"enum_code,omitempty"`
 	EnumValue string      `json:"enum_value,omitempty"`
+	Subtype   string      `json:"subtype,omitempty"`
 }
 
 // CustomField представляет структуру пользовательского поля
 type CustomField struct {
-	ID           int               `json:"id"`
-	Name         string            `json:"name"`
-	Type         string            `json:"type"`
-	Code         string            `json:"code,omitempty"`
-	Sort         int               `json:"sort,omitempty"`
-	IsMultiple   bool              `json:"is_multiple,omitempty"`
-	IsSystem     bool              `json:"is_system,omitempty"`
-	IsEditable   bool              `json:"is_editable,omitempty"`
-	IsRequired   bool              `json:"is_required,omitempty"`
-	IsDeleteable bool              `json:"is_deleteable,omitempty"`
-	IsVisible    bool              `json:"is_visible,omitempty"`
-	Enums        []CustomFieldEnum `json:"enums,omitempty"`
+	ID               int               `json:"id,omitempty"`
+	Name             string            `json:"name"`
+	Type             string            `json:"type"`
+	Code             string            `json:"code,omitempty"`
+	Sort             int               `json:"sort,omitempty"`
+	EntityType       string            `json:"entity_type,omitempty"`
+	GroupID          string            `json:"group_id,omitempty"`
+	AccountID        int               `json:"account_id,omitempty"`
+	IsMultiple       bool              `json:"is_multiple,omitempty"`
+	IsSystem         bool              `json:"is_system,omitempty"`
+	IsEditable       bool              `json:"is_editable,omitempty"`
+	IsRequired       bool              `json:"is_required,omitempty"`
+	IsDeleteable     bool              `json:"is_deleteable,omitempty"`
+	IsVisible        bool              `json:"is_visible,omitempty"`
+	IsAPIOnly        bool              `json:"is_api_only,omitempty"`
+	RequiredStatuses []RequiredStatus  `json:"required_statuses,omitempty"`
+	Enums            []CustomFieldEnum `json:"enums,omitempty"`
+}
+
+// RequiredStatus задает этап воронки, на котором поле обязательно для заполнения
+type RequiredStatus struct {
+	StatusID   int `json:"status_id"`
+	PipelineID int `json:"pipeline_id"`
 }
 
 // CustomFieldEnum представляет вариант значения для поля типа список
 type CustomFieldEnum struct {
-	ID    int    `json:"id"`
+	ID    int    `json:"id,omitempty"`
 	Value string `json:"value"`
 	Sort  int    `json:"sort,omitempty"`
 	Code  string `json:"code,omitempty"`
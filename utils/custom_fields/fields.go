@@ -0,0 +1,383 @@
+package custom_fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// EntityType определяет тип сущности, к которой относятся пользовательские поля
+type EntityType string
+
+// Типы сущностей, для которых можно управлять пользовательскими полями
+const (
+	EntityLeads     EntityType = "leads"
+	EntityContacts  EntityType = "contacts"
+	EntityCompanies EntityType = "companies"
+	EntityCustomers EntityType = "customers"
+)
+
+// Типы пользовательских полей
+const (
+	TypeText          = "text"
+	TypeNumeric       = "numeric"
+	TypeCheckbox      = "checkbox"
+	TypeSelect        = "select"
+	TypeMultiselect   = "multiselect"
+	TypeDate          = "date"
+	TypeDateTime      = "date_time"
+	TypeBirthday      = "birthday"
+	TypeURL           = "url"
+	TypeTextarea      = "textarea"
+	TypeRadiobutton   = "radiobutton"
+	TypeStreetAddress = "streetaddress"
+	TypeSmartAddress  = "smart_address"
+	TypeLegalEntity   = "legal_entity"
+	TypeMultitext     = "multitext"
+	TypePrice         = "price"
+	TypeCategory      = "category"
+	TypeItems         = "items"
+	TypeTrackingData  = "tracking_data"
+	TypeMonetary      = "monetary"
+	TypeFile          = "file"
+)
+
+// MaxCustomFieldsLimit - максимальное количество полей на странице, которое отдает API
+const MaxCustomFieldsLimit = 50
+
+// CustomFieldsResponse представляет ответ от API при получении списка пользовательских полей
+type CustomFieldsResponse struct {
+	TotalItems int `json:"_total_items"`
+	Page       int `json:"_page"`
+	PageCount  int `json:"_page_count"`
+	Embedded   struct {
+		CustomFields []CustomField `json:"custom_fields"`
+	} `json:"_embedded"`
+}
+
+// customFieldsURL формирует адрес раздела пользовательских полей сущности
+func customFieldsURL(apiClient *client.Client, entityType EntityType) (string, error) {
+	switch entityType {
+	case EntityLeads, EntityContacts, EntityCompanies, EntityCustomers:
+		return fmt.Sprintf("%s/api/v4/%s/custom_fields", apiClient.GetBaseURL(), entityType), nil
+	default:
+		return "", fmt.Errorf("неподдерживаемый тип сущности: %q", entityType)
+	}
+}
+
+// GetCustomFields получает страницу пользовательских полей сущности.
+// limit не может превышать MaxCustomFieldsLimit.
+func GetCustomFields(apiClient *client.Client, entityType EntityType, page, limit int) ([]CustomField, error) {
+	response, err := getCustomFieldsPage(apiClient, entityType, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.CustomFields, nil
+}
+
+// GetAllCustomFields получает все пользовательские поля сущности, последовательно запрашивая страницы.
+func GetAllCustomFields(apiClient *client.Client, entityType EntityType) ([]CustomField, error) {
+	var fields []CustomField
+
+	for page := 1; ; page++ {
+		response, err := getCustomFieldsPage(apiClient, entityType, page, MaxCustomFieldsLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, response.Embedded.CustomFields...)
+
+		// Последняя страница определяется по _page_count, а если его нет - по неполной странице
+		if response.PageCount > 0 {
+			if page >= response.PageCount {
+				break
+			}
+		} else if len(response.Embedded.CustomFields) < MaxCustomFieldsLimit {
+			break
+		}
+	}
+
+	return fields, nil
+}
+
+// getCustomFieldsPage выполняет запрос одной страницы пользовательских полей
+func getCustomFieldsPage(apiClient *client.Client, entityType EntityType, page, limit int) (*CustomFieldsResponse, error) {
+	baseURL, err := customFieldsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s?page=%d&limit=%d", baseURL, page, limit)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response CustomFieldsResponse
+
+	// amoCRM возвращает 204, если полей нет
+	if resp.StatusCode == http.StatusNoContent {
+		return &response, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// GetCustomField получает пользовательское поле сущности по ID.
+func GetCustomField(apiClient *client.Client, entityType EntityType, fieldID int) (*CustomField, error) {
+	baseURL, err := customFieldsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%d", baseURL, fieldID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var field CustomField
+	if err := json.NewDecoder(resp.Body).Decode(&field); err != nil {
+		return nil, err
+	}
+
+	return &field, nil
+}
+
+// CreateCustomFields создает несколько пользовательских полей сущности одним запросом.
+func CreateCustomFields(apiClient *client.Client, entityType EntityType, fields []CustomField) ([]CustomField, error) {
+	return saveCustomFields(apiClient, "POST", entityType, fields)
+}
+
+// CreateCustomField создает одно пользовательское поле сущности.
+func CreateCustomField(apiClient *client.Client, entityType EntityType, field *CustomField) (*CustomField, error) {
+	created, err := CreateCustomFields(apiClient, entityType, []CustomField{*field})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(created) == 0 {
+		return nil, fmt.Errorf("не удалось создать пользовательское поле")
+	}
+
+	return &created[0], nil
+}
+
+// UpdateCustomFields обновляет несколько пользовательских полей сущности одним запросом.
+// У каждого поля должен быть указан ID.
+func UpdateCustomFields(apiClient *client.Client, entityType EntityType, fields []CustomField) ([]CustomField, error) {
+	for _, field := range fields {
+		if field.ID == 0 {
+			return nil, fmt.Errorf("не указан ID пользовательского поля %q", field.Name)
+		}
+	}
+
+	return saveCustomFields(apiClient, "PATCH", entityType, fields)
+}
+
+// saveCustomFields выполняет пакетное создание или обновление пользовательских полей
+func saveCustomFields(apiClient *client.Client, method string, entityType EntityType, fields []CustomField) ([]CustomField, error) {
+	url, err := customFieldsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldsData, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var response CustomFieldsResponse
+	if err := sendCustomFieldsRequest(apiClient, method, url, fieldsData, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.CustomFields, nil
+}
+
+// UpdateCustomField обновляет пользовательское поле сущности.
+// Поля, которые не нужно менять, следует оставить пустыми.
+func UpdateCustomField(apiClient *client.Client, entityType EntityType, field *CustomField) (*CustomField, error) {
+	if field.ID == 0 {
+		return nil, fmt.Errorf("не указан ID пользовательского поля")
+	}
+
+	// Передаются только заполненные поля: пустые название и тип не должны затирать значения в amoCRM
+	fieldData, err := json.Marshal(field)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]interface{}{}
+	if err := json.Unmarshal(fieldData, &changes); err != nil {
+		return nil, err
+	}
+	if field.Name == "" {
+		delete(changes, "name")
+	}
+	if field.Type == "" {
+		delete(changes, "type")
+	}
+
+	return patchCustomField(apiClient, entityType, field.ID, changes)
+}
+
+// patchCustomField отправляет изменения одного пользовательского поля
+func patchCustomField(apiClient *client.Client, entityType EntityType, fieldID int, changes interface{}) (*CustomField, error) {
+	baseURL, err := customFieldsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	changesData, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated CustomField
+	if err := sendCustomFieldsRequest(apiClient, "PATCH", fmt.Sprintf("%s/%d", baseURL, fieldID), changesData, &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteCustomField удаляет пользовательское поле сущности.
+func DeleteCustomField(apiClient *client.Client, entityType EntityType, fieldID int) error {
+	baseURL, err := customFieldsURL(apiClient, entityType)
+	if err != nil {
+		return err
+	}
+
+	return deleteCustomFieldsResource(apiClient, fmt.Sprintf("%s/%d", baseURL, fieldID))
+}
+
+// SetFieldEnums заменяет варианты значений поля типа список.
+// Варианты без ID будут созданы, варианты поля, отсутствующие в enums, будут удалены.
+func SetFieldEnums(apiClient *client.Client, entityType EntityType, fieldID int, enums []CustomFieldEnum) (*CustomField, error) {
+	if enums == nil {
+		enums = []CustomFieldEnum{}
+	}
+
+	return patchCustomField(apiClient, entityType, fieldID, map[string]interface{}{"enums": enums})
+}
+
+// AddFieldEnums добавляет варианты значений в поле типа список, сохраняя существующие.
+// Варианты, значения которых уже есть в поле (без учета регистра), пропускаются.
+func AddFieldEnums(apiClient *client.Client, entityType EntityType, fieldID int, values ...string) (*CustomField, error) {
+	field, err := GetCustomField(apiClient, entityType, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(field.Enums))
+	maxSort := 0
+	for _, enum := range field.Enums {
+		existing[strings.ToLower(enum.Value)] = true
+		if enum.Sort > maxSort {
+			maxSort = enum.Sort
+		}
+	}
+
+	enums := append([]CustomFieldEnum{}, field.Enums...)
+	added := false
+	for _, value := range values {
+		if existing[strings.ToLower(value)] {
+			continue
+		}
+		existing[strings.ToLower(value)] = true
+		maxSort++
+		enums = append(enums, CustomFieldEnum{Value: value, Sort: maxSort})
+		added = true
+	}
+
+	if !added {
+		return field, nil
+	}
+
+	return SetFieldEnums(apiClient, entityType, fieldID, enums)
+}
+
+// SetRequiredStatuses задает этапы воронок, на которых поле обязательно для заполнения.
+// Пустой список снимает обязательность поля.
+func SetRequiredStatuses(apiClient *client.Client, entityType EntityType, fieldID int, statuses []RequiredStatus) (*CustomField, error) {
+	if statuses == nil {
+		statuses = []RequiredStatus{}
+	}
+
+	return patchCustomField(apiClient, entityType, fieldID, map[string]interface{}{"required_statuses": statuses})
+}
+
+// sendCustomFieldsRequest отправляет JSON-запрос и декодирует ответ в result
+func sendCustomFieldsRequest(apiClient *client.Client, method, url string, data []byte, result interface{}) error {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// deleteCustomFieldsResource удаляет поле или группу полей по адресу url
+func deleteCustomFieldsResource(apiClient *client.Client, url string) error {
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
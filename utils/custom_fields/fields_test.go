@@ -0,0 +1,205 @@
+package custom_fields
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+func TestGetAllCustomFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/api/v4/leads/custom_fields" {
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.Query().Get("limit") != "50" {
+			t.Errorf("Ожидался параметр limit=50, получен %s", r.URL.Query().Get("limit"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+		switch r.URL.Query().Get("page") {
+		case "1":
+			_, _ = w.Write([]byte(`{"_page":1,"_page_count":2,"_embedded":{"custom_fields":[
+				{"id":1,"name":"Источник","type":"select","enums":[{"id":10,"value":"Сайт","sort":1}]}]}}`))
+		case "2":
+			_, _ = w.Write([]byte(`{"_page":2,"_page_count":2,"_embedded":{"custom_fields":[
+				{"id":2,"name":"Бюджет клиента","type":"numeric","required_statuses":[{"status_id":142,"pipeline_id":7}]}]}}`))
+		default:
+			t.Errorf("Неожиданная страница %s", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	fields, err := GetAllCustomFields(apiClient, EntityLeads)
+	if err != nil {
+		t.Fatalf("Ошибка при получении полей: %v", err)
+	}
+
+	if len(fields) != 2 || fields[0].Enums[0].Value != "Сайт" || fields[1].RequiredStatuses[0].PipelineID != 7 {
+		t.Errorf("Получены неверные поля: %+v", fields)
+	}
+
+	if _, err := GetCustomFields(apiClient, EntityType("tasks"), 1, 50); err == nil {
+		t.Error("Ожидалась ошибка для неподдерживаемого типа сущности, но ее не было")
+	}
+}
+
+func TestCreateAndUpdateCustomFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v4/contacts/custom_fields":
+			expected := `[{"name":"Источник","type":"select","group_id":"contacts_1","enums":[{"value":"Сайт","sort":1},{"value":"Реклама","sort":2}]}]`
+			if string(body) != expected {
+				t.Errorf("Неожиданное тело запроса:\n%s\nожидалось:\n%s", body, expected)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"custom_fields":[{"id":5,"name":"Источник","type":"select"}]}}`))
+		case "PATCH /api/v4/contacts/custom_fields/5":
+			_, _ = w.Write([]byte(`{"id":5,"name":"Источник","type":"select"}`))
+			switch string(body) {
+			case `{"required_statuses":[]}`, `{"id":5,"name":"Канал","type":"select"}`, `{"id":5,"sort":10}`:
+			default:
+				t.Errorf("Неожиданное тело запроса: %s", body)
+			}
+		case "DELETE /api/v4/contacts/custom_fields/5":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	field, err := CreateCustomField(apiClient, EntityContacts, &CustomField{
+		Name:    "Источник",
+		Type:    TypeSelect,
+		GroupID: "contacts_1",
+		Enums: []CustomFieldEnum{
+			{Value: "Сайт", Sort: 1},
+			{Value: "Реклама", Sort: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Ошибка при создании поля: %v", err)
+	}
+	if field.ID != 5 {
+		t.Errorf("Ожидался ID поля 5, получен %d", field.ID)
+	}
+
+	if _, err := UpdateCustomField(apiClient, EntityContacts, &CustomField{ID: 5, Name: "Канал", Type: TypeSelect}); err != nil {
+		t.Errorf("Ошибка при обновлении поля: %v", err)
+	}
+
+	// Незаполненные название и тип не передаются
+	if _, err := UpdateCustomField(apiClient, EntityContacts, &CustomField{ID: 5, Sort: 10}); err != nil {
+		t.Errorf("Ошибка при частичном обновлении поля: %v", err)
+	}
+
+	// Пустой список этапов должен передаваться явно, чтобы снять обязательность
+	if _, err := SetRequiredStatuses(apiClient, EntityContacts, 5, nil); err != nil {
+		t.Errorf("Ошибка при изменении обязательности поля: %v", err)
+	}
+
+	if _, err := UpdateCustomFields(apiClient, EntityContacts, []CustomField{{Name: "Без ID"}}); err == nil {
+		t.Error("Ожидалась ошибка для поля без ID, но ее не было")
+	}
+
+	if err := DeleteCustomField(apiClient, EntityContacts, 5); err != nil {
+		t.Errorf("Ошибка при удалении поля: %v", err)
+	}
+}
+
+func TestAddFieldEnums(t *testing.T) {
+	patched := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v4/companies/custom_fields/8":
+			_, _ = w.Write([]byte(`{"id":8,"name":"Отрасль","type":"select","enums":[{"id":1,"value":"Ритейл","sort":1},{"id":2,"value":"IT","sort":2}]}`))
+		case "PATCH /api/v4/companies/custom_fields/8":
+			body, _ := ioutil.ReadAll(r.Body)
+			patched = string(body)
+			_, _ = w.Write([]byte(`{"id":8,"name":"Отрасль","type":"select"}`))
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	if _, err := AddFieldEnums(apiClient, EntityCompanies, 8, "it", "Логистика"); err != nil {
+		t.Fatalf("Ошибка при добавлении вариантов: %v", err)
+	}
+
+	expected := `{"enums":[{"id":1,"value":"Ритейл","sort":1},{"id":2,"value":"IT","sort":2},{"value":"Логистика","sort":3}]}`
+	if patched != expected {
+		t.Errorf("Неожиданное тело запроса:\n%s\nожидалось:\n%s", patched, expected)
+	}
+
+	// Все варианты уже существуют - изменяющий запрос не выполняется
+	patched = ""
+	if _, err := AddFieldEnums(apiClient, EntityCompanies, 8, "Ритейл"); err != nil {
+		t.Fatalf("Ошибка при добавлении вариантов: %v", err)
+	}
+	if patched != "" {
+		t.Errorf("Не ожидалось изменения поля, получено %s", patched)
+	}
+}
+
+func TestCustomFieldGroups(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v4/customers/custom_fields/groups":
+			_, _ = w.Write([]byte(`{"_embedded":{"custom_field_groups":[
+				{"id":"customers_1","name":"Основное","is_predefined":true},
+				{"id":"customers_2","name":"Доставка","sort":1}]}}`))
+		case "POST /api/v4/customers/custom_fields/groups":
+			if string(body) != `[{"name":"Реквизиты","sort":2}]` {
+				t.Errorf("Неожиданное тело запроса: %s", body)
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"custom_field_groups":[{"id":"customers_3","name":"Реквизиты","sort":2}]}}`))
+		case "PATCH /api/v4/customers/custom_fields/groups/customers_3":
+			_, _ = w.Write([]byte(`{"id":"customers_3","name":"Банк","sort":2}`))
+		case "DELETE /api/v4/customers/custom_fields/groups/customers_3":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	groups, err := GetCustomFieldGroups(apiClient, EntityCustomers, 1, 50)
+	if err != nil {
+		t.Fatalf("Ошибка при получении групп: %v", err)
+	}
+	if len(groups) != 2 || !groups[0].IsPredefined || groups[1].ID != "customers_2" {
+		t.Errorf("Получены неверные группы: %+v", groups)
+	}
+
+	created, err := CreateCustomFieldGroups(apiClient, EntityCustomers, []CustomFieldGroup{{Name: "Реквизиты", Sort: 2}})
+	if err != nil || len(created) != 1 || created[0].ID != "customers_3" {
+		t.Fatalf("Ожидалась созданная группа customers_3, получено %+v, ошибка %v", created, err)
+	}
+
+	created[0].Name = "Банк"
+	updated, err := UpdateCustomFieldGroup(apiClient, EntityCustomers, &created[0])
+	if err != nil || updated.Name != "Банк" {
+		t.Errorf("Ожидалась группа с названием Банк, получено %+v, ошибка %v", updated, err)
+	}
+
+	if err := DeleteCustomFieldGroup(apiClient, EntityCustomers, "customers_3"); err != nil {
+		t.Errorf("Ошибка при удалении группы: %v", err)
+	}
+}
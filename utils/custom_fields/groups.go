@@ -0,0 +1,161 @@
+package custom_fields
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// CustomFieldGroup представляет группу пользовательских полей (вкладку в карточке сущности)
+type CustomFieldGroup struct {
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name"`
+	Sort         int    `json:"sort,omitempty"`
+	EntityType   string `json:"entity_type,omitempty"`
+	IsPredefined bool   `json:"is_predefined,omitempty"`
+	Type         string `json:"type,omitempty"`
+}
+
+// CustomFieldGroupsResponse представляет ответ от API при получении списка групп полей
+type CustomFieldGroupsResponse struct {
+	TotalItems int `json:"_total_items"`
+	Page       int `json:"_page"`
+	PageCount  int `json:"_page_count"`
+	Embedded   struct {
+		CustomFieldGroups []CustomFieldGroup `json:"custom_field_groups"`
+	} `json:"_embedded"`
+}
+
+// customFieldGroupsURL формирует адрес раздела групп полей сущности
+func customFieldGroupsURL(apiClient *client.Client, entityType EntityType) (string, error) {
+	baseURL, err := customFieldsURL(apiClient, entityType)
+	if err != nil {
+		return "", err
+	}
+
+	return baseURL + "/groups", nil
+}
+
+// GetCustomFieldGroups получает страницу групп пользовательских полей сущности.
+func GetCustomFieldGroups(apiClient *client.Client, entityType EntityType, page, limit int) ([]CustomFieldGroup, error) {
+	baseURL, err := customFieldGroupsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?page=%d&limit=%d", baseURL, page, limit), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// amoCRM возвращает 204, если групп нет
+	if resp.StatusCode == http.StatusNoContent {
+		return []CustomFieldGroup{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response CustomFieldGroupsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.CustomFieldGroups, nil
+}
+
+// GetCustomFieldGroup получает группу пользовательских полей по ID.
+func GetCustomFieldGroup(apiClient *client.Client, entityType EntityType, groupID string) (*CustomFieldGroup, error) {
+	baseURL, err := customFieldGroupsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s", baseURL, groupID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var group CustomFieldGroup
+	if err := json.NewDecoder(resp.Body).Decode(&group); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// CreateCustomFieldGroups создает группы пользовательских полей сущности.
+func CreateCustomFieldGroups(apiClient *client.Client, entityType EntityType, groups []CustomFieldGroup) ([]CustomFieldGroup, error) {
+	url, err := customFieldGroupsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	groupsData, err := json.Marshal(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	var response CustomFieldGroupsResponse
+	if err := sendCustomFieldsRequest(apiClient, "POST", url, groupsData, &response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.CustomFieldGroups, nil
+}
+
+// UpdateCustomFieldGroup обновляет название или сортировку группы пользовательских полей.
+func UpdateCustomFieldGroup(apiClient *client.Client, entityType EntityType, group *CustomFieldGroup) (*CustomFieldGroup, error) {
+	if group.ID == "" {
+		return nil, fmt.Errorf("не указан ID группы полей")
+	}
+
+	baseURL, err := customFieldGroupsURL(apiClient, entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	groupData, err := json.Marshal(group)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated CustomFieldGroup
+	if err := sendCustomFieldsRequest(apiClient, "PATCH", fmt.Sprintf("%s/%s", baseURL, group.ID), groupData, &updated); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteCustomFieldGroup удаляет группу пользовательских полей.
+// Предустановленные группы удалить нельзя.
+func DeleteCustomFieldGroup(apiClient *client.Client, entityType EntityType, groupID string) error {
+	baseURL, err := customFieldGroupsURL(apiClient, entityType)
+	if err != nil {
+		return err
+	}
+
+	return deleteCustomFieldsResource(apiClient, fmt.Sprintf("%s/%s", baseURL, groupID))
+}
@@ -0,0 +1,397 @@
+package custom_fields
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagName - имя тега структуры, в котором задается соответствие пользовательскому полю
+const tagName = "amo"
+
+// fieldTag содержит разобранный тег amo поля структуры.
+//
+// Поддерживаемые параметры тега:
+//   - field_id=123 - ID пользовательского поля;
+//   - code=PHONE - код поля (используется, если ID не указан);
+//   - enum=WORK - код типа значения для полей телефона и email;
+//   - enum_id - целочисленное поле структуры хранит ID варианта списка, а не число;
+//   - omitempty - не передавать нулевое значение.
+type fieldTag struct {
+	fieldID   int
+	code      string
+	enumCode  string
+	enumID    bool
+	omitEmpty bool
+}
+
+// key возвращает ключ поиска поля
+func (t fieldTag) key() FieldKey {
+	return FieldKey{ID: t.fieldID, Code: t.code}
+}
+
+// String возвращает описание поля для сообщений об ошибках
+func (t fieldTag) String() string {
+	description := "code=" + t.code
+	if t.fieldID != 0 {
+		description = "field_id=" + strconv.Itoa(t.fieldID)
+	}
+	if t.enumCode != "" {
+		description += ", enum=" + t.enumCode
+	}
+	return description
+}
+
+// parseFieldTag разбирает тег amo
+func parseFieldTag(tag string) (fieldTag, error) {
+	var parsed fieldTag
+
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		name, value, hasValue := strings.Cut(part, "=")
+
+		switch {
+		case name == "field_id" && hasValue:
+			id, err := strconv.Atoi(value)
+			if err != nil || id <= 0 {
+				return parsed, fmt.Errorf("неверный field_id %q", value)
+			}
+			parsed.fieldID = id
+		case name == "code" && hasValue && value != "":
+			parsed.code = value
+		case name == "enum" && hasValue && value != "":
+			parsed.enumCode = value
+		case name == "enum_id" && !hasValue:
+			parsed.enumID = true
+		case name == "omitempty" && !hasValue:
+			parsed.omitEmpty = true
+		default:
+			return parsed, fmt.Errorf("неизвестный параметр тега %q", part)
+		}
+	}
+
+	if parsed.fieldID == 0 && parsed.code == "" {
+		return parsed, fmt.Errorf("в теге не указан field_id или code")
+	}
+
+	return parsed, nil
+}
+
+// taggedField связывает поле структуры с пользовательским полем amoCRM
+type taggedField struct {
+	name  string
+	index int
+	tag   fieldTag
+}
+
+// taggedFields возвращает поля структуры с тегом amo
+func taggedFields(structType reflect.Type) ([]taggedField, error) {
+	var fields []taggedField
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok || tag == "-" {
+			continue
+		}
+
+		if !field.IsExported() {
+			return nil, fmt.Errorf("поле %s.%s: поле с тегом amo должно быть экспортируемым", structType.Name(), field.Name)
+		}
+
+		parsed, err := parseFieldTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("поле %s.%s: %v", structType.Name(), field.Name, err)
+		}
+
+		fields = append(fields, taggedField{name: structType.Name() + "." + field.Name, index: i, tag: parsed})
+	}
+
+	return fields, nil
+}
+
+// MarshalFields преобразует структуру с тегами amo в значения пользовательских полей.
+//
+//	type Deal struct {
+//	    Budget    float64   `amo:"field_id=123"`
+//	    Source    int       `amo:"field_id=456,enum_id"`
+//	    WorkPhone string    `amo:"code=PHONE,enum=WORK,omitempty"`
+//	    Phones    []string  `amo:"code=PHONE,enum=MOB"`
+//	    SignedAt  time.Time `amo:"field_id=789,omitempty"`
+//	}
+//
+// Поля структуры с одинаковым field_id или code объединяются в одно пользовательское поле.
+// nil-указатели, пустые срезы и нулевые time.Time пропускаются. Поддерживаются строки, числа, bool, time.Time,
+// Address, LegalEntityValue, указатели на них и срезы для полей с несколькими значениями.
+func MarshalFields(v interface{}) ([]CustomFieldValue, error) {
+	structValue := reflect.ValueOf(v)
+	for structValue.Kind() == reflect.Ptr {
+		if structValue.IsNil() {
+			return nil, fmt.Errorf("передан nil вместо структуры")
+		}
+		structValue = structValue.Elem()
+	}
+	if structValue.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ожидалась структура, получен %s", structValue.Type())
+	}
+
+	fields, err := taggedFields(structValue.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	var result Fields
+	for _, field := range fields {
+		values, err := encodeFieldValue(structValue.Field(field.index), field.tag)
+		if err != nil {
+			return nil, fmt.Errorf("поле %s: %v", field.name, err)
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		if existing := result.Find(field.tag.key()); existing != nil {
+			existing.Values = append(existing.Values, values...)
+			continue
+		}
+		result = append(result, CustomFieldValue{FieldID: field.tag.fieldID, FieldCode: field.tag.code, Values: values})
+	}
+
+	return result, nil
+}
+
+// encodeFieldValue преобразует значение поля структуры в значения пользовательского поля
+func encodeFieldValue(value reflect.Value, tag fieldTag) ([]FieldValue, error) {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	if tag.omitEmpty && value.IsZero() {
+		return nil, nil
+	}
+
+	if value.Kind() == reflect.Slice {
+		var values []FieldValue
+		for i := 0; i < value.Len(); i++ {
+			itemValues, err := encodeScalar(value.Index(i), tag)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, itemValues...)
+		}
+		return values, nil
+	}
+
+	return encodeScalar(value, tag)
+}
+
+// encodeScalar преобразует одиночное значение в значения пользовательского поля
+func encodeScalar(value reflect.Value, tag fieldTag) ([]FieldValue, error) {
+	switch typed := value.Interface().(type) {
+	case time.Time:
+		// Нулевая дата означает отсутствие значения
+		if typed.IsZero() {
+			return nil, nil
+		}
+		return []FieldValue{Date(typed)}, nil
+	case Address:
+		return SmartAddress(typed), nil
+	case LegalEntityValue:
+		return []FieldValue{LegalEntity(typed)}, nil
+	}
+
+	var fieldValue FieldValue
+	switch value.Kind() {
+	case reflect.String:
+		if tag.enumID {
+			return nil, fmt.Errorf("параметр enum_id применим только к целочисленным полям")
+		}
+		fieldValue = Text(value.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if tag.enumID {
+			fieldValue = Select(int(value.Int()))
+		} else {
+			fieldValue = Numeric(float64(value.Int()))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if tag.enumID {
+			fieldValue = Select(int(value.Uint()))
+		} else {
+			fieldValue = Numeric(float64(value.Uint()))
+		}
+	case reflect.Float32, reflect.Float64:
+		fieldValue = Numeric(value.Float())
+	case reflect.Bool:
+		fieldValue = Checkbox(value.Bool())
+	default:
+		return nil, fmt.Errorf("неподдерживаемый тип %s", value.Type())
+	}
+
+	fieldValue.EnumCode = tag.enumCode
+	return []FieldValue{fieldValue}, nil
+}
+
+// UnmarshalFields заполняет структуру с тегами amo из значений пользовательских полей.
+// v должен быть указателем на структуру. Если в теге указан enum, учитываются только значения с этим кодом типа.
+// Если значения поля нет, поля структуры с omitempty, указатели и срезы не изменяются,
+// для остальных полей возвращается ошибка.
+func UnmarshalFields(values []CustomFieldValue, v interface{}) error {
+	pointer := reflect.ValueOf(v)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() || pointer.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ожидался указатель на структуру, получен %T", v)
+	}
+	structValue := pointer.Elem()
+
+	fields, err := taggedFields(structValue.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		var matched []FieldValue
+		if customField := Fields(values).Find(field.tag.key()); customField != nil {
+			for _, value := range customField.Values {
+				if field.tag.enumCode == "" || strings.EqualFold(value.EnumCode, field.tag.enumCode) {
+					matched = append(matched, value)
+				}
+			}
+		}
+		if len(matched) == 0 {
+			if optionalField(structValue.Field(field.index), field.tag) {
+				continue
+			}
+			return fmt.Errorf("поле %s: нет значения пользовательского поля %s", field.name, field.tag)
+		}
+
+		if err := decodeFieldValue(structValue.Field(field.index), matched, field.tag); err != nil {
+			return fmt.Errorf("поле %s: %v", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// optionalField проверяет, может ли поле структуры остаться без значения
+func optionalField(value reflect.Value, tag fieldTag) bool {
+	if tag.omitEmpty {
+		return true
+	}
+	return value.Kind() == reflect.Ptr || value.Kind() == reflect.Slice
+}
+
+// decodeFieldValue записывает значения пользовательского поля в поле структуры
+func decodeFieldValue(target reflect.Value, values []FieldValue, tag fieldTag) error {
+	if target.Kind() == reflect.Ptr {
+		element := reflect.New(target.Type().Elem())
+		if err := decodeFieldValue(element.Elem(), values, tag); err != nil {
+			return err
+		}
+		target.Set(element)
+		return nil
+	}
+
+	if target.Type() == reflect.TypeOf(Address{}) {
+		target.Set(reflect.ValueOf(toAddress(values)))
+		return nil
+	}
+
+	if target.Kind() == reflect.Slice {
+		items := reflect.MakeSlice(target.Type(), 0, len(values))
+		for _, value := range values {
+			item := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeScalar(item, value, tag); err != nil {
+				return err
+			}
+			items = reflect.Append(items, item)
+		}
+		target.Set(items)
+		return nil
+	}
+
+	return decodeScalar(target, values[0], tag)
+}
+
+// decodeScalar записывает одиночное значение пользовательского поля в поле структуры
+func decodeScalar(target reflect.Value, value FieldValue, tag fieldTag) error {
+	switch target.Interface().(type) {
+	case time.Time:
+		parsed, ok := toTime(value.Value)
+		if !ok {
+			return fmt.Errorf("значение %v не является датой", value.Value)
+		}
+		target.Set(reflect.ValueOf(parsed))
+		return nil
+	case LegalEntityValue:
+		entity, ok := toLegalEntity(value.Value)
+		if !ok {
+			return fmt.Errorf("значение %v не является реквизитами юридического лица", value.Value)
+		}
+		target.Set(reflect.ValueOf(entity))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		text, ok := toString(value.Value)
+		if !ok && value.EnumValue == "" {
+			return fmt.Errorf("значение %v не является строкой", value.Value)
+		}
+		if text == "" {
+			text = value.EnumValue
+		}
+		target.SetString(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := integerValue(value, tag)
+		if err != nil {
+			return err
+		}
+		if target.OverflowInt(int64(number)) {
+			return fmt.Errorf("значение %v не помещается в %s", number, target.Type())
+		}
+		target.SetInt(int64(number))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := integerValue(value, tag)
+		if err != nil {
+			return err
+		}
+		if number < 0 || target.OverflowUint(uint64(number)) {
+			return fmt.Errorf("значение %v не помещается в %s", number, target.Type())
+		}
+		target.SetUint(uint64(number))
+	case reflect.Float32, reflect.Float64:
+		number, ok := toFloat(value.Value)
+		if !ok {
+			return fmt.Errorf("значение %v не является числом", value.Value)
+		}
+		target.SetFloat(number)
+	case reflect.Bool:
+		checked, ok := toBool(value.Value)
+		if !ok {
+			return fmt.Errorf("значение %v не является логическим", value.Value)
+		}
+		target.SetBool(checked)
+	default:
+		return fmt.Errorf("неподдерживаемый тип %s", target.Type())
+	}
+
+	return nil
+}
+
+// integerValue возвращает целое значение: ID варианта списка для enum_id или число
+func integerValue(value FieldValue, tag fieldTag) (float64, error) {
+	if tag.enumID {
+		return float64(value.EnumID), nil
+	}
+
+	number, ok := toFloat(value.Value)
+	if !ok || number != math.Trunc(number) {
+		return 0, fmt.Errorf("значение %v не является целым числом", value.Value)
+	}
+	return number, nil
+}
@@ -0,0 +1,170 @@
+package custom_fields
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testDeal - пример пользовательской структуры со связью с полями amoCRM
+type testDeal struct {
+	Title       string    `json:"title"`
+	Budget      float64   `amo:"field_id=3"`
+	Source      int       `amo:"field_id=4,enum_id"`
+	Interests   []int     `amo:"field_id=5,enum_id"`
+	SignedAt    time.Time `amo:"field_id=6,omitempty"`
+	Subscribed  *bool     `amo:"field_id=7"`
+	WorkPhone   string    `amo:"code=PHONE,enum=WORK,omitempty"`
+	MobilePhone []string  `amo:"code=PHONE,enum=MOB"`
+	Address     *Address  `amo:"field_id=8"`
+	Comment     *string   `amo:"field_id=1"`
+	Ignored     string    `amo:"-"`
+}
+
+func TestMarshalFields(t *testing.T) {
+	subscribed := true
+	deal := testDeal{
+		Budget:      1500,
+		Source:      31,
+		Interests:   []int{41, 42},
+		Subscribed:  &subscribed,
+		WorkPhone:   "+79001234567",
+		MobilePhone: []string{"+79007654321"},
+		Address:     &Address{City: "Москва"},
+	}
+
+	values, err := MarshalFields(&deal)
+	if err != nil {
+		t.Fatalf("Ошибка при преобразовании структуры: %v", err)
+	}
+
+	data, _ := json.Marshal(values)
+	expected := `[{"field_id":3,"values":[{"value":1500}]},` +
+		`{"field_id":4,"values":[{"enum_id":31}]},` +
+		`{"field_id":5,"values":[{"enum_id":41},{"enum_id":42}]},` +
+		`{"field_id":7,"values":[{"value":true}]},` +
+		`{"field_code":"PHONE","values":[{"value":"+79001234567","enum_code":"WORK"},{"value":"+79007654321","enum_code":"MOB"}]},` +
+		`{"field_id":8,"values":[{"value":"Москва","subtype":"city"}]}]`
+	if string(data) != expected {
+		t.Errorf("Неверный JSON:\n%s\nожидалось:\n%s", data, expected)
+	}
+}
+
+func TestUnmarshalFields(t *testing.T) {
+	var values []CustomFieldValue
+	if err := json.Unmarshal([]byte(amoCRMFieldsJSON), &values); err != nil {
+		t.Fatalf("Ошибка при разборе JSON: %v", err)
+	}
+
+	var deal testDeal
+	if err := UnmarshalFields(values, &deal); err != nil {
+		t.Fatalf("Ошибка при заполнении структуры: %v", err)
+	}
+
+	if deal.Budget != 1500.5 || deal.Source != 31 || len(deal.Interests) != 2 || deal.Interests[1] != 42 {
+		t.Errorf("Неверные числовые поля и списки: %+v", deal)
+	}
+	if deal.SignedAt.Unix() != 1609459200 {
+		t.Errorf("Ожидалась дата 1609459200, получено %v", deal.SignedAt)
+	}
+	if deal.Subscribed == nil || !*deal.Subscribed {
+		t.Error("Ожидался отмеченный флажок рассылки")
+	}
+	if deal.WorkPhone != "+79001234567" || len(deal.MobilePhone) != 1 || deal.MobilePhone[0] != "+79007654321" {
+		t.Errorf("Телефоны не разделены по типам: %q, %v", deal.WorkPhone, deal.MobilePhone)
+	}
+	if deal.Address == nil || deal.Address.City != "Москва" || deal.Address.Zip != "101000" {
+		t.Errorf("Неверный адрес: %+v", deal.Address)
+	}
+	if deal.Comment == nil || *deal.Comment != "Директор" {
+		t.Errorf("Ожидался комментарий Директор, получено %v", deal.Comment)
+	}
+
+	// Повторное преобразование дает те же значения
+	encoded, err := MarshalFields(deal)
+	if err != nil {
+		t.Fatalf("Ошибка при преобразовании структуры: %v", err)
+	}
+	var decoded testDeal
+	if err := UnmarshalFields(encoded, &decoded); err != nil {
+		t.Fatalf("Ошибка при заполнении структуры: %v", err)
+	}
+	if decoded.Budget != deal.Budget || !decoded.SignedAt.Equal(deal.SignedAt) || decoded.WorkPhone != deal.WorkPhone {
+		t.Errorf("Значения изменились после повторного преобразования: %+v", decoded)
+	}
+}
+
+func TestMarshalFieldsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"Нет ID и кода", &struct {
+			Name string `amo:"enum=WORK"`
+		}{}, "не указан field_id или code"},
+		{"Неизвестный параметр", &struct {
+			Name string `amo:"field_id=1,required"`
+		}{}, "неизвестный параметр тега"},
+		{"Неподдерживаемый тип", &struct {
+			Meta map[string]string `amo:"field_id=1"`
+		}{Meta: map[string]string{"a": "b"}}, "неподдерживаемый тип"},
+		{"enum_id для строки", &struct {
+			Source string `amo:"field_id=1,enum_id"`
+		}{Source: "Сайт"}, "enum_id"},
+		{"Не структура", 42, "ожидалась структура"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := MarshalFields(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Ожидалась ошибка с текстом %q, получено %v", tt.expected, err)
+			}
+		})
+	}
+
+	var target struct {
+		Budget int `amo:"field_id=3"`
+	}
+	values := []CustomFieldValue{Field(3, Text("много"))}
+	if err := UnmarshalFields(values, &target); err == nil || !strings.Contains(err.Error(), "Budget") {
+		t.Errorf("Ожидалась ошибка с именем поля Budget, получено %v", err)
+	}
+	if err := UnmarshalFields(values, target); err == nil {
+		t.Error("Ожидалась ошибка для значения, переданного не по указателю")
+	}
+
+	// Поле без omitempty, значения которого нет в сущности
+	if err := UnmarshalFields([]CustomFieldValue{Field(4, Numeric(1))}, &target); err == nil ||
+		!strings.Contains(err.Error(), "Budget") || !strings.Contains(err.Error(), "field_id=3") {
+		t.Errorf("Ожидалась ошибка об отсутствии значения поля Budget, получено %v", err)
+	}
+}
+
+func TestUnmarshalFieldsOptional(t *testing.T) {
+	var deal testDeal
+	if err := UnmarshalFields([]CustomFieldValue{Field(3, Numeric(100)), Field(4, Select(31))}, &deal); err != nil {
+		t.Fatalf("Поля с omitempty, указатели и срезы не должны быть обязательными: %v", err)
+	}
+	if deal.Budget != 100 || deal.Source != 31 || deal.Address != nil || !deal.SignedAt.IsZero() {
+		t.Errorf("Неверно заполнена структура: %+v", deal)
+	}
+}
+
+func TestMarshalFieldsZeroTime(t *testing.T) {
+	var dates struct {
+		SignedAt time.Time   `amo:"field_id=6"`
+		Meetings []time.Time `amo:"field_id=9"`
+	}
+	dates.Meetings = []time.Time{{}, time.Unix(1609459200, 0)}
+
+	values, err := MarshalFields(dates)
+	if err != nil {
+		t.Fatalf("Ошибка при преобразовании структуры: %v", err)
+	}
+	if len(values) != 1 || values[0].FieldID != 9 || len(values[0].Values) != 1 {
+		t.Errorf("Нулевые даты должны пропускаться, получено %+v", values)
+	}
+}
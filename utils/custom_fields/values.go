@@ -0,0 +1,430 @@
+package custom_fields
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Коды типов значений для полей телефона и email (поля типа multitext)
+const (
+	PhoneWork   = "WORK"
+	PhoneWorkDD = "WORKDD"
+	PhoneMobile = "MOB"
+	PhoneFax    = "FAX"
+	PhoneHome   = "HOME"
+	PhoneOther  = "OTHER"
+
+	EmailWork    = "WORK"
+	EmailPrivate = "PRIV"
+	EmailOther   = "OTHER"
+)
+
+// Подтипы значений поля типа smart_address
+const (
+	SubtypeAddressLine1 = "address_line_1"
+	SubtypeAddressLine2 = "address_line_2"
+	SubtypeCity         = "city"
+	SubtypeState        = "state"
+	SubtypeZip          = "zip"
+	SubtypeCountry      = "country"
+)
+
+// Address представляет значение поля типа smart_address
+type Address struct {
+	AddressLine1 string
+	AddressLine2 string
+	City         string
+	State        string
+	Zip          string
+	Country      string
+}
+
+// LegalEntityValue представляет значение поля типа legal_entity (реквизиты юридического лица)
+type LegalEntityValue struct {
+	Name                      string `json:"name"`
+	EntityType                *int   `json:"entity_type,omitempty"`
+	VatID                     string `json:"vat_id,omitempty"`
+	TaxRegistrationReasonCode string `json:"tax_registration_reason_code,omitempty"`
+	Address                   string `json:"address,omitempty"`
+	KPP                       string `json:"kpp,omitempty"`
+	ExternalUID               string `json:"external_uid,omitempty"`
+}
+
+// EnumValue представляет выбранный вариант поля типа список
+type EnumValue struct {
+	ID    int
+	Code  string
+	Value string
+}
+
+// MultitextValue представляет одно значение поля телефона или email вместе с его типом
+type MultitextValue struct {
+	Value    string
+	EnumID   int
+	EnumCode string
+}
+
+// Field собирает значение пользовательского поля по его ID.
+func Field(fieldID int, values ...FieldValue) CustomFieldValue {
+	return CustomFieldValue{FieldID: fieldID, Values: values}
+}
+
+// FieldByCode собирает значение пользовательского поля по его коду (например, PHONE или EMAIL).
+func FieldByCode(code string, values ...FieldValue) CustomFieldValue {
+	return CustomFieldValue{FieldCode: code, Values: values}
+}
+
+// Text создает значение полей text, textarea и streetaddress.
+func Text(value string) FieldValue {
+	return FieldValue{Value: value}
+}
+
+// Numeric создает значение полей numeric, price и monetary.
+func Numeric(value float64) FieldValue {
+	return FieldValue{Value: value}
+}
+
+// Checkbox создает значение поля checkbox.
+func Checkbox(value bool) FieldValue {
+	return FieldValue{Value: value}
+}
+
+// URL создает значение поля url.
+func URL(value string) FieldValue {
+	return FieldValue{Value: value}
+}
+
+// Date создает значение полей date, date_time и birthday в виде Unix timestamp.
+func Date(value time.Time) FieldValue {
+	return FieldValue{Value: value.Unix()}
+}
+
+// Select создает значение полей select и radiobutton по ID варианта.
+func Select(enumID int) FieldValue {
+	return FieldValue{EnumID: enumID}
+}
+
+// MultiSelect создает значения поля multiselect по ID вариантов.
+func MultiSelect(enumIDs ...int) []FieldValue {
+	values := make([]FieldValue, 0, len(enumIDs))
+	for _, enumID := range enumIDs {
+		values = append(values, Select(enumID))
+	}
+	return values
+}
+
+// Phone создает значение поля телефона с типом, например PhoneWork или PhoneMobile.
+func Phone(number, enumCode string) FieldValue {
+	return FieldValue{Value: number, EnumCode: enumCode}
+}
+
+// Email создает значение поля email с типом, например EmailWork или EmailPrivate.
+func Email(email, enumCode string) FieldValue {
+	return FieldValue{Value: email, EnumCode: enumCode}
+}
+
+// SmartAddress создает значения поля smart_address. Пустые части адреса пропускаются.
+func SmartAddress(address Address) []FieldValue {
+	var values []FieldValue
+	for _, part := range address.parts() {
+		if part.value != "" {
+			values = append(values, FieldValue{Value: part.value, Subtype: part.subtype})
+		}
+	}
+	return values
+}
+
+// LegalEntity создает значение поля legal_entity.
+func LegalEntity(value LegalEntityValue) FieldValue {
+	return FieldValue{Value: value}
+}
+
+// TrackingData создает значение поля tracking_data (например, utm_source).
+func TrackingData(value string) FieldValue {
+	return FieldValue{Value: value}
+}
+
+// addressPart связывает подтип поля smart_address с частью адреса
+type addressPart struct {
+	subtype string
+	value   string
+}
+
+// parts возвращает части адреса в порядке, в котором их отдает amoCRM
+func (a Address) parts() []addressPart {
+	return []addressPart{
+		{SubtypeAddressLine1, a.AddressLine1},
+		{SubtypeAddressLine2, a.AddressLine2},
+		{SubtypeCity, a.City},
+		{SubtypeState, a.State},
+		{SubtypeZip, a.Zip},
+		{SubtypeCountry, a.Country},
+	}
+}
+
+// FieldKey задает способ поиска поля: по ID или по коду
+type FieldKey struct {
+	ID   int
+	Code string
+}
+
+// ByID возвращает ключ поиска поля по ID.
+func ByID(fieldID int) FieldKey {
+	return FieldKey{ID: fieldID}
+}
+
+// ByCode возвращает ключ поиска поля по коду. Регистр кода не учитывается.
+func ByCode(code string) FieldKey {
+	return FieldKey{Code: code}
+}
+
+// matches проверяет, соответствует ли значение поля ключу
+func (k FieldKey) matches(field CustomFieldValue) bool {
+	if k.ID != 0 {
+		return field.FieldID == k.ID
+	}
+	return k.Code != "" && strings.EqualFold(field.FieldCode, k.Code)
+}
+
+// Fields предоставляет типизированный доступ к значениям пользовательских полей сущности:
+//
+//	phones := custom_fields.Fields(contact.CustomFieldsValues).Multitext(custom_fields.ByCode("PHONE"))
+type Fields []CustomFieldValue
+
+// Find возвращает значение поля по ключу или nil, если поле не заполнено.
+func (f Fields) Find(key FieldKey) *CustomFieldValue {
+	for i := range f {
+		if key.matches(f[i]) {
+			return &f[i]
+		}
+	}
+	return nil
+}
+
+// Set заменяет значение поля с тем же ID или кодом либо добавляет его в конец списка.
+func (f Fields) Set(value CustomFieldValue) Fields {
+	key := FieldKey{ID: value.FieldID, Code: value.FieldCode}
+	for i := range f {
+		if key.matches(f[i]) {
+			f[i] = value
+			return f
+		}
+	}
+	return append(f, value)
+}
+
+// first возвращает первое значение поля
+func (f Fields) first(key FieldKey) (FieldValue, bool) {
+	field := f.Find(key)
+	if field == nil || len(field.Values) == 0 {
+		return FieldValue{}, false
+	}
+	return field.Values[0], true
+}
+
+// Text возвращает строковое значение поля: text, textarea, url, streetaddress, tracking_data
+// или значение выбранного варианта списка.
+func (f Fields) Text(key FieldKey) (string, bool) {
+	value, ok := f.first(key)
+	if !ok {
+		return "", false
+	}
+	return toString(value.Value)
+}
+
+// Numeric возвращает значение поля numeric, price или monetary.
+// amoCRM может передавать число как строку, такие значения тоже разбираются.
+func (f Fields) Numeric(key FieldKey) (float64, bool) {
+	value, ok := f.first(key)
+	if !ok {
+		return 0, false
+	}
+	return toFloat(value.Value)
+}
+
+// Checkbox возвращает значение поля checkbox.
+func (f Fields) Checkbox(key FieldKey) (bool, bool) {
+	value, ok := f.first(key)
+	if !ok {
+		return false, false
+	}
+	return toBool(value.Value)
+}
+
+// Date возвращает значение поля date, date_time или birthday.
+func (f Fields) Date(key FieldKey) (time.Time, bool) {
+	value, ok := f.first(key)
+	if !ok {
+		return time.Time{}, false
+	}
+	return toTime(value.Value)
+}
+
+// Select возвращает выбранный вариант поля select или radiobutton.
+func (f Fields) Select(key FieldKey) (EnumValue, bool) {
+	value, ok := f.first(key)
+	if !ok {
+		return EnumValue{}, false
+	}
+	return toEnumValue(value), true
+}
+
+// MultiSelect возвращает выбранные варианты поля multiselect.
+func (f Fields) MultiSelect(key FieldKey) []EnumValue {
+	field := f.Find(key)
+	if field == nil {
+		return nil
+	}
+
+	enums := make([]EnumValue, 0, len(field.Values))
+	for _, value := range field.Values {
+		enums = append(enums, toEnumValue(value))
+	}
+	return enums
+}
+
+// Multitext возвращает значения поля телефона или email вместе с их типами.
+func (f Fields) Multitext(key FieldKey) []MultitextValue {
+	field := f.Find(key)
+	if field == nil {
+		return nil
+	}
+
+	values := make([]MultitextValue, 0, len(field.Values))
+	for _, value := range field.Values {
+		text, _ := toString(value.Value)
+		values = append(values, MultitextValue{Value: text, EnumID: value.EnumID, EnumCode: value.EnumCode})
+	}
+	return values
+}
+
+// SmartAddress возвращает значение поля smart_address.
+func (f Fields) SmartAddress(key FieldKey) (Address, bool) {
+	field := f.Find(key)
+	if field == nil || len(field.Values) == 0 {
+		return Address{}, false
+	}
+	return toAddress(field.Values), true
+}
+
+// LegalEntity возвращает значение поля legal_entity.
+func (f Fields) LegalEntity(key FieldKey) (LegalEntityValue, bool) {
+	value, ok := f.first(key)
+	if !ok {
+		return LegalEntityValue{}, false
+	}
+	return toLegalEntity(value.Value)
+}
+
+// toLegalEntity приводит значение поля к реквизитам юридического лица
+func toLegalEntity(value interface{}) (LegalEntityValue, bool) {
+	if entity, isEntity := value.(LegalEntityValue); isEntity {
+		return entity, true
+	}
+
+	// После разбора JSON значение хранится как map, поэтому преобразуем его через JSON
+	data, err := json.Marshal(value)
+	if err != nil {
+		return LegalEntityValue{}, false
+	}
+	var entity LegalEntityValue
+	if err := json.Unmarshal(data, &entity); err != nil {
+		return LegalEntityValue{}, false
+	}
+	return entity, true
+}
+
+// toAddress собирает адрес из значений поля smart_address
+func toAddress(values []FieldValue) Address {
+	var address Address
+	targets := map[string]*string{
+		SubtypeAddressLine1: &address.AddressLine1,
+		SubtypeAddressLine2: &address.AddressLine2,
+		SubtypeCity:         &address.City,
+		SubtypeState:        &address.State,
+		SubtypeZip:          &address.Zip,
+		SubtypeCountry:      &address.Country,
+	}
+	for _, value := range values {
+		if target, ok := targets[value.Subtype]; ok {
+			*target, _ = toString(value.Value)
+		}
+	}
+	return address
+}
+
+// toBool приводит значение поля к логическому типу
+func toBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		return parsed, err == nil
+	}
+	return false, false
+}
+
+// toTime приводит значение поля к времени: Unix timestamp или строка в формате RFC3339
+func toTime(value interface{}) (time.Time, bool) {
+	if s, isString := value.(string); isString {
+		if parsed, err := time.Parse(time.RFC3339, s); err == nil {
+			return parsed, true
+		}
+	}
+
+	timestamp, ok := toFloat(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(timestamp), 0), true
+}
+
+// toEnumValue преобразует значение поля-списка в EnumValue
+func toEnumValue(value FieldValue) EnumValue {
+	text, _ := toString(value.Value)
+	if text == "" {
+		text = value.EnumValue
+	}
+	return EnumValue{ID: value.EnumID, Code: value.EnumCode, Value: text}
+}
+
+// toString приводит значение поля к строке
+func toString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// toFloat приводит значение поля к числу
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		parsed, err := v.Float64()
+		return parsed, err == nil
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return parsed, err == nil
+	}
+	return 0, false
+}
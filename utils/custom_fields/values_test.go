@@ -0,0 +1,146 @@
+package custom_fields
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// amoCRMFieldsJSON - значения полей в том виде, в котором их отдает amoCRM
+const amoCRMFieldsJSON = `[
+	{"field_id":1,"field_name":"Должность","field_code":"POSITION","field_type":"text","values":[{"value":"Директор"}]},
+	{"field_id":2,"field_name":"Телефон","field_code":"PHONE","field_type":"multitext","values":[
+		{"value":"+79001234567","enum_id":11,"enum_code":"WORK"},
+		{"value":"+79007654321","enum_id":12,"enum_code":"MOB"}]},
+	{"field_id":3,"field_name":"Бюджет клиента","field_type":"numeric","values":[{"value":"1500.5"}]},
+	{"field_id":4,"field_name":"Источник","field_type":"select","values":[{"value":"Сайт","enum_id":31}]},
+	{"field_id":5,"field_name":"Интересы","field_type":"multiselect","values":[{"value":"CRM","enum_id":41},{"value":"Телефония","enum_id":42}]},
+	{"field_id":6,"field_name":"Дата договора","field_type":"date","values":[{"value":1609459200}]},
+	{"field_id":7,"field_name":"Рассылка","field_type":"checkbox","values":[{"value":true}]},
+	{"field_id":8,"field_name":"Адрес","field_type":"smart_address","values":[
+		{"value":"ул. Ленина, 1","subtype":"address_line_1"},
+		{"value":"Москва","subtype":"city"},
+		{"value":"101000","subtype":"zip"}]},
+	{"field_id":9,"field_name":"Юр. лицо","field_type":"legal_entity","values":[
+		{"value":{"name":"ООО Ромашка","entity_type":1,"vat_id":"7701234567","kpp":"770101001"}}]},
+	{"field_id":10,"field_name":"utm_source","field_code":"UTM_SOURCE","field_type":"tracking_data","values":[{"value":"yandex"}]}
+]`
+
+func TestFieldValuesRoundTrip(t *testing.T) {
+	var fields []CustomFieldValue
+	if err := json.Unmarshal([]byte(amoCRMFieldsJSON), &fields); err != nil {
+		t.Fatalf("Ошибка при разборе JSON: %v", err)
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatalf("Ошибка при маршализации в JSON: %v", err)
+	}
+
+	var expected, actual interface{}
+	_ = json.Unmarshal([]byte(amoCRMFieldsJSON), &expected)
+	_ = json.Unmarshal(encoded, &actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("JSON после повторной маршализации отличается:\n%s", encoded)
+	}
+}
+
+func TestFieldValueBuilders(t *testing.T) {
+	entityType := 1
+	tests := []struct {
+		name     string
+		field    CustomFieldValue
+		expected string
+	}{
+		{"Текст", Field(1, Text("Директор")), `{"field_id":1,"values":[{"value":"Директор"}]}`},
+		{"Число", Field(3, Numeric(1500.5)), `{"field_id":3,"values":[{"value":1500.5}]}`},
+		{"Флажок", Field(7, Checkbox(false)), `{"field_id":7,"values":[{"value":false}]}`},
+		{"Ссылка", Field(11, URL("https://example.com")), `{"field_id":11,"values":[{"value":"https://example.com"}]}`},
+		{"Дата", Field(6, Date(time.Unix(1609459200, 0))), `{"field_id":6,"values":[{"value":1609459200}]}`},
+		{"Список", Field(4, Select(31)), `{"field_id":4,"values":[{"enum_id":31}]}`},
+		{"Мультисписок", Field(5, MultiSelect(41, 42)...), `{"field_id":5,"values":[{"enum_id":41},{"enum_id":42}]}`},
+		{
+			"Телефоны по коду",
+			FieldByCode("PHONE", Phone("+79001234567", PhoneWork), Phone("+79007654321", PhoneMobile)),
+			`{"field_code":"PHONE","values":[{"value":"+79001234567","enum_code":"WORK"},{"value":"+79007654321","enum_code":"MOB"}]}`,
+		},
+		{"Email", FieldByCode("EMAIL", Email("ivan@example.com", EmailPrivate)), `{"field_code":"EMAIL","values":[{"value":"ivan@example.com","enum_code":"PRIV"}]}`},
+		{
+			"Адрес",
+			Field(8, SmartAddress(Address{AddressLine1: "ул. Ленина, 1", City: "Москва"})...),
+			`{"field_id":8,"values":[{"value":"ул. Ленина, 1","subtype":"address_line_1"},{"value":"Москва","subtype":"city"}]}`,
+		},
+		{
+			"Юр. лицо",
+			Field(9, LegalEntity(LegalEntityValue{Name: "ООО Ромашка", EntityType: &entityType, VatID: "7701234567"})),
+			`{"field_id":9,"values":[{"value":{"name":"ООО Ромашка","entity_type":1,"vat_id":"7701234567"}}]}`,
+		},
+		{"Метка", FieldByCode("UTM_SOURCE", TrackingData("yandex")), `{"field_code":"UTM_SOURCE","values":[{"value":"yandex"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.field)
+			if err != nil {
+				t.Fatalf("Ошибка при маршализации в JSON: %v", err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("Неверный JSON:\n%s\nожидалось:\n%s", data, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFieldsGetters(t *testing.T) {
+	var values []CustomFieldValue
+	if err := json.Unmarshal([]byte(amoCRMFieldsJSON), &values); err != nil {
+		t.Fatalf("Ошибка при разборе JSON: %v", err)
+	}
+	fields := Fields(values)
+
+	if text, ok := fields.Text(ByCode("position")); !ok || text != "Директор" {
+		t.Errorf("Ожидалась должность Директор, получено %q", text)
+	}
+	if number, ok := fields.Numeric(ByID(3)); !ok || number != 1500.5 {
+		t.Errorf("Ожидался бюджет 1500.5, получено %v", number)
+	}
+	if date, ok := fields.Date(ByID(6)); !ok || date.Unix() != 1609459200 {
+		t.Errorf("Ожидалась дата 1609459200, получено %v", date)
+	}
+	if checked, ok := fields.Checkbox(ByID(7)); !ok || !checked {
+		t.Error("Ожидался отмеченный флажок")
+	}
+	if enum, ok := fields.Select(ByID(4)); !ok || enum.ID != 31 || enum.Value != "Сайт" {
+		t.Errorf("Ожидался вариант 31 Сайт, получено %+v", enum)
+	}
+	if enums := fields.MultiSelect(ByID(5)); len(enums) != 2 || enums[1].ID != 42 {
+		t.Errorf("Ожидались варианты 41 и 42, получено %+v", enums)
+	}
+
+	phones := fields.Multitext(ByCode("PHONE"))
+	if len(phones) != 2 || phones[1].Value != "+79007654321" || phones[1].EnumCode != PhoneMobile {
+		t.Errorf("Получены неверные телефоны: %+v", phones)
+	}
+
+	address, ok := fields.SmartAddress(ByID(8))
+	if !ok || address != (Address{AddressLine1: "ул. Ленина, 1", City: "Москва", Zip: "101000"}) {
+		t.Errorf("Получен неверный адрес: %+v", address)
+	}
+
+	entity, ok := fields.LegalEntity(ByID(9))
+	if !ok || entity.Name != "ООО Ромашка" || entity.KPP != "770101001" || entity.EntityType == nil || *entity.EntityType != 1 {
+		t.Errorf("Получено неверное юр. лицо: %+v", entity)
+	}
+
+	if _, ok := fields.Text(ByID(404)); ok {
+		t.Error("Не ожидалось значение для незаполненного поля")
+	}
+
+	// Set заменяет существующее поле и добавляет новое
+	fields = fields.Set(Field(1, Text("Менеджер")))
+	fields = fields.Set(Field(12, Text("Новое")))
+	if text, _ := fields.Text(ByID(1)); text != "Менеджер" || len(fields) != 11 {
+		t.Errorf("Ожидалась замена поля 1 и добавление поля 12, получено %q и %d полей", text, len(fields))
+	}
+}
@@ -0,0 +1,252 @@
+// Пакет dedup предоставляет инструменты для поиска и объединения дублей контактов в amoCRM.
+package dedup
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/entities/contacts"
+)
+
+// Коды системных мультиполей контакта
+const (
+	FieldCodePhone = "PHONE"
+	FieldCodeEmail = "EMAIL"
+)
+
+// Rule описывает правило сопоставления контактов.
+// Контакты, у которых совпадает хотя бы один ключ правила, считаются дублями.
+type Rule struct {
+	// Name - название правила, попадает в отчет о совпадениях
+	Name string
+	// Keys возвращает нормализованные ключи контакта для сравнения
+	Keys func(contact *contacts.Contact) []string
+}
+
+var (
+	// RulePhone сопоставляет контакты по нормализованному номеру телефона
+	RulePhone = Rule{Name: "phone", Keys: Phones}
+	// RuleEmail сопоставляет контакты по адресу электронной почты
+	RuleEmail = Rule{Name: "email", Keys: Emails}
+	// RuleName сопоставляет контакты по имени без учета регистра и лишних пробелов
+	RuleName = Rule{Name: "name", Keys: nameKeys}
+)
+
+// DefaultRules - правила, используемые FindDuplicates, если правила не заданы.
+var DefaultRules = []Rule{RulePhone, RuleEmail}
+
+// AllOf объединяет правила: контакты совпадают, только если совпали ключи всех правил.
+//
+// Пример использования:
+//
+//	// Дубли - контакты с одинаковым именем и телефоном
+//	rule := dedup.AllOf(dedup.RuleName, dedup.RulePhone)
+func AllOf(rules ...Rule) Rule {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+
+	return Rule{
+		Name: strings.Join(names, "+"),
+		Keys: func(contact *contacts.Contact) []string {
+			keys := []string{""}
+			for i, rule := range rules {
+				ruleKeys := rule.Keys(contact)
+				if len(ruleKeys) == 0 {
+					return nil
+				}
+
+				combined := make([]string, 0, len(keys)*len(ruleKeys))
+				for _, prefix := range keys {
+					for _, key := range ruleKeys {
+						if i > 0 {
+							key = prefix + "|" + key
+						}
+						combined = append(combined, key)
+					}
+				}
+				keys = combined
+			}
+			return keys
+		},
+	}
+}
+
+// Phones возвращает нормализованные номера телефонов контакта без повторов.
+func Phones(contact *contacts.Contact) []string {
+	return fieldKeys(contact, FieldCodePhone, contacts.NormalizePhone)
+}
+
+// Emails возвращает нормализованные адреса электронной почты контакта без повторов.
+func Emails(contact *contacts.Contact) []string {
+	return fieldKeys(contact, FieldCodeEmail, contacts.NormalizeEmail)
+}
+
+// nameKeys возвращает имя контакта в нормализованном виде.
+func nameKeys(contact *contacts.Contact) []string {
+	name := strings.Join(strings.Fields(strings.ToLower(contact.Name)), " ")
+	if name == "" {
+		return nil
+	}
+	return []string{name}
+}
+
+// fieldKeys собирает нормализованные значения мультиполя с указанным кодом.
+func fieldKeys(contact *contacts.Contact, code string, normalize func(string) string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for _, field := range contact.CustomFieldsValues {
+		if !strings.EqualFold(field.FieldCode, code) {
+			continue
+		}
+		for _, value := range field.Values {
+			raw, ok := value.Value.(string)
+			if !ok {
+				continue
+			}
+			key := normalize(raw)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// Match описывает совпадение, по которому контакты попали в один кластер.
+type Match struct {
+	Rule       string `json:"rule"`
+	Key        string `json:"key"`
+	ContactIDs []int  `json:"contact_ids"`
+}
+
+// Cluster - группа контактов, которые считаются дублями друг друга.
+type Cluster struct {
+	Contacts []contacts.Contact `json:"contacts"`
+	Matches  []Match            `json:"matches"`
+}
+
+// IDs возвращает ID контактов кластера.
+func (c Cluster) IDs() []int {
+	ids := make([]int, 0, len(c.Contacts))
+	for _, contact := range c.Contacts {
+		ids = append(ids, contact.ID)
+	}
+	return ids
+}
+
+// FindDuplicates группирует контакты в кластеры дублей по заданным правилам.
+// Совпадения транзитивны: если A совпадает с B по телефону, а B с C по email,
+// все три контакта попадут в один кластер. Контакты без дублей в результат не входят.
+// Если правила не указаны, используются DefaultRules.
+func FindDuplicates(list []contacts.Contact, rules ...Rule) []Cluster {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+
+	parent := make([]int, len(list))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type ruleKey struct {
+		rule int
+		key  string
+	}
+	groups := make(map[ruleKey][]int)
+	var order []ruleKey
+
+	for i := range list {
+		for r, rule := range rules {
+			for _, key := range rule.Keys(&list[i]) {
+				rk := ruleKey{rule: r, key: key}
+				if _, ok := groups[rk]; !ok {
+					order = append(order, rk)
+				}
+				groups[rk] = append(groups[rk], i)
+			}
+		}
+	}
+
+	// Объединяем контакты с совпавшими ключами
+	matchesByRoot := make(map[int][]Match)
+	var matchGroups [][]int
+	var matchList []Match
+	for _, rk := range order {
+		indexes := groups[rk]
+		if len(indexes) < 2 {
+			continue
+		}
+		for _, idx := range indexes[1:] {
+			parent[find(idx)] = find(indexes[0])
+		}
+
+		ids := make([]int, 0, len(indexes))
+		for _, idx := range indexes {
+			ids = append(ids, list[idx].ID)
+		}
+		sort.Ints(ids)
+		matchGroups = append(matchGroups, indexes)
+		matchList = append(matchList, Match{Rule: rules[rk.rule].Name, Key: rk.key, ContactIDs: ids})
+	}
+	for i, indexes := range matchGroups {
+		root := find(indexes[0])
+		matchesByRoot[root] = append(matchesByRoot[root], matchList[i])
+	}
+
+	// Собираем кластеры
+	members := make(map[int][]contacts.Contact)
+	for i := range list {
+		root := find(i)
+		if _, ok := matchesByRoot[root]; ok {
+			members[root] = append(members[root], list[i])
+		}
+	}
+
+	clusters := make([]Cluster, 0, len(members))
+	for root, clusterContacts := range members {
+		sort.Slice(clusterContacts, func(i, j int) bool { return clusterContacts[i].ID < clusterContacts[j].ID })
+		matches := matchesByRoot[root]
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].Rule != matches[j].Rule {
+				return matches[i].Rule < matches[j].Rule
+			}
+			return matches[i].Key < matches[j].Key
+		})
+		clusters = append(clusters, Cluster{Contacts: clusterContacts, Matches: matches})
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Contacts[0].ID < clusters[j].Contacts[0].ID })
+
+	return clusters
+}
+
+// Report формирует текстовый отчет о найденных кластерах дублей.
+func Report(clusters []Cluster) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Найдено групп дублей: %d\n", len(clusters))
+
+	for i, cluster := range clusters {
+		fmt.Fprintf(&b, "\nГруппа %d:\n", i+1)
+		for _, contact := range cluster.Contacts {
+			fmt.Fprintf(&b, "  #%d %s\n", contact.ID, contact.Name)
+		}
+		for _, match := range cluster.Matches {
+			fmt.Fprintf(&b, "  совпадение %s=%s: %v\n", match.Rule, match.Key, match.ContactIDs)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,252 @@
+package dedup
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/contacts"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+)
+
+// newContact создает контакт с телефонами и email для тестов
+func newContact(id int, name string, createdAt int64, phones, emails []string) contacts.Contact {
+	contact := contacts.Contact{ID: id, Name: name, CreatedAt: createdAt}
+	if len(phones) > 0 {
+		field := custom_fields.CustomFieldValue{FieldID: 1, FieldCode: FieldCodePhone}
+		for _, phone := range phones {
+			field.Values = append(field.Values, custom_fields.FieldValue{Value: phone, EnumCode: "WORK"})
+		}
+		contact.CustomFieldsValues = append(contact.CustomFieldsValues, field)
+	}
+	if len(emails) > 0 {
+		field := custom_fields.CustomFieldValue{FieldID: 2, FieldCode: FieldCodeEmail}
+		for _, email := range emails {
+			field.Values = append(field.Values, custom_fields.FieldValue{Value: email, EnumCode: "WORK"})
+		}
+		contact.CustomFieldsValues = append(contact.CustomFieldsValues, field)
+	}
+	return contact
+}
+
+func TestFindDuplicates(t *testing.T) {
+	list := []contacts.Contact{
+		newContact(1, "Иван Иванов", 100, []string{"+7 (900) 123-45-67"}, nil),
+		newContact(2, "Иван", 200, []string{"89001234567"}, []string{"ivan@example.com"}),
+		newContact(3, "И. Иванов", 300, nil, []string{" IVAN@example.com"}),
+		newContact(4, "Петр Петров", 400, []string{"+79990000000"}, []string{"petr@example.com"}),
+		newContact(5, "иван  иванов", 500, nil, nil),
+	}
+
+	t.Run("Правила по умолчанию", func(t *testing.T) {
+		clusters := FindDuplicates(list)
+
+		if len(clusters) != 1 {
+			t.Fatalf("Ожидался 1 кластер, получено %d", len(clusters))
+		}
+
+		// Контакт 3 попадает в кластер транзитивно через email контакта 2
+		ids := clusters[0].IDs()
+		if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+			t.Errorf("Ожидались контакты [1 2 3], получено %v", ids)
+		}
+
+		matches := clusters[0].Matches
+		if len(matches) != 2 {
+			t.Fatalf("Ожидалось 2 совпадения, получено %d", len(matches))
+		}
+		if matches[0].Rule != "email" || matches[0].Key != "ivan@example.com" {
+			t.Errorf("Ожидалось совпадение email=ivan@example.com, получено %s=%s", matches[0].Rule, matches[0].Key)
+		}
+		if matches[1].Rule != "phone" || matches[1].Key != "79001234567" {
+			t.Errorf("Ожидалось совпадение phone=79001234567, получено %s=%s", matches[1].Rule, matches[1].Key)
+		}
+	})
+
+	t.Run("Составное правило", func(t *testing.T) {
+		clusters := FindDuplicates(list, AllOf(RuleName, RulePhone))
+		if len(clusters) != 0 {
+			t.Errorf("Ожидалось отсутствие кластеров, получено %d", len(clusters))
+		}
+
+		clusters = FindDuplicates(list, RuleName)
+		if len(clusters) != 1 || len(clusters[0].Contacts) != 2 {
+			t.Fatalf("Ожидался 1 кластер из 2 контактов, получено %v", clusters)
+		}
+		if clusters[0].Matches[0].Rule != "name" || clusters[0].Matches[0].Key != "иван иванов" {
+			t.Errorf("Ожидалось совпадение name=иван иванов, получено %v", clusters[0].Matches[0])
+		}
+	})
+
+	t.Run("Отчет", func(t *testing.T) {
+		report := Report(FindDuplicates(list))
+		if !strings.Contains(report, "Найдено групп дублей: 1") || !strings.Contains(report, "#3 И. Иванов") {
+			t.Errorf("Отчет не содержит ожидаемых строк: %s", report)
+		}
+	})
+}
+
+// setupMergeTestServer создает тестовый сервер amoCRM для объединения контактов 10 и 20.
+// Все изменяющие запросы записываются в writes.
+func setupMergeTestServer(t *testing.T, writes *[]string) *httptest.Server {
+	// Задачи, привязанные к дублю; перенесенные задачи пропадают из выборки
+	duplicateTasks := map[int]bool{600: true, 601: true}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			body, _ := ioutil.ReadAll(r.Body)
+			*writes = append(*writes, r.Method+" "+r.URL.Path+" "+string(body))
+		}
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v4/contacts/10":
+			_, _ = w.Write([]byte(`{"id":10,"name":"Иван","custom_fields_values":[
+				{"field_id":1,"field_code":"PHONE","values":[{"value":"+79001234567","enum_code":"WORK"}]}],
+				"_embedded":{"leads":[{"id":100}]}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v4/contacts/20":
+			_, _ = w.Write([]byte(`{"id":20,"name":"Иван Иванов","custom_fields_values":[
+				{"field_id":1,"field_code":"PHONE","values":[{"value":"89001234567","enum_code":"MOB"}]},
+				{"field_id":2,"field_code":"EMAIL","values":[{"value":"ivan@example.com","enum_code":"WORK"}]}],
+				"_embedded":{"leads":[{"id":100},{"id":200}],"companies":[{"id":300,"name":"ООО Ромашка"}]}}`))
+		case r.URL.Path == "/api/v4/contacts/10/tags":
+			_, _ = w.Write([]byte(`{"_embedded":{"tags":[{"id":1,"name":"vip"}]}}`))
+		case r.URL.Path == "/api/v4/contacts/20/tags":
+			_, _ = w.Write([]byte(`{"_embedded":{"tags":[{"id":1,"name":"vip"},{"id":2,"name":"сайт"}]}}`))
+		case r.URL.Path == "/api/v4/contacts/20/notes":
+			_, _ = w.Write([]byte(`{"_embedded":{"notes":[{"id":500,"note_type":"common","params":{"text":"Перезвонить"}}]}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v4/tasks":
+			query := r.URL.Query()
+			if query.Get("filter[entity_type]") != "contacts" || query.Get("filter[entity_id][]") != "20" {
+				t.Errorf("Неверный фильтр задач: %s", r.URL.RawQuery)
+			}
+			var items []string
+			for _, id := range []int{600, 601} {
+				if duplicateTasks[id] {
+					items = append(items, fmt.Sprintf(`{"id":%d,"entity_type":"contacts","entity_id":20}`, id))
+				}
+			}
+			if len(items) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = w.Write([]byte(`{"_embedded":{"tasks":[` + strings.Join(items, ",") + `]}}`))
+		case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/api/v4/tasks/"):
+			id, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/v4/tasks/"))
+			delete(duplicateTasks, id)
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+}
+
+func TestMerge(t *testing.T) {
+	cluster := Cluster{Contacts: []contacts.Contact{
+		{ID: 10, CreatedAt: 100},
+		{ID: 20, CreatedAt: 200},
+	}}
+
+	t.Run("Объединение", func(t *testing.T) {
+		var writes []string
+		server := setupMergeTestServer(t, &writes)
+		defer server.Close()
+
+		apiClient := client.NewClient(server.URL, "test_api_key")
+
+		log, err := Merge(apiClient, cluster, nil)
+		if err != nil {
+			t.Fatalf("Ошибка при объединении: %v", err)
+		}
+
+		if log.SurvivorID != 10 || len(log.MergedIDs) != 1 || log.MergedIDs[0] != 20 {
+			t.Errorf("Ожидался основной контакт 10 и объединенный 20, получено %d и %v", log.SurvivorID, log.MergedIDs)
+		}
+
+		counts := make(map[AuditAction]int)
+		for _, entry := range log.Entries {
+			counts[entry.Action]++
+			if entry.FromContactID != 20 || entry.ToContactID != 10 {
+				t.Errorf("Неверное направление переноса в записи %+v", entry)
+			}
+		}
+		expected := map[AuditAction]int{
+			AuditLeadMoved:       2,
+			AuditCompanyMoved:    1,
+			AuditNoteCopied:      1,
+			AuditTaskMoved:       2,
+			AuditTagAdded:        1,
+			AuditFieldValueAdded: 1,
+		}
+		for action, count := range expected {
+			if counts[action] != count {
+				t.Errorf("Ожидалось %d записей %s, получено %d", count, action, counts[action])
+			}
+		}
+
+		// Сделка 100 уже привязана к основному контакту, поэтому только отвязывается от дубля
+		joined := strings.Join(writes, "\n")
+		for _, expectedWrite := range []string{
+			"POST /api/v4/contacts/10/link {\"to\":[{\"entity_id\":200,\"entity_type\":\"leads\"}]}",
+			"POST /api/v4/contacts/20/unlink {\"to\":[{\"entity_id\":100,\"entity_type\":\"leads\"}]}",
+			"POST /api/v4/contacts/10/link {\"to\":[{\"entity_id\":300,\"entity_type\":\"companies\"}]}",
+			"PATCH /api/v4/tasks/600",
+			"PATCH /api/v4/tasks/601",
+			"POST /api/v4/contacts/10/notes",
+			"POST /api/v4/contacts/10/tags [{\"id\":2,\"name\":\"сайт\"}]",
+		} {
+			if !strings.Contains(joined, expectedWrite) {
+				t.Errorf("Ожидался запрос %s, выполнены:\n%s", expectedWrite, joined)
+			}
+		}
+		if strings.Contains(joined, "/api/v4/contacts/10/link {\"to\":[{\"entity_id\":100") {
+			t.Errorf("Сделка 100 не должна привязываться повторно")
+		}
+
+		// Телефон дубля совпадает с телефоном основного контакта, добавляется только email
+		last := writes[len(writes)-1]
+		if !strings.HasPrefix(last, "PATCH /api/v4/contacts/10 ") {
+			t.Fatalf("Ожидалось обновление основного контакта последним запросом, получено %s", last)
+		}
+		var updated contacts.Contact
+		if err := json.Unmarshal([]byte(strings.SplitN(last, " ", 3)[2]), &updated); err != nil {
+			t.Fatalf("Ошибка при разборе обновления контакта: %v", err)
+		}
+		if len(updated.CustomFieldsValues) != 2 || len(updated.CustomFieldsValues[0].Values) != 1 {
+			t.Errorf("Ожидались телефон и добавленный email, получено %+v", updated.CustomFieldsValues)
+		}
+	})
+
+	t.Run("Пробный запуск", func(t *testing.T) {
+		var writes []string
+		server := setupMergeTestServer(t, &writes)
+		defer server.Close()
+
+		apiClient := client.NewClient(server.URL, "test_api_key")
+
+		log, err := Merge(apiClient, cluster, &MergeOptions{DryRun: true, Survivor: LatestUpdatedSurvivor})
+		if err != nil {
+			t.Fatalf("Ошибка при объединении: %v", err)
+		}
+
+		if len(writes) != 0 {
+			t.Errorf("В режиме DryRun не должно быть изменяющих запросов, выполнены: %v", writes)
+		}
+		if !log.DryRun || len(log.Entries) != 8 {
+			t.Errorf("Ожидался журнал пробного запуска из 8 записей, получено %d", len(log.Entries))
+		}
+	})
+
+	t.Run("Недостаточно контактов", func(t *testing.T) {
+		apiClient := client.NewClient("http://localhost", "test_api_key")
+		if _, err := Merge(apiClient, Cluster{Contacts: cluster.Contacts[:1]}, nil); err == nil {
+			t.Error("Ожидалась ошибка, но ее не было")
+		}
+	})
+}
@@ -0,0 +1,408 @@
+package dedup
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/contacts"
+	"github.com/chudno/amo_crm_sdk/entities/notes"
+	"github.com/chudno/amo_crm_sdk/entities/tags"
+	"github.com/chudno/amo_crm_sdk/entities/tasks"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+)
+
+// pageLimit - максимальный размер страницы в API amoCRM
+const pageLimit = 250
+
+// SurvivorStrategy выбирает из кластера контакт, который останется после объединения.
+type SurvivorStrategy func(candidates []contacts.Contact) contacts.Contact
+
+// OldestSurvivor выбирает контакт, созданный раньше остальных.
+func OldestSurvivor(candidates []contacts.Contact) contacts.Contact {
+	survivor := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.CreatedAt < survivor.CreatedAt ||
+			(candidate.CreatedAt == survivor.CreatedAt && candidate.ID < survivor.ID) {
+			survivor = candidate
+		}
+	}
+	return survivor
+}
+
+// LatestUpdatedSurvivor выбирает контакт, измененный позже остальных.
+func LatestUpdatedSurvivor(candidates []contacts.Contact) contacts.Contact {
+	survivor := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.UpdatedAt > survivor.UpdatedAt ||
+			(candidate.UpdatedAt == survivor.UpdatedAt && candidate.ID < survivor.ID) {
+			survivor = candidate
+		}
+	}
+	return survivor
+}
+
+// MergeOptions задает параметры объединения дублей.
+type MergeOptions struct {
+	// Survivor - стратегия выбора основного контакта, по умолчанию OldestSurvivor
+	Survivor SurvivorStrategy
+	// DryRun - только сформировать журнал изменений, не изменяя данные в amoCRM
+	DryRun bool
+}
+
+// AuditAction тип действия в журнале объединения.
+type AuditAction string
+
+const (
+	// AuditLeadMoved сделка перенесена на основной контакт
+	AuditLeadMoved AuditAction = "lead_moved"
+	// AuditCompanyMoved компания перенесена на основной контакт
+	AuditCompanyMoved AuditAction = "company_moved"
+	// AuditNoteCopied примечание скопировано на основной контакт
+	AuditNoteCopied AuditAction = "note_copied"
+	// AuditTaskMoved задача перенесена на основной контакт
+	AuditTaskMoved AuditAction = "task_moved"
+	// AuditTagAdded тег дубля добавлен основному контакту
+	AuditTagAdded AuditAction = "tag_added"
+	// AuditFieldValueAdded телефон или email дубля добавлен основному контакту
+	AuditFieldValueAdded AuditAction = "field_value_added"
+)
+
+// AuditEntry запись журнала объединения.
+type AuditEntry struct {
+	Action        AuditAction `json:"action"`
+	FromContactID int         `json:"from_contact_id"`
+	ToContactID   int         `json:"to_contact_id"`
+	EntityID      int         `json:"entity_id,omitempty"`
+	Value         string      `json:"value,omitempty"`
+}
+
+// AuditLog журнал объединения кластера дублей.
+type AuditLog struct {
+	SurvivorID int          `json:"survivor_id"`
+	MergedIDs  []int        `json:"merged_ids"`
+	DryRun     bool         `json:"dry_run"`
+	Entries    []AuditEntry `json:"entries"`
+}
+
+// add добавляет запись в журнал.
+func (l *AuditLog) add(action AuditAction, fromID, entityID int, value string) {
+	l.Entries = append(l.Entries, AuditEntry{
+		Action:        action,
+		FromContactID: fromID,
+		ToContactID:   l.SurvivorID,
+		EntityID:      entityID,
+		Value:         value,
+	})
+}
+
+// Merge объединяет кластер дублей: выбирает основной контакт и переносит на него
+// сделки, компании, примечания, задачи, теги, телефоны и email остальных контактов.
+// Сделки и компании отвязываются от дублей, сами дубли не удаляются.
+//
+// При ошибке возвращается журнал уже выполненных изменений вместе с ошибкой.
+//
+// Пример использования:
+//
+//	clusters := dedup.FindDuplicates(contactsList)
+//	for _, cluster := range clusters {
+//		log, err := dedup.Merge(apiClient, cluster, &dedup.MergeOptions{DryRun: true})
+//		...
+//	}
+func Merge(apiClient *client.Client, cluster Cluster, options *MergeOptions) (*AuditLog, error) {
+	if len(cluster.Contacts) < 2 {
+		return nil, fmt.Errorf("в кластере должно быть не меньше двух контактов")
+	}
+	if options == nil {
+		options = &MergeOptions{}
+	}
+	strategy := options.Survivor
+	if strategy == nil {
+		strategy = OldestSurvivor
+	}
+
+	survivorID := strategy(cluster.Contacts).ID
+	log := &AuditLog{SurvivorID: survivorID, DryRun: options.DryRun}
+
+	survivor, err := contacts.GetContact(apiClient, survivorID, contacts.WithLeads, contacts.WithCompanies)
+	if err != nil {
+		return log, fmt.Errorf("ошибка при получении контакта %d: %w", survivorID, err)
+	}
+
+	m := &merger{
+		apiClient: apiClient,
+		dryRun:    options.DryRun,
+		log:       log,
+		survivor:  survivor,
+		leads:     make(map[int]bool),
+		companies: make(map[int]bool),
+		tags:      make(map[string]bool),
+	}
+	if survivor.Embedded != nil {
+		for _, lead := range survivor.Embedded.Leads {
+			m.leads[lead.ID] = true
+		}
+		for _, company := range survivor.Embedded.Companies {
+			m.companies[company.ID] = true
+		}
+	}
+	survivorTags, err := tags.GetEntityTags(apiClient, tags.EntityTypeContact, survivorID)
+	if err != nil {
+		return log, fmt.Errorf("ошибка при получении тегов контакта %d: %w", survivorID, err)
+	}
+	for _, tag := range survivorTags {
+		m.tags[tag.Name] = true
+	}
+
+	for _, candidate := range cluster.Contacts {
+		if candidate.ID == survivorID {
+			continue
+		}
+		if err := m.mergeDuplicate(candidate.ID); err != nil {
+			return log, err
+		}
+		log.MergedIDs = append(log.MergedIDs, candidate.ID)
+	}
+
+	if err := m.saveSurvivor(); err != nil {
+		return log, err
+	}
+
+	return log, nil
+}
+
+// merger хранит состояние объединения одного кластера.
+type merger struct {
+	apiClient     *client.Client
+	dryRun        bool
+	log           *AuditLog
+	survivor      *contacts.Contact
+	leads         map[int]bool
+	companies     map[int]bool
+	tags          map[string]bool
+	fieldsChanged bool
+}
+
+// mergeDuplicate переносит на основной контакт все данные одного дубля.
+func (m *merger) mergeDuplicate(duplicateID int) error {
+	duplicate, err := contacts.GetContact(m.apiClient, duplicateID, contacts.WithLeads, contacts.WithCompanies)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении контакта %d: %w", duplicateID, err)
+	}
+
+	if duplicate.Embedded != nil {
+		for _, lead := range duplicate.Embedded.Leads {
+			if err := m.moveLink(duplicateID, "leads", lead.ID, m.leads); err != nil {
+				return fmt.Errorf("ошибка при переносе сделки %d: %w", lead.ID, err)
+			}
+			m.log.add(AuditLeadMoved, duplicateID, lead.ID, "")
+		}
+		for _, company := range duplicate.Embedded.Companies {
+			if err := m.moveLink(duplicateID, "companies", company.ID, m.companies); err != nil {
+				return fmt.Errorf("ошибка при переносе компании %d: %w", company.ID, err)
+			}
+			m.log.add(AuditCompanyMoved, duplicateID, company.ID, company.Name)
+		}
+	}
+
+	if err := m.copyNotes(duplicateID); err != nil {
+		return err
+	}
+	if err := m.moveTasks(duplicateID); err != nil {
+		return err
+	}
+	if err := m.copyTags(duplicateID); err != nil {
+		return err
+	}
+
+	m.mergeFieldValues(duplicate)
+	return nil
+}
+
+// moveLink привязывает сущность к основному контакту и отвязывает от дубля.
+func (m *merger) moveLink(duplicateID int, entityType string, entityID int, linked map[int]bool) error {
+	if m.dryRun {
+		return nil
+	}
+	if !linked[entityID] {
+		if err := contacts.LinkContactWithEntity(m.apiClient, m.survivor.ID, entityType, entityID); err != nil {
+			return err
+		}
+		linked[entityID] = true
+	}
+	return contacts.UnlinkContactFromEntity(m.apiClient, duplicateID, entityType, entityID)
+}
+
+// copyNotes копирует примечания дубля на основной контакт.
+// API amoCRM не позволяет сменить сущность примечания, поэтому создаются копии.
+func (m *merger) copyNotes(duplicateID int) error {
+	for page := 1; ; page++ {
+		list, err := notes.ListNotes(m.apiClient, tasks.EntityTypeContact, duplicateID, pageLimit, page)
+		if err != nil {
+			return fmt.Errorf("ошибка при получении примечаний контакта %d: %w", duplicateID, err)
+		}
+
+		for _, note := range list {
+			if !m.dryRun {
+				copied := &notes.Note{
+					EntityID:   m.survivor.ID,
+					EntityType: tasks.EntityTypeContact,
+					NoteType:   note.NoteType,
+					Params:     note.Params,
+				}
+				if _, err := notes.CreateNote(m.apiClient, tasks.EntityTypeContact, m.survivor.ID, copied); err != nil {
+					return fmt.Errorf("ошибка при копировании примечания %d: %w", note.ID, err)
+				}
+			}
+			m.log.add(AuditNoteCopied, duplicateID, note.ID, "")
+		}
+
+		if len(list) < pageLimit {
+			return nil
+		}
+	}
+}
+
+// moveTasks перепривязывает задачи дубля к основному контакту.
+// Перенесенные задачи пропадают из выборки по дублю, поэтому при изменении данных
+// первая страница запрашивается повторно, пока задачи не закончатся.
+func (m *merger) moveTasks(duplicateID int) error {
+	filter := &tasks.TasksFilter{
+		EntityType: tasks.EntityTypeContact,
+		EntityIDs:  []int{duplicateID},
+	}
+
+	moved := make(map[int]bool)
+	for page := 1; ; {
+		list, err := tasks.FindTasks(m.apiClient, page, pageLimit, filter)
+		if err != nil {
+			return fmt.Errorf("ошибка при получении задач контакта %d: %w", duplicateID, err)
+		}
+
+		for _, task := range list {
+			if !m.dryRun {
+				// Задача, которая вернулась повторно, не была перенесена
+				if moved[task.ID] {
+					return fmt.Errorf("задача %d осталась привязана к контакту %d после переноса", task.ID, duplicateID)
+				}
+				moved[task.ID] = true
+
+				update := &tasks.Task{
+					ID:         task.ID,
+					EntityType: tasks.EntityTypeContact,
+					EntityID:   m.survivor.ID,
+				}
+				if _, err := tasks.UpdateTask(m.apiClient, update); err != nil {
+					return fmt.Errorf("ошибка при переносе задачи %d: %w", task.ID, err)
+				}
+			}
+			m.log.add(AuditTaskMoved, duplicateID, task.ID, "")
+		}
+
+		if !m.dryRun {
+			if len(list) == 0 {
+				return nil
+			}
+			continue
+		}
+		if len(list) < pageLimit {
+			return nil
+		}
+		page++
+	}
+}
+
+// copyTags добавляет основному контакту теги дубля, которых у него еще нет.
+func (m *merger) copyTags(duplicateID int) error {
+	duplicateTags, err := tags.GetEntityTags(m.apiClient, tags.EntityTypeContact, duplicateID)
+	if err != nil {
+		return fmt.Errorf("ошибка при получении тегов контакта %d: %w", duplicateID, err)
+	}
+
+	var missing []tags.Tag
+	for _, tag := range duplicateTags {
+		if m.tags[tag.Name] {
+			continue
+		}
+		m.tags[tag.Name] = true
+		missing = append(missing, tag)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if !m.dryRun {
+		if err := tags.LinkEntityWithTags(m.apiClient, tags.EntityTypeContact, m.survivor.ID, missing); err != nil {
+			return fmt.Errorf("ошибка при добавлении тегов контакту %d: %w", m.survivor.ID, err)
+		}
+	}
+	for _, tag := range missing {
+		m.log.add(AuditTagAdded, duplicateID, tag.ID, tag.Name)
+	}
+	return nil
+}
+
+// mergeFieldValues добавляет основному контакту телефоны и email дубля.
+func (m *merger) mergeFieldValues(duplicate *contacts.Contact) {
+	normalizers := map[string]func(string) string{
+		FieldCodePhone: contacts.NormalizePhone,
+		FieldCodeEmail: contacts.NormalizeEmail,
+	}
+
+	for _, code := range []string{FieldCodePhone, FieldCodeEmail} {
+		normalize := normalizers[code]
+		known := make(map[string]bool)
+		for _, key := range fieldKeys(m.survivor, code, normalize) {
+			known[key] = true
+		}
+
+		for _, field := range duplicate.CustomFieldsValues {
+			if !strings.EqualFold(field.FieldCode, code) {
+				continue
+			}
+			for _, value := range field.Values {
+				raw, ok := value.Value.(string)
+				if !ok || normalize(raw) == "" || known[normalize(raw)] {
+					continue
+				}
+				known[normalize(raw)] = true
+				m.appendFieldValue(field, value)
+				m.log.add(AuditFieldValueAdded, duplicate.ID, field.FieldID, raw)
+			}
+		}
+	}
+}
+
+// appendFieldValue добавляет значение в поле основного контакта, создавая поле при необходимости.
+func (m *merger) appendFieldValue(field custom_fields.CustomFieldValue, value custom_fields.FieldValue) {
+	m.fieldsChanged = true
+	for i := range m.survivor.CustomFieldsValues {
+		if m.survivor.CustomFieldsValues[i].FieldID == field.FieldID {
+			m.survivor.CustomFieldsValues[i].Values = append(m.survivor.CustomFieldsValues[i].Values, value)
+			return
+		}
+	}
+
+	m.survivor.CustomFieldsValues = append(m.survivor.CustomFieldsValues, custom_fields.CustomFieldValue{
+		FieldID:   field.FieldID,
+		FieldCode: field.FieldCode,
+		Values:    []custom_fields.FieldValue{value},
+	})
+}
+
+// saveSurvivor сохраняет добавленные телефоны и email основного контакта.
+func (m *merger) saveSurvivor() error {
+	if !m.fieldsChanged || m.dryRun {
+		return nil
+	}
+
+	update := &contacts.Contact{
+		ID:                 m.survivor.ID,
+		Name:               m.survivor.Name,
+		CustomFieldsValues: m.survivor.CustomFieldsValues,
+	}
+	if _, err := contacts.UpdateContact(m.apiClient, update); err != nil {
+		return fmt.Errorf("ошибка при обновлении контакта %d: %w", m.survivor.ID, err)
+	}
+	return nil
+}
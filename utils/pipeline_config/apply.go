@@ -0,0 +1,96 @@
+package pipeline_config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/pipelines"
+)
+
+// ApplyOptions задает параметры применения плана.
+type ApplyOptions struct {
+	// AllowDeleteWithLeads разрешает удалять воронки и этапы, в которых есть сделки.
+	// amoCRM переносит сделки удаляемого этапа в первый этап воронки.
+	AllowDeleteWithLeads bool
+}
+
+// Apply выполняет изменения плана: создает воронки и этапы, изменяет их и удаляет лишние.
+// Если в удаляемых воронках или этапах есть сделки и это не разрешено параметрами,
+// план не применяется целиком. Повторное построение плана после применения дает пустой план.
+func (p *Plan) Apply(apiClient *client.Client, options ApplyOptions) error {
+	if blocked := p.BlockedDeletes(); len(blocked) > 0 && !options.AllowDeleteWithLeads {
+		var names []string
+		for _, change := range blocked {
+			names = append(names, change.String())
+		}
+		return fmt.Errorf("удаление отменено, в воронках или этапах есть сделки:\n%s", strings.Join(names, "\n"))
+	}
+
+	// Новые воронки создаются одним запросом вместе с этапами
+	var newPipelines []pipelines.Pipeline
+	for _, change := range p.Changes {
+		if change.Action == ActionCreate && change.Status == "" {
+			newPipelines = append(newPipelines, change.pipeline)
+		}
+	}
+	if len(newPipelines) > 0 {
+		if _, err := pipelines.CreatePipelines(apiClient, newPipelines); err != nil {
+			return fmt.Errorf("не удалось создать воронки: %w", err)
+		}
+	}
+
+	// Новые этапы существующих воронок создаются до удаления старых,
+	// чтобы в воронке всегда оставался хотя бы один этап
+	var pipelineOrder []int
+	newStatuses := make(map[int][]pipelines.Status)
+	for _, change := range p.Changes {
+		if change.Action == ActionCreate && change.Status != "" {
+			if _, ok := newStatuses[change.PipelineID]; !ok {
+				pipelineOrder = append(pipelineOrder, change.PipelineID)
+			}
+			newStatuses[change.PipelineID] = append(newStatuses[change.PipelineID], change.status)
+		}
+	}
+	for _, pipelineID := range pipelineOrder {
+		if _, err := pipelines.CreateStatuses(apiClient, pipelineID, newStatuses[pipelineID]); err != nil {
+			return fmt.Errorf("не удалось создать этапы воронки %d: %w", pipelineID, err)
+		}
+	}
+
+	for _, change := range p.Changes {
+		if change.Action != ActionUpdate {
+			continue
+		}
+
+		var err error
+		if change.Status == "" {
+			pipeline := change.pipeline
+			_, err = pipelines.UpdatePipeline(apiClient, &pipeline)
+		} else {
+			status := change.status
+			_, err = pipelines.UpdateStatus(apiClient, change.PipelineID, &status)
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось выполнить изменение %s: %w", change, err)
+		}
+	}
+
+	// Этапы удаляются до воронок
+	for _, change := range p.Changes {
+		if change.Action == ActionDelete && change.Status != "" {
+			if err := pipelines.DeleteStatus(apiClient, change.PipelineID, change.StatusID); err != nil {
+				return fmt.Errorf("не удалось выполнить изменение %s: %w", change, err)
+			}
+		}
+	}
+	for _, change := range p.Changes {
+		if change.Action == ActionDelete && change.Status == "" {
+			if err := pipelines.DeletePipeline(apiClient, change.PipelineID); err != nil {
+				return fmt.Errorf("не удалось выполнить изменение %s: %w", change, err)
+			}
+		}
+	}
+
+	return nil
+}
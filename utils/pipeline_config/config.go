@@ -0,0 +1,130 @@
+// Пакет pipeline_config позволяет описать воронки и этапы в файле JSON или YAML
+// и привести к этому описанию воронки аккаунта amoCRM.
+package pipeline_config
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config описывает желаемые воронки аккаунта.
+type Config struct {
+	Pipelines []Pipeline `json:"pipelines" yaml:"pipelines"`
+	// DeleteUnlisted включает удаление воронок аккаунта, которых нет в описании
+	DeleteUnlisted bool `json:"delete_unlisted" yaml:"delete_unlisted"`
+}
+
+// Pipeline описывает воронку. Воронки сопоставляются с воронками аккаунта по названию.
+type Pipeline struct {
+	Name string `json:"name" yaml:"name"`
+	// Sort - порядок сортировки, по умолчанию определяется позицией воронки в описании
+	Sort     int      `json:"sort" yaml:"sort"`
+	IsMain   bool     `json:"is_main" yaml:"is_main"`
+	Statuses []Status `json:"statuses" yaml:"statuses"`
+}
+
+// Status описывает этап воронки. Этапы сопоставляются с этапами воронки по названию.
+// Системные этапы "Неразобранное", "Успешно реализовано" и "Закрыто и не реализовано" не описываются.
+type Status struct {
+	Name string `json:"name" yaml:"name"`
+	// Color - цвет этапа, если не указан, цвет не изменяется
+	Color string `json:"color" yaml:"color"`
+	// Sort - порядок сортировки, по умолчанию определяется позицией этапа в описании
+	Sort int `json:"sort" yaml:"sort"`
+}
+
+// sortStep - шаг сортировки для воронок и этапов без явно указанного порядка
+const sortStep = 10
+
+// ReadConfig читает описание воронок в формате YAML или JSON и проверяет его.
+func ReadConfig(r io.Reader) (*Config, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON является подмножеством YAML, поэтому оба формата читаются одним декодером
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var config Config
+	if err := decoder.Decode(&config); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("ошибка разбора описания воронок: %v", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// LoadConfig читает описание воронок из файла .json, .yaml или .yml.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ReadConfig(bytes.NewReader(data))
+}
+
+// Validate проверяет, что названия воронок и этапов заполнены и не повторяются.
+func (c *Config) Validate() error {
+	pipelineNames := make(map[string]bool)
+	mainCount := 0
+
+	for _, pipeline := range c.Pipelines {
+		name := strings.TrimSpace(pipeline.Name)
+		if name == "" {
+			return fmt.Errorf("не указано название воронки")
+		}
+		if pipelineNames[name] {
+			return fmt.Errorf("воронка %q описана несколько раз", name)
+		}
+		pipelineNames[name] = true
+
+		if pipeline.IsMain {
+			mainCount++
+		}
+		if len(pipeline.Statuses) == 0 {
+			return fmt.Errorf("воронка %q: не описано ни одного этапа", name)
+		}
+
+		statusNames := make(map[string]bool)
+		for _, status := range pipeline.Statuses {
+			statusName := strings.TrimSpace(status.Name)
+			if statusName == "" {
+				return fmt.Errorf("воронка %q: не указано название этапа", name)
+			}
+			if statusNames[statusName] {
+				return fmt.Errorf("воронка %q: этап %q описан несколько раз", name, statusName)
+			}
+			statusNames[statusName] = true
+		}
+	}
+
+	if mainCount > 1 {
+		return fmt.Errorf("главной может быть только одна воронка")
+	}
+	return nil
+}
+
+// pipelineSort возвращает порядок сортировки воронки с учетом позиции в описании
+func (c *Config) pipelineSort(index int) int {
+	if c.Pipelines[index].Sort != 0 {
+		return c.Pipelines[index].Sort
+	}
+	return (index + 1) * sortStep
+}
+
+// statusSort возвращает порядок сортировки этапа с учетом позиции в описании
+func (p *Pipeline) statusSort(index int) int {
+	if p.Statuses[index].Sort != 0 {
+		return p.Statuses[index].Sort
+	}
+	return (index + 1) * sortStep
+}
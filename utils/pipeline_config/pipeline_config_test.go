@@ -0,0 +1,283 @@
+package pipeline_config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/pipelines"
+)
+
+const testConfigYAML = `
+pipelines:
+  - name: Продажи
+    is_main: true
+    statuses:
+      - name: Первичный контакт
+        color: "#99ccff"
+      - name: Переговоры
+        color: "#ffcc66"
+  - name: Партнеры
+    sort: 50
+    statuses:
+      - name: Заявка
+`
+
+const testConfigJSON = `{"pipelines": [
+	{"name": "Продажи", "is_main": true, "statuses": [
+		{"name": "Первичный контакт", "color": "#99ccff"},
+		{"name": "Переговоры", "color": "#ffcc66"}]},
+	{"name": "Партнеры", "sort": 50, "statuses": [{"name": "Заявка"}]}
+]}`
+
+func TestReadConfig(t *testing.T) {
+	fromYAML, err := ReadConfig(strings.NewReader(testConfigYAML))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении YAML: %v", err)
+	}
+	fromJSON, err := ReadConfig(strings.NewReader(testConfigJSON))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении JSON: %v", err)
+	}
+	if fmt.Sprintf("%+v", fromYAML) != fmt.Sprintf("%+v", fromJSON) {
+		t.Errorf("Описания в YAML и JSON различаются:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+
+	invalid := map[string]string{
+		"Неизвестное поле":   "pipelines:\n  - name: A\n    colour: red\n",
+		"Повтор воронки":     "pipelines:\n  - {name: A, statuses: [{name: X}]}\n  - {name: A, statuses: [{name: X}]}\n",
+		"Повтор этапа":       "pipelines:\n  - {name: A, statuses: [{name: X}, {name: X}]}\n",
+		"Воронка без этапов": "pipelines:\n  - {name: A}\n",
+		"Две главные":        "pipelines:\n  - {name: A, is_main: true, statuses: [{name: X}]}\n  - {name: B, is_main: true, statuses: [{name: X}]}\n",
+	}
+	for name, config := range invalid {
+		if _, err := ReadConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("%s: ожидалась ошибка, но ее не было", name)
+		}
+	}
+}
+
+// fakeAccount хранит воронки и сделки тестового аккаунта
+type fakeAccount struct {
+	t         *testing.T
+	pipelines []pipelines.Pipeline
+	leads     map[int]int // количество сделок по ID этапа
+	nextID    int
+	requests  []string
+	bodies    []string // тела запросов создания и изменения воронок
+}
+
+func (a *fakeAccount) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.requests = append(a.requests, r.Method+" "+r.URL.Path)
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v4/leads/pipelines"), "/")
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/v4/leads":
+		statusID, _ := strconv.Atoi(r.URL.Query().Get("filter[statuses][0][status_id]"))
+		if a.leads[statusID] == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"leads":[{"id":1}]}}`))
+	case r.Method == "GET" && r.URL.Path == "/api/v4/leads/pipelines":
+		// Этапы отдаются в _embedded.statuses, как в amoCRM
+		type pipelineJSON struct {
+			ID       int    `json:"id"`
+			Name     string `json:"name"`
+			Sort     int    `json:"sort"`
+			IsMain   bool   `json:"is_main"`
+			Embedded struct {
+				Statuses []pipelines.Status `json:"statuses"`
+			} `json:"_embedded"`
+		}
+		var response struct {
+			Embedded struct {
+				Pipelines []pipelineJSON `json:"pipelines"`
+			} `json:"_embedded"`
+		}
+		for _, pipeline := range a.pipelines {
+			item := pipelineJSON{ID: pipeline.ID, Name: pipeline.Name, Sort: pipeline.Sort, IsMain: pipeline.IsMain}
+			item.Embedded.Statuses = pipeline.Statuses
+			response.Embedded.Pipelines = append(response.Embedded.Pipelines, item)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	case r.Method == "POST" && r.URL.Path == "/api/v4/leads/pipelines":
+		a.bodies = append(a.bodies, string(body))
+		var request []struct {
+			Name     string `json:"name"`
+			Sort     int    `json:"sort"`
+			IsMain   bool   `json:"is_main"`
+			Embedded struct {
+				Statuses []pipelines.Status `json:"statuses"`
+			} `json:"_embedded"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		for _, item := range request {
+			pipeline := pipelines.Pipeline{ID: a.id(), Name: item.Name, Sort: item.Sort, IsMain: item.IsMain}
+			for _, status := range item.Embedded.Statuses {
+				status.ID = a.id()
+				pipeline.Statuses = append(pipeline.Statuses, status)
+			}
+			pipeline.Statuses = append(pipeline.Statuses, pipelines.Status{ID: pipelines.StatusWon, Sort: 10000}, pipelines.Status{ID: pipelines.StatusLost, Sort: 11000})
+			a.pipelines = append(a.pipelines, pipeline)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"pipelines":[]}}`))
+	case r.Method == "PATCH" && len(parts) == 2:
+		a.bodies = append(a.bodies, string(body))
+		pipeline := a.pipeline(parts[1])
+		_ = json.NewDecoder(r.Body).Decode(pipeline)
+		_ = json.NewEncoder(w).Encode(pipeline)
+	case r.Method == "POST" && len(parts) == 3:
+		pipeline := a.pipeline(parts[1])
+		var statuses []pipelines.Status
+		_ = json.NewDecoder(r.Body).Decode(&statuses)
+		for _, status := range statuses {
+			status.ID = a.id()
+			pipeline.Statuses = append(pipeline.Statuses, status)
+		}
+		_, _ = w.Write([]byte(`{"_embedded":{"statuses":[]}}`))
+	case (r.Method == "PATCH" || r.Method == "DELETE") && len(parts) == 4:
+		pipeline := a.pipeline(parts[1])
+		statusID, _ := strconv.Atoi(parts[3])
+		for i := range pipeline.Statuses {
+			if pipeline.Statuses[i].ID != statusID {
+				continue
+			}
+			if r.Method == "DELETE" {
+				pipeline.Statuses = append(pipeline.Statuses[:i], pipeline.Statuses[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_ = json.NewDecoder(r.Body).Decode(&pipeline.Statuses[i])
+			_ = json.NewEncoder(w).Encode(pipeline.Statuses[i])
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		a.t.Errorf("Неожиданный запрос %s %s", r.Method, r.URL.Path)
+	}
+}
+
+// id выдает ID для новой воронки или этапа
+func (a *fakeAccount) id() int {
+	a.nextID++
+	return a.nextID
+}
+
+// pipeline находит воронку по ID из пути запроса
+func (a *fakeAccount) pipeline(id string) *pipelines.Pipeline {
+	pipelineID, _ := strconv.Atoi(id)
+	for i := range a.pipelines {
+		if a.pipelines[i].ID == pipelineID {
+			return &a.pipelines[i]
+		}
+	}
+	a.t.Fatalf("Воронка %s не найдена", id)
+	return nil
+}
+
+// newFakeAccount создает аккаунт с главной воронкой "Продажи"
+func newFakeAccount(t *testing.T) *fakeAccount {
+	return &fakeAccount{
+		t:      t,
+		nextID: 1000,
+		leads:  map[int]int{},
+		pipelines: []pipelines.Pipeline{{
+			ID: 10, Name: "Продажи", Sort: 1, IsMain: true,
+			Statuses: []pipelines.Status{
+				{ID: 100, Name: "Неразобранное", Sort: 10, Type: pipelines.StatusTypeUnsorted},
+				{ID: 101, Name: "Первичный контакт", Sort: 10, Color: "#99ccff"},
+				{ID: 102, Name: "Старый этап", Sort: 20, Color: "#cccccc"},
+				{ID: pipelines.StatusWon, Name: "Успешно реализовано", Sort: 10000},
+				{ID: pipelines.StatusLost, Name: "Закрыто и не реализовано", Sort: 11000},
+			},
+		}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	config, err := ReadConfig(strings.NewReader(testConfigYAML))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении описания: %v", err)
+	}
+
+	plan := Diff(config, newFakeAccount(t).pipelines)
+
+	expected := `~ воронка "Продажи" (ID 10): sort: 1 -> 10
++ этап "Продажи" / "Переговоры"
+- этап "Продажи" / "Старый этап" (ID 102)
++ воронка "Партнеры": этапов: 1
+`
+	if plan.String() != expected {
+		t.Errorf("Неверный план:\n%s\nОжидалось:\n%s", plan, expected)
+	}
+}
+
+func TestApply(t *testing.T) {
+	account := newFakeAccount(t)
+	server := httptest.NewServer(account)
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+	config, err := ReadConfig(strings.NewReader(testConfigYAML))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении описания: %v", err)
+	}
+
+	// В удаляемом этапе есть сделки: план не применяется
+	account.leads[102] = 3
+	plan, err := MakePlan(apiClient, config)
+	if err != nil {
+		t.Fatalf("Ошибка при построении плана: %v", err)
+	}
+	if blocked := plan.BlockedDeletes(); len(blocked) != 1 || blocked[0].StatusID != 102 {
+		t.Fatalf("Ожидалось заблокированное удаление этапа 102, получено %+v", blocked)
+	}
+
+	account.requests = nil
+	if err := plan.Apply(apiClient, ApplyOptions{}); err == nil || !strings.Contains(err.Error(), "Старый этап") {
+		t.Fatalf("Ожидалась ошибка удаления этапа со сделками, получено %v", err)
+	}
+	if len(account.requests) != 0 {
+		t.Errorf("Не должно быть запросов при отмене плана, выполнено %v", account.requests)
+	}
+
+	// С подтверждением план применяется, повторный план пустой
+	if err := plan.Apply(apiClient, ApplyOptions{AllowDeleteWithLeads: true}); err != nil {
+		t.Fatalf("Ошибка при применении плана: %v", err)
+	}
+
+	again, err := MakePlan(apiClient, config)
+	if err != nil {
+		t.Fatalf("Ошибка при повторном построении плана: %v", err)
+	}
+	if !again.Empty() {
+		t.Errorf("Ожидался пустой план после применения, получено:\n%s", again)
+	}
+
+	if len(account.pipelines) != 2 || len(account.pipelines[1].Statuses) != 3 {
+		t.Errorf("Неверные воронки после применения: %+v", account.pipelines)
+	}
+	if len(account.pipelines[0].Statuses) != 5 {
+		t.Errorf("Системные этапы должны сохраниться: %+v", account.pipelines[0].Statuses)
+	}
+
+	// Передаются только изменяемые поля воронок
+	if len(account.bodies) == 0 {
+		t.Fatal("Ожидались запросы создания или изменения воронок")
+	}
+	for _, body := range account.bodies {
+		if strings.Contains(body, "is_active") || strings.Contains(body, `"is_main":false`) {
+			t.Errorf("Запрос содержит неизменяемые поля воронки: %s", body)
+		}
+	}
+}
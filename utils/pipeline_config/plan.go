@@ -0,0 +1,297 @@
+package pipeline_config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/leads"
+	"github.com/chudno/amo_crm_sdk/entities/pipelines"
+)
+
+// Action - вид изменения в плане
+type Action string
+
+const (
+	// ActionCreate - создание воронки или этапа
+	ActionCreate Action = "create"
+	// ActionUpdate - изменение воронки или этапа
+	ActionUpdate Action = "update"
+	// ActionDelete - удаление воронки или этапа
+	ActionDelete Action = "delete"
+)
+
+// Change описывает одно изменение воронки или этапа.
+type Change struct {
+	Action Action
+	// Pipeline - название воронки
+	Pipeline   string
+	PipelineID int
+	// Status - название этапа, пустое для изменений самой воронки
+	Status   string
+	StatusID int
+	// Diff - изменяемые свойства в виде "sort: 10 -> 20"
+	Diff []string
+	// HasLeads - в удаляемой воронке или этапе есть сделки
+	HasLeads bool
+
+	// Желаемое состояние для создания и изменения
+	pipeline pipelines.Pipeline
+	status   pipelines.Status
+}
+
+// String возвращает описание изменения в одну строку.
+func (c Change) String() string {
+	var b strings.Builder
+
+	switch c.Action {
+	case ActionCreate:
+		b.WriteString("+ ")
+	case ActionUpdate:
+		b.WriteString("~ ")
+	case ActionDelete:
+		b.WriteString("- ")
+	}
+
+	if c.Status == "" {
+		fmt.Fprintf(&b, "воронка %q", c.Pipeline)
+	} else {
+		fmt.Fprintf(&b, "этап %q / %q", c.Pipeline, c.Status)
+	}
+
+	id := c.PipelineID
+	if c.Status != "" {
+		id = c.StatusID
+	}
+	if id != 0 {
+		fmt.Fprintf(&b, " (ID %d)", id)
+	}
+
+	if len(c.Diff) > 0 {
+		b.WriteString(": " + strings.Join(c.Diff, ", "))
+	}
+	if c.HasLeads {
+		b.WriteString(" [есть сделки]")
+	}
+	return b.String()
+}
+
+// Plan - список изменений, приводящих воронки аккаунта к описанию.
+type Plan struct {
+	Changes []Change
+}
+
+// Empty проверяет, что воронки аккаунта уже соответствуют описанию.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// String возвращает план в виде списка изменений, по одному на строку.
+func (p *Plan) String() string {
+	if p.Empty() {
+		return "Изменений нет\n"
+	}
+
+	var b strings.Builder
+	for _, change := range p.Changes {
+		b.WriteString(change.String() + "\n")
+	}
+	return b.String()
+}
+
+// BlockedDeletes возвращает удаления воронок и этапов, в которых есть сделки.
+func (p *Plan) BlockedDeletes() []Change {
+	var result []Change
+	for _, change := range p.Changes {
+		if change.Action == ActionDelete && change.HasLeads {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// MakePlan сравнивает описание с воронками аккаунта и проверяет наличие сделок в удаляемых воронках и этапах.
+func MakePlan(apiClient *client.Client, config *Config) (*Plan, error) {
+	current, err := pipelines.ListPipelines(apiClient)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить воронки: %w", err)
+	}
+
+	plan := Diff(config, current)
+
+	for i := range plan.Changes {
+		change := &plan.Changes[i]
+		if change.Action != ActionDelete {
+			continue
+		}
+		if change.HasLeads, err = hasLeads(apiClient, change.PipelineID, change.StatusID); err != nil {
+			return nil, fmt.Errorf("не удалось проверить сделки: %w", err)
+		}
+	}
+
+	return plan, nil
+}
+
+// Diff сравнивает описание с переданными воронками без обращения к API.
+// Наличие сделок в удаляемых воронках и этапах не проверяется.
+func Diff(config *Config, current []pipelines.Pipeline) *Plan {
+	plan := &Plan{}
+
+	existing := make(map[string]pipelines.Pipeline)
+	for _, pipeline := range current {
+		existing[strings.TrimSpace(pipeline.Name)] = pipeline
+	}
+
+	described := make(map[string]bool)
+	for i, desired := range config.Pipelines {
+		name := strings.TrimSpace(desired.Name)
+		described[name] = true
+		sort := config.pipelineSort(i)
+
+		actual, ok := existing[name]
+		if !ok {
+			pipeline := pipelines.Pipeline{Name: name, Sort: sort, IsMain: desired.IsMain}
+			for j, status := range desired.Statuses {
+				pipeline.Statuses = append(pipeline.Statuses, pipelines.Status{
+					Name:  strings.TrimSpace(status.Name),
+					Sort:  desired.statusSort(j),
+					Color: status.Color,
+				})
+			}
+			plan.Changes = append(plan.Changes, Change{
+				Action:   ActionCreate,
+				Pipeline: name,
+				Diff:     []string{fmt.Sprintf("этапов: %d", len(pipeline.Statuses))},
+				pipeline: pipeline,
+			})
+			continue
+		}
+
+		// В изменение попадают только отличающиеся поля
+		var diff []string
+		update := pipelines.Pipeline{ID: actual.ID}
+		if actual.Sort != sort {
+			diff = append(diff, fmt.Sprintf("sort: %d -> %d", actual.Sort, sort))
+			update.Sort = sort
+		}
+		// Снять признак главной воронки нельзя, можно только назначить главной другую воронку
+		if desired.IsMain && !actual.IsMain {
+			diff = append(diff, "is_main: false -> true")
+			update.IsMain = true
+		}
+		if len(diff) > 0 {
+			plan.Changes = append(plan.Changes, Change{
+				Action:     ActionUpdate,
+				Pipeline:   name,
+				PipelineID: actual.ID,
+				Diff:       diff,
+				pipeline:   update,
+			})
+		}
+
+		plan.Changes = append(plan.Changes, diffStatuses(desired, actual)...)
+	}
+
+	if config.DeleteUnlisted {
+		for _, pipeline := range current {
+			if !described[strings.TrimSpace(pipeline.Name)] {
+				plan.Changes = append(plan.Changes, Change{
+					Action:     ActionDelete,
+					Pipeline:   pipeline.Name,
+					PipelineID: pipeline.ID,
+				})
+			}
+		}
+	}
+
+	return plan
+}
+
+// diffStatuses сравнивает этапы описанной воронки с этапами существующей воронки.
+// Системные этапы не создаются, не изменяются и не удаляются.
+func diffStatuses(desired Pipeline, actual pipelines.Pipeline) []Change {
+	var changes []Change
+
+	existing := make(map[string]pipelines.Status)
+	for _, status := range actual.Statuses {
+		if isManaged(status) {
+			existing[strings.TrimSpace(status.Name)] = status
+		}
+	}
+
+	described := make(map[string]bool)
+	for i, desiredStatus := range desired.Statuses {
+		name := strings.TrimSpace(desiredStatus.Name)
+		described[name] = true
+		sort := desired.statusSort(i)
+
+		status, ok := existing[name]
+		if !ok {
+			changes = append(changes, Change{
+				Action:     ActionCreate,
+				Pipeline:   actual.Name,
+				PipelineID: actual.ID,
+				Status:     name,
+				status:     pipelines.Status{Name: name, Sort: sort, Color: desiredStatus.Color},
+			})
+			continue
+		}
+
+		var diff []string
+		color := status.Color
+		if desiredStatus.Color != "" && !strings.EqualFold(desiredStatus.Color, status.Color) {
+			diff = append(diff, fmt.Sprintf("color: %s -> %s", status.Color, desiredStatus.Color))
+			color = desiredStatus.Color
+		}
+		if status.Sort != sort {
+			diff = append(diff, fmt.Sprintf("sort: %d -> %d", status.Sort, sort))
+		}
+		if len(diff) > 0 {
+			changes = append(changes, Change{
+				Action:     ActionUpdate,
+				Pipeline:   actual.Name,
+				PipelineID: actual.ID,
+				Status:     name,
+				StatusID:   status.ID,
+				Diff:       diff,
+				status:     pipelines.Status{ID: status.ID, Name: status.Name, Sort: sort, Color: color},
+			})
+		}
+	}
+
+	for _, status := range actual.Statuses {
+		if isManaged(status) && !described[strings.TrimSpace(status.Name)] {
+			changes = append(changes, Change{
+				Action:     ActionDelete,
+				Pipeline:   actual.Name,
+				PipelineID: actual.ID,
+				Status:     status.Name,
+				StatusID:   status.ID,
+			})
+		}
+	}
+
+	return changes
+}
+
+// isManaged проверяет, что этап не является системным
+func isManaged(status pipelines.Status) bool {
+	return !status.IsClosed() && !status.IsUnsorted()
+}
+
+// hasLeads проверяет, есть ли сделки в воронке или, если указан statusID, в этапе воронки
+func hasLeads(apiClient *client.Client, pipelineID, statusID int) (bool, error) {
+	filter := map[string]string{"filter[pipeline_id]": fmt.Sprint(pipelineID)}
+	if statusID != 0 {
+		filter = map[string]string{
+			"filter[statuses][0][pipeline_id]": fmt.Sprint(pipelineID),
+			"filter[statuses][0][status_id]":   fmt.Sprint(statusID),
+		}
+	}
+
+	found, err := leads.GetLeads(apiClient, 1, 1, filter)
+	if err != nil {
+		return false, err
+	}
+	return len(found) > 0, nil
+}
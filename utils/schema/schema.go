@@ -0,0 +1,139 @@
+// Пакет schema загружает схему аккаунта amoCRM: пользовательские поля, воронки и этапы,
+// пользователей и типы задач. Схему можно сохранить в JSON-снимок и использовать без доступа к API.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/pipelines"
+	"github.com/chudno/amo_crm_sdk/entities/tasks"
+	"github.com/chudno/amo_crm_sdk/entities/users"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+)
+
+// usersPageLimit - количество пользователей, запрашиваемых за одну страницу
+const usersPageLimit = 250
+
+// Schema содержит схему аккаунта amoCRM
+type Schema struct {
+	CustomFields map[custom_fields.EntityType][]custom_fields.CustomField `json:"custom_fields"`
+	Pipelines    []pipelines.Pipeline                                     `json:"pipelines"`
+	Users        []users.User                                             `json:"users"`
+	TaskTypes    []tasks.TaskType                                         `json:"task_types"`
+}
+
+// Entities - типы сущностей, пользовательские поля которых входят в схему
+var Entities = []custom_fields.EntityType{
+	custom_fields.EntityLeads,
+	custom_fields.EntityContacts,
+	custom_fields.EntityCompanies,
+	custom_fields.EntityCustomers,
+}
+
+// Fetch загружает схему аккаунта через API.
+func Fetch(apiClient *client.Client) (*Schema, error) {
+	schema := &Schema{CustomFields: make(map[custom_fields.EntityType][]custom_fields.CustomField)}
+
+	for _, entityType := range Entities {
+		fields, err := custom_fields.GetAllCustomFields(apiClient, entityType)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось получить поля %s: %v", entityType, err)
+		}
+		schema.CustomFields[entityType] = fields
+	}
+
+	pipelinesList, err := pipelines.ListPipelines(apiClient)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить воронки: %v", err)
+	}
+	schema.Pipelines = pipelinesList
+
+	for page := 1; ; page++ {
+		usersPage, err := users.ListUsers(apiClient, usersPageLimit, page)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось получить пользователей: %v", err)
+		}
+		schema.Users = append(schema.Users, usersPage...)
+		if len(usersPage) < usersPageLimit {
+			break
+		}
+	}
+
+	taskTypes, err := tasks.ListTaskTypes(apiClient)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить типы задач: %v", err)
+	}
+	schema.TaskTypes = taskTypes
+
+	schema.Normalize()
+	return schema, nil
+}
+
+// Normalize упорядочивает элементы схемы по ID, чтобы снимки и сгенерированный код не зависели
+// от порядка, в котором их вернул API.
+func (s *Schema) Normalize() {
+	for _, fields := range s.CustomFields {
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].ID < fields[j].ID })
+		for _, field := range fields {
+			enums := field.Enums
+			sort.SliceStable(enums, func(i, j int) bool { return enums[i].ID < enums[j].ID })
+		}
+	}
+
+	sort.SliceStable(s.Pipelines, func(i, j int) bool { return s.Pipelines[i].ID < s.Pipelines[j].ID })
+	for _, pipeline := range s.Pipelines {
+		statuses := pipeline.Statuses
+		sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	}
+
+	sort.SliceStable(s.Users, func(i, j int) bool { return s.Users[i].ID < s.Users[j].ID })
+	sort.SliceStable(s.TaskTypes, func(i, j int) bool { return s.TaskTypes[i].ID < s.TaskTypes[j].ID })
+}
+
+// Fields возвращает пользовательские поля сущности.
+func (s *Schema) Fields(entityType custom_fields.EntityType) []custom_fields.CustomField {
+	return s.CustomFields[entityType]
+}
+
+// Field возвращает пользовательское поле сущности по ID или nil, если поля нет.
+func (s *Schema) Field(entityType custom_fields.EntityType, fieldID int) *custom_fields.CustomField {
+	fields := s.CustomFields[entityType]
+	for i := range fields {
+		if fields[i].ID == fieldID {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// Pipeline возвращает воронку по ID или nil, если воронки нет.
+func (s *Schema) Pipeline(pipelineID int) *pipelines.Pipeline {
+	for i := range s.Pipelines {
+		if s.Pipelines[i].ID == pipelineID {
+			return &s.Pipelines[i]
+		}
+	}
+	return nil
+}
+
+// ReadSnapshot читает схему из JSON-снимка.
+func ReadSnapshot(r io.Reader) (*Schema, error) {
+	var schema Schema
+	if err := json.NewDecoder(r).Decode(&schema); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать снимок схемы: %v", err)
+	}
+
+	schema.Normalize()
+	return &schema, nil
+}
+
+// WriteSnapshot сохраняет схему в JSON-снимок.
+func (s *Schema) WriteSnapshot(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
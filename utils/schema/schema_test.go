@@ -0,0 +1,70 @@
+package schema
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+)
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v4/leads/custom_fields":
+			_, _ = w.Write([]byte(`{"_page_count":1,"_embedded":{"custom_fields":[
+				{"id":20,"name":"Источник","type":"select","enums":[{"id":2,"value":"Реклама"},{"id":1,"value":"Сайт"}]},
+				{"id":10,"name":"Бюджет","type":"numeric"}]}}`))
+		case "/api/v4/contacts/custom_fields", "/api/v4/companies/custom_fields", "/api/v4/customers/custom_fields":
+			w.WriteHeader(http.StatusNoContent)
+		case "/api/v4/leads/pipelines":
+			http.ServeFile(w, r, filepath.Join("testdata", "pipelines.json"))
+		case "/api/v4/users":
+			_, _ = w.Write([]byte(`{"_embedded":{"users":[{"id":2,"name":"Петр"},{"id":1,"name":"Иван"}]}}`))
+		case "/api/v4/account":
+			if r.URL.Query().Get("with") != "task_types" {
+				t.Errorf("Ожидался параметр with=task_types, получен %s", r.URL.Query().Get("with"))
+			}
+			_, _ = w.Write([]byte(`{"id":1,"_embedded":{"task_types":[{"id":1,"name":"Звонок"}]}}`))
+		default:
+			t.Errorf("Неожиданный запрос %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	s, err := Fetch(client.NewClient(server.URL, "test_api_key"))
+	if err != nil {
+		t.Fatalf("Ошибка при загрузке схемы: %v", err)
+	}
+
+	leadFields := s.Fields(custom_fields.EntityLeads)
+	if len(leadFields) != 2 || leadFields[0].ID != 10 || leadFields[1].Enums[0].ID != 1 {
+		t.Errorf("Поля не упорядочены по ID: %+v", leadFields)
+	}
+	if s.Field(custom_fields.EntityLeads, 20) == nil || s.Field(custom_fields.EntityContacts, 20) != nil {
+		t.Error("Неверный поиск поля по ID")
+	}
+	if pipeline := s.Pipeline(7); pipeline == nil || len(pipeline.Statuses) != 3 || pipeline.Statuses[0].ID != 142 {
+		t.Errorf("Ожидалась воронка 7 с упорядоченными этапами, получено %+v", pipeline)
+	}
+	if len(s.Users) != 2 || s.Users[0].ID != 1 || len(s.TaskTypes) != 1 {
+		t.Errorf("Неверные пользователи или типы задач: %+v, %+v", s.Users, s.TaskTypes)
+	}
+
+	// Снимок читается обратно без потерь
+	var buf bytes.Buffer
+	if err := s.WriteSnapshot(&buf); err != nil {
+		t.Fatalf("Ошибка при сохранении снимка: %v", err)
+	}
+	restored, err := ReadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("Ошибка при чтении снимка: %v", err)
+	}
+	if restored.Field(custom_fields.EntityLeads, 20).Name != "Источник" || restored.Pipeline(7) == nil ||
+		len(restored.Pipeline(7).Statuses) != 3 {
+		t.Errorf("Снимок восстановлен неверно: %+v", restored)
+	}
+}
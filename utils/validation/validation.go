@@ -0,0 +1,413 @@
+// Пакет validation проверяет сделки, контакты и компании по схеме аккаунта amoCRM до отправки в API:
+// существование пользовательских полей, совместимость типов значений, варианты списков,
+// обязательность полей на этапах и принадлежность этапа воронке.
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/companies"
+	"github.com/chudno/amo_crm_sdk/entities/contacts"
+	"github.com/chudno/amo_crm_sdk/entities/leads"
+	"github.com/chudno/amo_crm_sdk/entities/pipelines"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+	"github.com/chudno/amo_crm_sdk/utils/schema"
+)
+
+// Rule определяет вид нарушения
+type Rule string
+
+// Виды нарушений
+const (
+	RuleUnknownField        Rule = "unknown_field"
+	RuleTypeMismatch        Rule = "type_mismatch"
+	RuleUnknownEnum         Rule = "unknown_enum"
+	RuleNotMultiple         Rule = "not_multiple"
+	RuleRequired            Rule = "required"
+	RuleUnknownPipeline     Rule = "unknown_pipeline"
+	RuleStatusNotInPipeline Rule = "status_not_in_pipeline"
+)
+
+// Violation описывает одно нарушение схемы аккаунта
+type Violation struct {
+	// Rule - вид нарушения
+	Rule Rule
+	// FieldID и FieldCode - пользовательское поле, к которому относится нарушение (если есть)
+	FieldID   int
+	FieldCode string
+	// Message - описание нарушения
+	Message string
+}
+
+// String возвращает описание нарушения
+func (v Violation) String() string {
+	switch {
+	case v.FieldID != 0:
+		return fmt.Sprintf("поле %d: %s", v.FieldID, v.Message)
+	case v.FieldCode != "":
+		return fmt.Sprintf("поле %s: %s", v.FieldCode, v.Message)
+	}
+	return v.Message
+}
+
+// ValidationError возвращается, если сущность не прошла проверку
+type ValidationError struct {
+	EntityType custom_fields.EntityType
+	Violations []Violation
+}
+
+// Error возвращает описание всех нарушений
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.String())
+	}
+	return fmt.Sprintf("%s: проверка по схеме аккаунта не пройдена: %s", e.EntityType, strings.Join(messages, "; "))
+}
+
+// Validator проверяет сущности по схеме аккаунта. Реализует интерфейсы Validator
+// пакетов leads, contacts и companies, поэтому подключается через WithValidator:
+//
+//	leads.CreateLead(apiClient, lead, leads.WithValidator(validator))
+type Validator struct {
+	schema *schema.Schema
+}
+
+// Проверка того, что Validator подключается к функциям создания и обновления сущностей
+var (
+	_ leads.Validator     = (*Validator)(nil)
+	_ contacts.Validator  = (*Validator)(nil)
+	_ companies.Validator = (*Validator)(nil)
+)
+
+// NewValidator создает валидатор по загруженной схеме аккаунта.
+func NewValidator(accountSchema *schema.Schema) *Validator {
+	return &Validator{schema: accountSchema}
+}
+
+// Load загружает схему аккаунта через API и создает валидатор.
+func Load(apiClient *client.Client) (*Validator, error) {
+	accountSchema, err := schema.Fetch(apiClient)
+	if err != nil {
+		return nil, err
+	}
+	return NewValidator(accountSchema), nil
+}
+
+// ValidateLead проверяет сделку. Возвращает *ValidationError, если найдены нарушения.
+func (v *Validator) ValidateLead(lead *leads.Lead) error {
+	return toError(custom_fields.EntityLeads, v.LeadViolations(lead))
+}
+
+// ValidateContact проверяет контакт. Возвращает *ValidationError, если найдены нарушения.
+func (v *Validator) ValidateContact(contact *contacts.Contact) error {
+	return toError(custom_fields.EntityContacts, v.fieldViolations(custom_fields.EntityContacts, contact.CustomFieldsValues))
+}
+
+// ValidateCompany проверяет компанию. Возвращает *ValidationError, если найдены нарушения.
+func (v *Validator) ValidateCompany(company *companies.Company) error {
+	return toError(custom_fields.EntityCompanies, v.fieldViolations(custom_fields.EntityCompanies, company.CustomFieldsValues))
+}
+
+// toError возвращает ошибку с нарушениями или nil, если нарушений нет
+func toError(entityType custom_fields.EntityType, violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{EntityType: entityType, Violations: violations}
+}
+
+// LeadViolations возвращает все нарушения сделки.
+//
+// Если у сделки указан этап, проверяется его принадлежность воронке и заполненность полей,
+// обязательных на этом этапе. Если воронка не указана, этап ищется во всех воронках аккаунта;
+// если этап есть в нескольких воронках (например, системные этапы 142 и 143), обязательные поля
+// не проверяются. При обновлении сделки со сменой этапа обязательные поля нужно передавать вместе с этапом.
+func (v *Validator) LeadViolations(lead *leads.Lead) []Violation {
+	violations := v.fieldViolations(custom_fields.EntityLeads, lead.CustomFieldsValues)
+
+	var pipeline *pipelines.Pipeline
+	switch {
+	case lead.PipelineID != 0:
+		pipeline = v.schema.Pipeline(lead.PipelineID)
+		if pipeline == nil {
+			return append(violations, Violation{
+				Rule:    RuleUnknownPipeline,
+				Message: fmt.Sprintf("воронка %d не найдена", lead.PipelineID),
+			})
+		}
+		if lead.StatusID != 0 && !hasStatus(pipeline, lead.StatusID) {
+			return append(violations, Violation{
+				Rule:    RuleStatusNotInPipeline,
+				Message: fmt.Sprintf("этап %d не принадлежит воронке %d", lead.StatusID, pipeline.ID),
+			})
+		}
+	case lead.StatusID != 0:
+		found := v.statusPipelines(lead.StatusID)
+		if len(found) == 0 {
+			return append(violations, Violation{
+				Rule:    RuleStatusNotInPipeline,
+				Message: fmt.Sprintf("этап %d не найден ни в одной воронке", lead.StatusID),
+			})
+		}
+		if len(found) > 1 {
+			return violations
+		}
+		pipeline = found[0]
+	}
+
+	if lead.StatusID == 0 {
+		return violations
+	}
+
+	filled := v.filledFields(custom_fields.EntityLeads, lead.CustomFieldsValues)
+	for _, field := range v.schema.Fields(custom_fields.EntityLeads) {
+		if !isRequiredAt(field, pipeline.ID, lead.StatusID) {
+			continue
+		}
+		if !filled[field.ID] {
+			violations = append(violations, Violation{
+				Rule:    RuleRequired,
+				FieldID: field.ID,
+				Message: fmt.Sprintf("поле %q обязательно на этапе %d", field.Name, lead.StatusID),
+			})
+		}
+	}
+
+	return violations
+}
+
+// filledFields возвращает ID полей схемы, значения которых переданы по ID или коду поля
+func (v *Validator) filledFields(entityType custom_fields.EntityType, values []custom_fields.CustomFieldValue) map[int]bool {
+	filled := make(map[int]bool)
+	for _, value := range values {
+		if len(value.Values) == 0 {
+			continue
+		}
+		if field := v.findField(entityType, value); field != nil {
+			filled[field.ID] = true
+		}
+	}
+	return filled
+}
+
+// statusPipelines возвращает воронки, в которых есть этап
+func (v *Validator) statusPipelines(statusID int) []*pipelines.Pipeline {
+	var found []*pipelines.Pipeline
+	for i := range v.schema.Pipelines {
+		if hasStatus(&v.schema.Pipelines[i], statusID) {
+			found = append(found, &v.schema.Pipelines[i])
+		}
+	}
+	return found
+}
+
+// hasStatus проверяет, есть ли этап в воронке
+func hasStatus(pipeline *pipelines.Pipeline, statusID int) bool {
+	for _, status := range pipeline.Statuses {
+		if status.ID == statusID {
+			return true
+		}
+	}
+	return false
+}
+
+// isRequiredAt проверяет, обязательно ли поле на этапе воронки
+func isRequiredAt(field custom_fields.CustomField, pipelineID, statusID int) bool {
+	for _, required := range field.RequiredStatuses {
+		if required.PipelineID == pipelineID && required.StatusID == statusID {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldViolations проверяет значения пользовательских полей сущности
+func (v *Validator) fieldViolations(entityType custom_fields.EntityType, values []custom_fields.CustomFieldValue) []Violation {
+	var violations []Violation
+
+	for _, value := range values {
+		field := v.findField(entityType, value)
+		if field == nil {
+			violations = append(violations, Violation{
+				Rule:      RuleUnknownField,
+				FieldID:   value.FieldID,
+				FieldCode: value.FieldCode,
+				Message:   "поле не найдено в аккаунте",
+			})
+			continue
+		}
+
+		for _, violation := range checkValues(field, value.Values) {
+			violation.FieldID = field.ID
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations
+}
+
+// findField находит определение поля по ID или коду значения
+func (v *Validator) findField(entityType custom_fields.EntityType, value custom_fields.CustomFieldValue) *custom_fields.CustomField {
+	if value.FieldID != 0 {
+		return v.schema.Field(entityType, value.FieldID)
+	}
+
+	fields := v.schema.Fields(entityType)
+	for i := range fields {
+		if value.FieldCode != "" && strings.EqualFold(fields[i].Code, value.FieldCode) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// checkValues проверяет значения поля на соответствие его типу
+func checkValues(field *custom_fields.CustomField, values []custom_fields.FieldValue) []Violation {
+	var violations []Violation
+
+	if len(values) > 1 && isSingleValue(field.Type) {
+		violations = append(violations, Violation{
+			Rule:    RuleNotMultiple,
+			Message: fmt.Sprintf("поле типа %s принимает одно значение, передано %d", field.Type, len(values)),
+		})
+	}
+
+	for _, value := range values {
+		if message := checkValue(field, value); message != nil {
+			violations = append(violations, *message)
+		}
+	}
+
+	return violations
+}
+
+// isSingleValue проверяет, принимает ли поле типа fieldType только одно значение
+func isSingleValue(fieldType string) bool {
+	switch fieldType {
+	case custom_fields.TypeMultiselect, custom_fields.TypeMultitext, custom_fields.TypeSmartAddress,
+		custom_fields.TypeItems, custom_fields.TypeCategory, custom_fields.TypeFile:
+		return false
+	}
+	return true
+}
+
+// checkValue проверяет одно значение поля
+func checkValue(field *custom_fields.CustomField, value custom_fields.FieldValue) *Violation {
+	mismatch := func(expected string) *Violation {
+		return &Violation{
+			Rule:    RuleTypeMismatch,
+			Message: fmt.Sprintf("поле типа %s ожидает %s, передано %v", field.Type, expected, value.Value),
+		}
+	}
+
+	switch field.Type {
+	case custom_fields.TypeText, custom_fields.TypeTextarea, custom_fields.TypeURL,
+		custom_fields.TypeStreetAddress, custom_fields.TypeTrackingData:
+		if _, ok := value.Value.(string); !ok {
+			return mismatch("строку")
+		}
+	case custom_fields.TypeNumeric, custom_fields.TypePrice, custom_fields.TypeMonetary:
+		if !isNumber(value.Value) {
+			return mismatch("число")
+		}
+	case custom_fields.TypeCheckbox:
+		if _, ok := value.Value.(bool); !ok {
+			return mismatch("true или false")
+		}
+	case custom_fields.TypeDate, custom_fields.TypeDateTime, custom_fields.TypeBirthday:
+		if !isDate(value.Value) {
+			return mismatch("Unix timestamp или дату в формате RFC3339")
+		}
+	case custom_fields.TypeSelect, custom_fields.TypeRadiobutton, custom_fields.TypeMultiselect:
+		return checkEnum(field, value)
+	case custom_fields.TypeMultitext:
+		if _, ok := value.Value.(string); !ok {
+			return mismatch("строку")
+		}
+		if value.EnumCode != "" && len(field.Enums) > 0 && !hasEnumValue(field, value.EnumCode) {
+			return &Violation{Rule: RuleUnknownEnum, Message: fmt.Sprintf("тип значения %q не найден", value.EnumCode)}
+		}
+	case custom_fields.TypeSmartAddress:
+		if !isAddressSubtype(value.Subtype) {
+			return &Violation{Rule: RuleTypeMismatch, Message: fmt.Sprintf("неизвестная часть адреса %q", value.Subtype)}
+		}
+	case custom_fields.TypeLegalEntity:
+		switch value.Value.(type) {
+		case custom_fields.LegalEntityValue, *custom_fields.LegalEntityValue, map[string]interface{}:
+		default:
+			return mismatch("реквизиты юридического лица")
+		}
+	}
+
+	return nil
+}
+
+// checkEnum проверяет, что выбранный вариант есть среди вариантов поля
+func checkEnum(field *custom_fields.CustomField, value custom_fields.FieldValue) *Violation {
+	if value.EnumID != 0 {
+		for _, enum := range field.Enums {
+			if enum.ID == value.EnumID {
+				return nil
+			}
+		}
+		return &Violation{Rule: RuleUnknownEnum, Message: fmt.Sprintf("вариант %d не найден", value.EnumID)}
+	}
+
+	text, ok := value.Value.(string)
+	if !ok || !hasEnumValue(field, text) {
+		return &Violation{Rule: RuleUnknownEnum, Message: fmt.Sprintf("вариант %v не найден", value.Value)}
+	}
+	return nil
+}
+
+// hasEnumValue проверяет, есть ли у поля вариант с указанным значением
+func hasEnumValue(field *custom_fields.CustomField, text string) bool {
+	for _, enum := range field.Enums {
+		if enum.Value == text {
+			return true
+		}
+	}
+	return false
+}
+
+// isNumber проверяет, является ли значение числом или строкой с числом
+func isNumber(value interface{}) bool {
+	switch v := value.(type) {
+	case float64, float32, int, int64, int32:
+		return true
+	case json.Number:
+		_, err := v.Float64()
+		return err == nil
+	case string:
+		_, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return err == nil
+	}
+	return false
+}
+
+// isDate проверяет, является ли значение датой
+func isDate(value interface{}) bool {
+	if text, ok := value.(string); ok {
+		if _, err := time.Parse(time.RFC3339, text); err == nil {
+			return true
+		}
+		return false
+	}
+	return isNumber(value)
+}
+
+// isAddressSubtype проверяет часть адреса поля smart_address
+func isAddressSubtype(subtype string) bool {
+	switch subtype {
+	case custom_fields.SubtypeAddressLine1, custom_fields.SubtypeAddressLine2, custom_fields.SubtypeCity,
+		custom_fields.SubtypeState, custom_fields.SubtypeZip, custom_fields.SubtypeCountry:
+		return true
+	}
+	return false
+}
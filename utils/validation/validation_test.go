@@ -0,0 +1,214 @@
+package validation
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+	"github.com/chudno/amo_crm_sdk/entities/contacts"
+	"github.com/chudno/amo_crm_sdk/entities/leads"
+	"github.com/chudno/amo_crm_sdk/utils/custom_fields"
+	"github.com/chudno/amo_crm_sdk/utils/schema"
+)
+
+const testSchemaJSON = `{
+	"custom_fields": {
+		"leads": [
+			{"id": 1, "name": "Бюджет клиента", "type": "numeric", "code": "BUDGET", "required_statuses": [{"pipeline_id": 10, "status_id": 142}, {"pipeline_id": 10, "status_id": 100}]},
+			{"id": 2, "name": "Источник", "type": "select", "enums": [{"id": 21, "value": "Сайт"}, {"id": 22, "value": "Реклама"}]},
+			{"id": 3, "name": "Дата договора", "type": "date"},
+			{"id": 4, "name": "Рассылка", "type": "checkbox"}
+		],
+		"contacts": [
+			{"id": 5, "name": "Телефон", "type": "multitext", "code": "PHONE", "enums": [{"id": 51, "value": "WORK"}, {"id": 52, "value": "MOB"}]}
+		]
+	},
+	"pipelines": [
+		{"id": 10, "name": "Основная", "is_main": true, "statuses": [{"id": 100}, {"id": 142}, {"id": 143}]},
+		{"id": 20, "name": "Партнеры", "statuses": [{"id": 200}, {"id": 142}, {"id": 143}]}
+	]
+}`
+
+// newTestValidator создает валидатор по тестовой схеме
+func newTestValidator(t *testing.T) *Validator {
+	accountSchema, err := schema.ReadSnapshot(strings.NewReader(testSchemaJSON))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении схемы: %v", err)
+	}
+	return NewValidator(accountSchema)
+}
+
+// rules возвращает виды нарушений
+func rules(violations []Violation) []Rule {
+	var result []Rule
+	for _, violation := range violations {
+		result = append(result, violation.Rule)
+	}
+	return result
+}
+
+func TestLeadViolations(t *testing.T) {
+	validator := newTestValidator(t)
+
+	tests := []struct {
+		name     string
+		lead     leads.Lead
+		expected []Rule
+	}{
+		{
+			name: "Корректная сделка",
+			lead: leads.Lead{PipelineID: 10, StatusID: 142, CustomFieldsValues: []custom_fields.CustomFieldValue{
+				custom_fields.Field(1, custom_fields.Numeric(1500)),
+				custom_fields.Field(2, custom_fields.Select(21)),
+				custom_fields.Field(3, custom_fields.Text("2021-01-01T00:00:00+03:00")),
+			}},
+		},
+		{
+			name: "Вариант списка по значению",
+			lead: leads.Lead{CustomFieldsValues: []custom_fields.CustomFieldValue{
+				custom_fields.Field(2, custom_fields.Text("Реклама")),
+			}},
+		},
+		{
+			name: "Неизвестное поле и вариант",
+			lead: leads.Lead{CustomFieldsValues: []custom_fields.CustomFieldValue{
+				custom_fields.Field(99, custom_fields.Text("?")),
+				custom_fields.Field(2, custom_fields.Select(23)),
+			}},
+			expected: []Rule{RuleUnknownField, RuleUnknownEnum},
+		},
+		{
+			name: "Несовместимые типы",
+			lead: leads.Lead{CustomFieldsValues: []custom_fields.CustomFieldValue{
+				custom_fields.Field(1, custom_fields.Text("много")),
+				custom_fields.Field(3, custom_fields.Text("вчера")),
+				custom_fields.Field(4, custom_fields.Text("да")),
+			}},
+			expected: []Rule{RuleTypeMismatch, RuleTypeMismatch, RuleTypeMismatch},
+		},
+		{
+			name: "Несколько значений в списке",
+			lead: leads.Lead{CustomFieldsValues: []custom_fields.CustomFieldValue{
+				custom_fields.Field(2, custom_fields.MultiSelect(21, 22)...),
+			}},
+			expected: []Rule{RuleNotMultiple},
+		},
+		{
+			name:     "Обязательное поле на этапе воронки",
+			lead:     leads.Lead{PipelineID: 10, StatusID: 142},
+			expected: []Rule{RuleRequired},
+		},
+		{
+			name:     "Этап без воронки ищется во всех воронках",
+			lead:     leads.Lead{StatusID: 100},
+			expected: []Rule{RuleRequired},
+		},
+		{
+			name: "Смена этапа в неглавной воронке без указания воронки",
+			lead: leads.Lead{StatusID: 200},
+		},
+		{
+			name: "Системный этап без воронки",
+			lead: leads.Lead{StatusID: 142},
+		},
+		{
+			name:     "Этап не найден ни в одной воронке",
+			lead:     leads.Lead{StatusID: 300},
+			expected: []Rule{RuleStatusNotInPipeline},
+		},
+		{
+			name: "Обязательное поле передано по коду",
+			lead: leads.Lead{PipelineID: 10, StatusID: 142, CustomFieldsValues: []custom_fields.CustomFieldValue{
+				custom_fields.FieldByCode("BUDGET", custom_fields.Numeric(1000)),
+			}},
+		},
+		{
+			name: "Поле не обязательно в другой воронке",
+			lead: leads.Lead{PipelineID: 20, StatusID: 142},
+		},
+		{
+			name:     "Этап из другой воронки",
+			lead:     leads.Lead{PipelineID: 20, StatusID: 100},
+			expected: []Rule{RuleStatusNotInPipeline},
+		},
+		{
+			name:     "Неизвестная воронка",
+			lead:     leads.Lead{PipelineID: 30, StatusID: 100},
+			expected: []Rule{RuleUnknownPipeline},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := rules(validator.LeadViolations(&tt.lead))
+			if strings.Join(ruleStrings(actual), ",") != strings.Join(ruleStrings(tt.expected), ",") {
+				t.Errorf("Ожидались нарушения %v, получено %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+// ruleStrings преобразует виды нарушений в строки для сравнения
+func ruleStrings(list []Rule) []string {
+	var result []string
+	for _, rule := range list {
+		result = append(result, string(rule))
+	}
+	return result
+}
+
+func TestValidateContact(t *testing.T) {
+	validator := newTestValidator(t)
+
+	valid := &contacts.Contact{CustomFieldsValues: []custom_fields.CustomFieldValue{
+		custom_fields.FieldByCode("PHONE", custom_fields.Phone("+79001234567", custom_fields.PhoneMobile)),
+	}}
+	if err := validator.ValidateContact(valid); err != nil {
+		t.Errorf("Неожиданная ошибка проверки: %v", err)
+	}
+
+	invalid := &contacts.Contact{CustomFieldsValues: []custom_fields.CustomFieldValue{
+		custom_fields.FieldByCode("PHONE", custom_fields.Phone("+79001234567", "SKYPE")),
+	}}
+	err := validator.ValidateContact(invalid)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Violations) != 1 || validationErr.Violations[0].FieldID != 5 {
+		t.Fatalf("Ожидалась ошибка проверки для поля 5, получено %v", err)
+	}
+	if !strings.Contains(err.Error(), `"SKYPE"`) {
+		t.Errorf("Текст ошибки не содержит неверный тип значения: %v", err)
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"_embedded":{"leads":[{"id":1}]}}`))
+	}))
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+	validator := newTestValidator(t)
+
+	lead := &leads.Lead{Name: "Сделка", PipelineID: 10, StatusID: 142}
+	if _, err := leads.CreateLead(apiClient, lead, leads.WithValidator(validator)); err == nil {
+		t.Fatal("Ожидалась ошибка проверки, но ее не было")
+	}
+	if requests != 0 {
+		t.Errorf("Запрос не должен выполняться при ошибке проверки, выполнено %d", requests)
+	}
+
+	lead.CustomFieldsValues = []custom_fields.CustomFieldValue{custom_fields.Field(1, custom_fields.Numeric(1000))}
+	if _, err := leads.CreateLead(apiClient, lead, leads.WithValidator(validator)); err != nil {
+		t.Fatalf("Неожиданная ошибка: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Ожидался 1 запрос, выполнено %d", requests)
+	}
+}
@@ -0,0 +1,162 @@
+package webhooks
+
+import (
+	"github.com/chudno/amo_crm_sdk/entities/events"
+	"github.com/chudno/amo_crm_sdk/utils/changefeed"
+)
+
+// eventEntityTypes соответствие сущностей вебхуков типам сущностей событий
+var eventEntityTypes = map[string]events.EventEntityType{
+	EntityLead:     events.EventEntityTypeLead,
+	EntityContact:  events.EventEntityTypeContact,
+	EntityCompany:  events.EventEntityTypeCompany,
+	EntityCustomer: events.EventEntityTypeCustomer,
+	EntityTask:     events.EventEntityTypeTask,
+}
+
+// entityEventTypes типы событий для добавления, удаления и восстановления сущностей
+var entityEventTypes = map[string]map[string]events.EventType{
+	EntityLead: {
+		ActionAdd:     events.EventTypeLeadAdded,
+		ActionDelete:  events.EventTypeLeadDeleted,
+		ActionRestore: events.EventTypeLeadRestored,
+	},
+	EntityContact: {
+		ActionAdd:     events.EventTypeContactAdded,
+		ActionDelete:  events.EventTypeContactDeleted,
+		ActionRestore: events.EventTypeContactRestored,
+	},
+	EntityCompany: {
+		ActionAdd:     events.EventTypeCompanyAdded,
+		ActionDelete:  events.EventTypeCompanyDeleted,
+		ActionRestore: events.EventTypeCompanyRestored,
+	},
+	EntityCustomer: {
+		ActionAdd:    events.EventTypeCustomerAdded,
+		ActionDelete: events.EventTypeCustomerDeleted,
+	},
+	EntityTask: {
+		ActionAdd:    events.EventTypeTaskAdded,
+		ActionDelete: events.EventTypeTaskDeleted,
+	},
+}
+
+// noteEventTypes типы событий по числовому типу примечания
+var noteEventTypes = map[int]events.EventType{
+	4:   events.EventTypeCommonNoteAdded,
+	10:  events.EventTypeIncomingCall,
+	11:  events.EventTypeOutgoingCall,
+	25:  events.EventTypeServiceNoteAdded,
+	102: events.EventTypeIncomingSMS,
+	103: events.EventTypeOutgoingSMS,
+}
+
+// entityState общие поля сущностей вебхука
+type entityState struct {
+	id, statusID, oldStatusID, pipelineID, oldPipelineID int
+	responsibleUserID, oldResponsibleUserID              int
+	modifiedUserID                                       int
+	lastModified                                         int64
+}
+
+// state возвращает общие поля сущности уведомления
+func (e *Event) state() (entityState, bool) {
+	switch {
+	case e.Lead != nil:
+		l := e.Lead
+		return entityState{l.ID, l.StatusID, l.OldStatusID, l.PipelineID, l.OldPipelineID,
+			l.ResponsibleUserID, l.OldResponsibleUserID, l.ModifiedUserID, l.LastModified}, true
+	case e.Contact != nil:
+		c := e.Contact
+		return entityState{id: c.ID, responsibleUserID: c.ResponsibleUserID, oldResponsibleUserID: c.OldResponsibleUserID,
+			modifiedUserID: c.ModifiedUserID, lastModified: c.LastModified}, true
+	case e.Company != nil:
+		c := e.Company
+		return entityState{id: c.ID, responsibleUserID: c.ResponsibleUserID, oldResponsibleUserID: c.OldResponsibleUserID,
+			modifiedUserID: c.ModifiedUserID, lastModified: c.LastModified}, true
+	case e.Customer != nil:
+		c := e.Customer
+		return entityState{id: c.ID, statusID: c.StatusID, oldStatusID: c.OldStatusID,
+			responsibleUserID: c.ResponsibleUserID, oldResponsibleUserID: c.OldResponsibleUserID,
+			modifiedUserID: c.ModifiedUserID, lastModified: c.LastModified}, true
+	case e.Task != nil:
+		t := e.Task
+		return entityState{id: t.ID, responsibleUserID: t.ResponsibleUserID, oldResponsibleUserID: t.OldResponsibleUserID,
+			modifiedUserID: t.ModifiedUserID, lastModified: t.LastModified}, true
+	}
+	return entityState{}, false
+}
+
+// Change преобразует уведомление в изменение changefeed, чтобы обрабатывать вебхуки
+// теми же обработчиками, что и опрос событий.
+// Для уведомлений без соответствующего события amoCRM, например об обновлении сущности, возвращается nil.
+func (e *Event) Change() *changefeed.Change {
+	change := &changefeed.Change{
+		EntityType: eventEntityTypes[e.Entity],
+		AccountID:  e.Account.ID,
+		Source:     changefeed.SourceWebhook,
+	}
+
+	switch {
+	case e.Note != nil:
+		eventType, ok := noteEventTypes[e.Note.NoteType]
+		if !ok {
+			return nil
+		}
+		change.Type = eventType
+		change.EntityID = e.Note.ElementID
+		change.CreatedBy = e.Note.CreatedUserID
+		change.CreatedAt = e.Note.DateCreate
+		change.After = &events.NoteRef{ID: e.Note.ID}
+		return change
+	case e.Talk != nil:
+		if e.Action != ActionAdd {
+			return nil
+		}
+		change.Type = events.EventTypeTalkCreated
+		change.EntityType = events.EventEntityType(e.Talk.EntityType)
+		change.EntityID = e.Talk.EntityID
+		change.CreatedAt = e.Talk.CreatedAt
+		change.After = &events.Talk{ID: e.Talk.TalkID, Origin: e.Talk.Origin}
+		return change
+	}
+
+	state, ok := e.state()
+	if !ok {
+		return nil
+	}
+	change.EntityID = state.id
+	change.CreatedBy = state.modifiedUserID
+	change.CreatedAt = state.lastModified
+
+	switch e.Action {
+	case ActionStatusChange:
+		switch e.Entity {
+		case EntityLead:
+			change.Type = events.EventTypeLeadStatusChanged
+			change.Before = &events.LeadStatus{StatusID: state.oldStatusID, PipelineID: state.oldPipelineID}
+			change.After = &events.LeadStatus{StatusID: state.statusID, PipelineID: state.pipelineID}
+		case EntityCustomer:
+			change.Type = events.EventTypeCustomerStatusChanged
+			change.Before = &events.CustomerStatus{StatusID: state.oldStatusID}
+			change.After = &events.CustomerStatus{StatusID: state.statusID}
+		default:
+			return nil
+		}
+	case ActionResponsible:
+		change.Type = events.EventTypeEntityResponsibleChanged
+		change.Before = &events.ResponsibleUser{UserID: state.oldResponsibleUserID}
+		change.After = &events.ResponsibleUser{UserID: state.responsibleUserID}
+	default:
+		eventType, ok := entityEventTypes[e.Entity][e.Action]
+		if !ok {
+			return nil
+		}
+		change.Type = eventType
+		if e.Entity == EntityLead && e.Action != ActionDelete {
+			change.After = &events.LeadStatus{StatusID: state.statusID, PipelineID: state.pipelineID}
+		}
+	}
+
+	return change
+}
@@ -0,0 +1,102 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DedupStore хранит ключи принятых уведомлений, чтобы повторные отправки amoCRM не обрабатывались дважды.
+type DedupStore interface {
+	// Add сохраняет ключ и возвращает false, если ключ уже сохранен
+	Add(ctx context.Context, key string) (bool, error)
+	// Remove удаляет ключ, например если уведомление не удалось поставить в очередь
+	Remove(ctx context.Context, key string) error
+}
+
+// DefaultDedupTTL время хранения ключей в MemoryDedupStore по умолчанию.
+const DefaultDedupTTL = 24 * time.Hour
+
+// MemoryDedupStore хранит ключи уведомлений в памяти процесса в течение заданного времени.
+type MemoryDedupStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	keys      map[string]time.Time
+	lastPrune time.Time
+}
+
+// NewMemoryDedupStore создает хранилище ключей с временем хранения ttl.
+// Если ttl не задано, используется DefaultDedupTTL.
+func NewMemoryDedupStore(ttl time.Duration) *MemoryDedupStore {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &MemoryDedupStore{ttl: ttl, keys: make(map[string]time.Time)}
+}
+
+// Add сохраняет ключ и возвращает false, если ключ уже сохранен и не устарел.
+func (s *MemoryDedupStore) Add(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+
+	if expires, ok := s.keys[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.keys[key] = now.Add(s.ttl)
+	return true, nil
+}
+
+// Remove удаляет ключ.
+func (s *MemoryDedupStore) Remove(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+	return nil
+}
+
+// prune удаляет устаревшие ключи не чаще одного раза за время хранения
+func (s *MemoryDedupStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < s.ttl {
+		return
+	}
+	for key, expires := range s.keys {
+		if !now.Before(expires) {
+			delete(s.keys, key)
+		}
+	}
+	s.lastPrune = now
+}
+
+// Key возвращает ключ уведомления для исключения повторной обработки.
+// Ключ составляется из сущности, действия, ID и даты изменения, а если их нет - из хеша данных уведомления.
+func (e *Event) Key() string {
+	id, modified := e.identity()
+	if id != "" && modified > 0 {
+		return fmt.Sprintf("%s/%s/%s/%d", e.Entity, e.Action, id, modified)
+	}
+
+	data, _ := json.Marshal(e)
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s/%s/%s", e.Entity, e.Action, hex.EncodeToString(sum[:]))
+}
+
+// identity возвращает ID сущности уведомления и дату ее изменения
+func (e *Event) identity() (string, int64) {
+	switch {
+	case e.Note != nil:
+		return fmt.Sprint(e.Note.ID), e.Note.LastModified
+	case e.Talk != nil:
+		return fmt.Sprint(e.Talk.TalkID), e.Talk.UpdatedAt
+	}
+	if state, ok := e.state(); ok && state.id > 0 {
+		return fmt.Sprint(state.id), state.lastModified
+	}
+	return "", 0
+}
@@ -0,0 +1,158 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// DesiredWebhook желаемая подписка на вебхуки для Ensure.
+// События задаются сочетаниями Entities и Actions и/или готовыми именами amoCRM в Settings.
+type DesiredWebhook struct {
+	Destination string
+	Entities    []string
+	Actions     []string
+	Settings    []string
+	Sort        int
+}
+
+// settingNames возвращает отсортированные имена настроек желаемой подписки
+func (d DesiredWebhook) settingNames() ([]string, error) {
+	names := append([]string(nil), d.Settings...)
+	if len(d.Entities) > 0 || len(d.Actions) > 0 {
+		mapped, err := SettingNames(d.Entities, d.Actions)
+		if err != nil {
+			return nil, fmt.Errorf("вебхук %s: %w", d.Destination, err)
+		}
+		names = append(names, mapped...)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("вебхук %s: не заданы события", d.Destination)
+	}
+	return sortedSettings(names), nil
+}
+
+// EnsureResult адреса подписок, измененных Ensure.
+type EnsureResult struct {
+	Created   []string
+	Updated   []string
+	Deleted   []string
+	Unchanged []string
+}
+
+// EnsureOption задает параметры Ensure.
+type EnsureOption func(*ensureOptions)
+
+type ensureOptions struct {
+	deletePrefix string
+	deleteAll    bool
+}
+
+// DeleteUnlistedWithPrefix удаляет подписки с адресом, начинающимся с prefix, которых нет в желаемом списке.
+// Без этого параметра Ensure не удаляет подписки, чтобы не затронуть вебхуки других интеграций.
+func DeleteUnlistedWithPrefix(prefix string) EnsureOption {
+	return func(o *ensureOptions) {
+		o.deletePrefix = prefix
+	}
+}
+
+// DeleteAllUnlisted удаляет все подписки аккаунта, которых нет в желаемом списке.
+func DeleteAllUnlisted() EnsureOption {
+	return func(o *ensureOptions) {
+		o.deleteAll = true
+	}
+}
+
+// Ensure приводит подписки на вебхуки к желаемому состоянию.
+// Подписки сопоставляются по адресу: отсутствующие создаются, подписки с другими событиями,
+// порядком сортировки или отключенные - переподписываются, лишние удаляются, если это разрешено параметрами.
+// Повторный вызов с тем же списком не выполняет изменений.
+func Ensure(apiClient *client.Client, desired []DesiredWebhook, options ...EnsureOption) (*EnsureResult, error) {
+	var opts ensureOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	// Проверяем желаемые подписки до обращения к API
+	wanted := make(map[string]bool, len(desired))
+	settings := make([][]string, len(desired))
+	for i, d := range desired {
+		if d.Destination == "" {
+			return nil, fmt.Errorf("не задан адрес вебхука")
+		}
+		if wanted[d.Destination] {
+			return nil, fmt.Errorf("вебхук %s указан несколько раз", d.Destination)
+		}
+		wanted[d.Destination] = true
+
+		names, err := d.settingNames()
+		if err != nil {
+			return nil, err
+		}
+		settings[i] = names
+	}
+
+	current, err := ListSubscriptions(apiClient)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении вебхуков: %w", err)
+	}
+	existing := make(map[string]Subscription, len(current))
+	for _, subscription := range current {
+		existing[subscription.Destination] = subscription
+	}
+
+	result := &EnsureResult{}
+	for i, d := range desired {
+		subscription := &Subscription{Destination: d.Destination, Settings: settings[i], Sort: d.Sort}
+
+		old, ok := existing[d.Destination]
+		// Если порядок сортировки не задан, amoCRM назначает его сам, и он не сравнивается
+		if ok && !old.Disabled && (d.Sort == 0 || old.Sort == d.Sort) && equalSettings(sortedSettings(old.Settings), settings[i]) {
+			result.Unchanged = append(result.Unchanged, d.Destination)
+			continue
+		}
+
+		if _, err := Subscribe(apiClient, subscription); err != nil {
+			return result, fmt.Errorf("ошибка при подписке вебхука %s: %w", d.Destination, err)
+		}
+		if ok {
+			result.Updated = append(result.Updated, d.Destination)
+		} else {
+			result.Created = append(result.Created, d.Destination)
+		}
+	}
+
+	for _, subscription := range current {
+		if wanted[subscription.Destination] || !opts.deletes(subscription.Destination) {
+			continue
+		}
+		if err := Unsubscribe(apiClient, subscription.Destination); err != nil {
+			return result, fmt.Errorf("ошибка при удалении вебхука %s: %w", subscription.Destination, err)
+		}
+		result.Deleted = append(result.Deleted, subscription.Destination)
+	}
+
+	return result, nil
+}
+
+// deletes проверяет, можно ли удалить подписку на адрес
+func (o ensureOptions) deletes(destination string) bool {
+	if o.deleteAll {
+		return true
+	}
+	return o.deletePrefix != "" && strings.HasPrefix(destination, o.deletePrefix)
+}
+
+// equalSettings сравнивает отсортированные списки имен настроек
+func equalSettings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
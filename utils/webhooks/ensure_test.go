@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// fakeSubscriptions тестовый сервер, хранящий подписки как amoCRM
+type fakeSubscriptions struct {
+	mu       sync.Mutex
+	hooks    map[string]Subscription
+	nextID   int
+	requests []string
+}
+
+func newFakeSubscriptions(hooks ...Subscription) *fakeSubscriptions {
+	f := &fakeSubscriptions{hooks: make(map[string]Subscription), nextID: 100}
+	for _, hook := range hooks {
+		f.hooks[hook.Destination] = hook
+	}
+	return f
+}
+
+func (f *fakeSubscriptions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path != "/api/v4/webhooks" {
+		http.NotFound(w, r)
+		return
+	}
+	f.requests = append(f.requests, r.Method)
+
+	switch r.Method {
+	case http.MethodGet:
+		if len(f.hooks) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		hooks := make([]Subscription, 0, len(f.hooks))
+		for _, hook := range f.hooks {
+			hooks = append(hooks, hook)
+		}
+		var response struct {
+			Embedded struct {
+				Webhooks []Subscription `json:"webhooks"`
+			} `json:"_embedded"`
+		}
+		response.Embedded.Webhooks = hooks
+		_ = json.NewEncoder(w).Encode(response)
+	case http.MethodPost:
+		var hook Subscription
+		if err := json.NewDecoder(r.Body).Decode(&hook); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if old, ok := f.hooks[hook.Destination]; ok {
+			hook.ID = old.ID
+		} else {
+			f.nextID++
+			hook.ID = f.nextID
+		}
+		if hook.Sort == 0 {
+			hook.Sort = 1
+		}
+		f.hooks[hook.Destination] = hook
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(hook)
+	case http.MethodDelete:
+		var body struct {
+			Destination string `json:"destination"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		delete(f.hooks, body.Destination)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestSettingName(t *testing.T) {
+	tests := []struct {
+		entity, action, expected string
+	}{
+		{EntityLead, ActionAdd, "add_lead"},
+		{EntityLead, ActionStatusChange, "status_lead"},
+		{EntityContact, ActionResponsible, "responsible_contact"},
+		{EntityCompany, ActionNote, "note_company"},
+		{EntityCustomer, ActionStatusChange, "status_customer"},
+		{EntityTalk, ActionUpdate, "update_talk"},
+		{EntityUnsorted, ActionDelete, "delete_unsorted"},
+	}
+	for _, test := range tests {
+		name, err := SettingName(test.entity, test.action)
+		if err != nil {
+			t.Fatalf("Ошибка при получении имени настройки: %v", err)
+		}
+		if name != test.expected {
+			t.Errorf("Ожидалось имя настройки %s, получено %s", test.expected, name)
+		}
+	}
+
+	if _, err := SettingName(EntityContact, ActionStatusChange); err == nil {
+		t.Error("Ожидалась ошибка для неподдерживаемого действия")
+	}
+	if _, err := SettingName("deals", ActionAdd); err == nil {
+		t.Error("Ожидалась ошибка для неизвестной сущности")
+	}
+
+	names, err := SettingNames([]string{EntityLead, EntityContact}, []string{ActionAdd, ActionStatusChange})
+	if err != nil {
+		t.Fatalf("Ошибка при получении имен настроек: %v", err)
+	}
+	if expected := []string{"add_lead", "status_lead", "add_contact"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Ожидались настройки %v, получено %v", expected, names)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	fake := newFakeSubscriptions(
+		Subscription{ID: 1, Destination: "https://example.com/amo/leads", Settings: []string{"add_lead"}, Sort: 1},
+		Subscription{ID: 2, Destination: "https://example.com/amo/old", Settings: []string{"add_contact"}, Sort: 1},
+		Subscription{ID: 3, Destination: "https://other.example.com/hook", Settings: []string{"add_lead"}, Sort: 1},
+		Subscription{ID: 4, Destination: "https://example.com/amo/tasks", Settings: []string{"add_task"}, Sort: 1, Disabled: true},
+	)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	desired := []DesiredWebhook{
+		{
+			Destination: "https://example.com/amo/leads",
+			Entities:    []string{EntityLead},
+			Actions:     []string{ActionAdd, ActionStatusChange},
+		},
+		{
+			Destination: "https://example.com/amo/tasks",
+			Settings:    []string{"add_task"},
+		},
+		{
+			Destination: "https://example.com/amo/contacts",
+			Entities:    []string{EntityContact, EntityCompany},
+			Actions:     []string{ActionAdd, ActionUpdate},
+		},
+	}
+
+	result, err := Ensure(apiClient, desired, DeleteUnlistedWithPrefix("https://example.com/amo/"))
+	if err != nil {
+		t.Fatalf("Ошибка при синхронизации вебхуков: %v", err)
+	}
+
+	if !reflect.DeepEqual(result.Created, []string{"https://example.com/amo/contacts"}) {
+		t.Errorf("Неверный список созданных вебхуков: %v", result.Created)
+	}
+	if !reflect.DeepEqual(result.Updated, []string{"https://example.com/amo/leads", "https://example.com/amo/tasks"}) {
+		t.Errorf("Неверный список обновленных вебхуков: %v", result.Updated)
+	}
+	if !reflect.DeepEqual(result.Deleted, []string{"https://example.com/amo/old"}) {
+		t.Errorf("Неверный список удаленных вебхуков: %v", result.Deleted)
+	}
+
+	if _, ok := fake.hooks["https://other.example.com/hook"]; !ok {
+		t.Error("Вебхук с другим адресом не должен удаляться")
+	}
+	expected := []string{"add_company", "add_contact", "update_company", "update_contact"}
+	if settings := fake.hooks["https://example.com/amo/contacts"].Settings; !reflect.DeepEqual(settings, expected) {
+		t.Errorf("Ожидались настройки %v, получено %v", expected, settings)
+	}
+
+	// Повторный вызов не должен ничего изменять
+	fake.requests = nil
+	result, err = Ensure(apiClient, desired, DeleteUnlistedWithPrefix("https://example.com/amo/"))
+	if err != nil {
+		t.Fatalf("Ошибка при повторной синхронизации вебхуков: %v", err)
+	}
+	if len(result.Created)+len(result.Updated)+len(result.Deleted) != 0 || len(result.Unchanged) != 3 {
+		t.Errorf("Повторная синхронизация не должна изменять вебхуки: %+v", result)
+	}
+	if !reflect.DeepEqual(fake.requests, []string{http.MethodGet}) {
+		t.Errorf("Ожидался только запрос списка вебхуков, выполнено %v", fake.requests)
+	}
+}
+
+func TestEnsureValidation(t *testing.T) {
+	fake := newFakeSubscriptions()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	apiClient := client.NewClient(server.URL, "test_api_key")
+
+	invalid := [][]DesiredWebhook{
+		{{Destination: "https://example.com/amo", Entities: []string{"deals"}, Actions: []string{ActionAdd}}},
+		{{Destination: "https://example.com/amo"}},
+		{{Settings: []string{"add_lead"}}},
+		{
+			{Destination: "https://example.com/amo", Settings: []string{"add_lead"}},
+			{Destination: "https://example.com/amo", Settings: []string{"add_contact"}},
+		},
+	}
+	for _, desired := range invalid {
+		if _, err := Ensure(apiClient, desired); err == nil {
+			t.Errorf("Ожидалась ошибка для %+v", desired)
+		}
+	}
+	if len(fake.requests) != 0 {
+		t.Errorf("При ошибке проверки не должно быть запросов к API, выполнено %v", fake.requests)
+	}
+}
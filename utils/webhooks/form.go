@@ -0,0 +1,159 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formNode узел дерева параметров формы вида leads[status][0][id]
+type formNode struct {
+	value    string
+	children map[string]*formNode
+}
+
+// child возвращает дочерний узел или nil
+func (n *formNode) child(name string) *formNode {
+	if n == nil {
+		return nil
+	}
+	return n.children[name]
+}
+
+// items возвращает дочерние узлы в порядке числовых индексов
+func (n *formNode) items() []*formNode {
+	if n == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(n.children))
+	for key := range n.children {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.Atoi(keys[i])
+		b, errB := strconv.Atoi(keys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+
+	items := make([]*formNode, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, n.children[key])
+	}
+	return items
+}
+
+// parseForm строит дерево параметров формы
+func parseForm(values url.Values) *formNode {
+	root := &formNode{children: make(map[string]*formNode)}
+	for key, list := range values {
+		node := root
+		for _, part := range splitFormKey(key) {
+			next, ok := node.children[part]
+			if !ok {
+				next = &formNode{children: make(map[string]*formNode)}
+				node.children[part] = next
+			}
+			node = next
+		}
+		if len(list) > 0 {
+			node.value = list[0]
+		}
+	}
+	return root
+}
+
+// splitFormKey разбивает ключ leads[status][0][id] на части leads, status, 0, id
+func splitFormKey(key string) []string {
+	name := key
+	rest := ""
+	if i := strings.IndexByte(key, '['); i >= 0 {
+		name, rest = key[:i], key[i:]
+	}
+
+	parts := []string{name}
+	for strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			break
+		}
+		parts = append(parts, rest[1:end])
+		rest = rest[end+1:]
+	}
+	return parts
+}
+
+// decodeForm заполняет структуру значениями узла.
+// Имена полей берутся из тегов json, строки преобразуются в числа и логические значения.
+func decodeForm(node *formNode, target interface{}, path string) error {
+	return decodeValue(node, reflect.ValueOf(target).Elem(), path)
+}
+
+// decodeValue заполняет значение v данными узла node, path используется в тексте ошибки
+func decodeValue(node *formNode, v reflect.Value, path string) error {
+	if node == nil {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return decodeValue(node, v.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if err := decodeValue(node.child(name), v.Field(i), path+"["+name+"]"); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		items := node.items()
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(item, slice.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.String:
+		v.SetString(node.value)
+	case reflect.Bool:
+		v.SetBool(node.value == "1" || node.value == "true")
+	case reflect.Int, reflect.Int64:
+		if node.value == "" {
+			return nil
+		}
+		number, err := strconv.ParseInt(node.value, 10, 64)
+		if err != nil {
+			// Некоторые числовые поля amoCRM передает с дробной частью
+			float, floatErr := strconv.ParseFloat(node.value, 64)
+			if floatErr != nil {
+				return fmt.Errorf("неверное числовое значение %s: %q", path, node.value)
+			}
+			number = int64(float)
+		}
+		v.SetInt(number)
+	case reflect.Float64:
+		if node.value == "" {
+			return nil
+		}
+		number, err := strconv.ParseFloat(node.value, 64)
+		if err != nil {
+			return fmt.Errorf("неверное числовое значение %s: %q", path, node.value)
+		}
+		v.SetFloat(number)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package webhooks
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Account аккаунт, от которого пришел вебхук.
+type Account struct {
+	ID        int    `json:"id"`
+	Subdomain string `json:"subdomain"`
+}
+
+// CustomField значение дополнительного поля сущности в вебхуке.
+type CustomField struct {
+	ID     int                `json:"id"`
+	Name   string             `json:"name"`
+	Code   string             `json:"code,omitempty"`
+	Values []CustomFieldValue `json:"values"`
+}
+
+// CustomFieldValue значение дополнительного поля. Для полей с вариантами указывается Enum.
+type CustomFieldValue struct {
+	Value string `json:"value"`
+	Enum  int    `json:"enum,omitempty"`
+}
+
+// Tag тег сущности в вебхуке.
+type Tag struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Lead сделка в вебхуке.
+// OldStatusID и OldPipelineID передаются при смене этапа, OldResponsibleUserID - при смене ответственного.
+type Lead struct {
+	ID                   int           `json:"id"`
+	Name                 string        `json:"name"`
+	StatusID             int           `json:"status_id"`
+	OldStatusID          int           `json:"old_status_id,omitempty"`
+	PipelineID           int           `json:"pipeline_id"`
+	OldPipelineID        int           `json:"old_pipeline_id,omitempty"`
+	Price                int           `json:"price"`
+	ResponsibleUserID    int           `json:"responsible_user_id"`
+	OldResponsibleUserID int           `json:"old_responsible_user_id,omitempty"`
+	CreatedUserID        int           `json:"created_user_id"`
+	ModifiedUserID       int           `json:"modified_user_id"`
+	DateCreate           int64         `json:"date_create"`
+	LastModified         int64         `json:"last_modified"`
+	AccountID            int           `json:"account_id"`
+	CustomFields         []CustomField `json:"custom_fields,omitempty"`
+	Tags                 []Tag         `json:"tags,omitempty"`
+}
+
+// Contact контакт в вебхуке.
+type Contact struct {
+	ID                   int           `json:"id"`
+	Name                 string        `json:"name"`
+	Type                 string        `json:"type"`
+	CompanyName          string        `json:"company_name,omitempty"`
+	LinkedCompanyID      int           `json:"linked_company_id,omitempty"`
+	ResponsibleUserID    int           `json:"responsible_user_id"`
+	OldResponsibleUserID int           `json:"old_responsible_user_id,omitempty"`
+	CreatedUserID        int           `json:"created_user_id"`
+	ModifiedUserID       int           `json:"modified_user_id"`
+	DateCreate           int64         `json:"date_create"`
+	LastModified         int64         `json:"last_modified"`
+	AccountID            int           `json:"account_id"`
+	CustomFields         []CustomField `json:"custom_fields,omitempty"`
+	Tags                 []Tag         `json:"tags,omitempty"`
+}
+
+// Company компания в вебхуке.
+type Company struct {
+	ID                   int           `json:"id"`
+	Name                 string        `json:"name"`
+	ResponsibleUserID    int           `json:"responsible_user_id"`
+	OldResponsibleUserID int           `json:"old_responsible_user_id,omitempty"`
+	CreatedUserID        int           `json:"created_user_id"`
+	ModifiedUserID       int           `json:"modified_user_id"`
+	DateCreate           int64         `json:"date_create"`
+	LastModified         int64         `json:"last_modified"`
+	AccountID            int           `json:"account_id"`
+	CustomFields         []CustomField `json:"custom_fields,omitempty"`
+	Tags                 []Tag         `json:"tags,omitempty"`
+}
+
+// Customer покупатель в вебхуке.
+type Customer struct {
+	ID                   int           `json:"id"`
+	Name                 string        `json:"name"`
+	StatusID             int           `json:"status_id"`
+	OldStatusID          int           `json:"old_status_id,omitempty"`
+	NextPrice            int           `json:"next_price"`
+	NextDate             int64         `json:"next_date"`
+	ResponsibleUserID    int           `json:"responsible_user_id"`
+	OldResponsibleUserID int           `json:"old_responsible_user_id,omitempty"`
+	CreatedUserID        int           `json:"created_user_id"`
+	ModifiedUserID       int           `json:"modified_user_id"`
+	DateCreate           int64         `json:"date_create"`
+	LastModified         int64         `json:"last_modified"`
+	AccountID            int           `json:"account_id"`
+	CustomFields         []CustomField `json:"custom_fields,omitempty"`
+	Tags                 []Tag         `json:"tags,omitempty"`
+}
+
+// Task задача в вебхуке.
+// ElementType - тип связанной сущности: 1 - контакт, 2 - сделка, 3 - компания, 12 - покупатель.
+type Task struct {
+	ID                   int    `json:"id"`
+	ElementID            int    `json:"element_id"`
+	ElementType          int    `json:"element_type"`
+	TaskType             int    `json:"task_type"`
+	Text                 string `json:"text"`
+	Status               int    `json:"status"`
+	CompleteTill         int64  `json:"complete_till"`
+	ResponsibleUserID    int    `json:"responsible_user_id"`
+	OldResponsibleUserID int    `json:"old_responsible_user_id,omitempty"`
+	CreatedUserID        int    `json:"created_user_id"`
+	ModifiedUserID       int    `json:"modified_user_id"`
+	DateCreate           int64  `json:"date_create"`
+	LastModified         int64  `json:"last_modified"`
+	AccountID            int    `json:"account_id"`
+}
+
+// Note примечание в вебхуке.
+// NoteType - числовой тип примечания: 4 - обычное, 10 - входящий звонок, 11 - исходящий звонок,
+// 25 - системное, 102 - входящее SMS, 103 - исходящее SMS.
+type Note struct {
+	ID                int    `json:"id"`
+	NoteType          int    `json:"note_type"`
+	ElementID         int    `json:"element_id"`
+	ElementType       int    `json:"element_type"`
+	Text              string `json:"text"`
+	ResponsibleUserID int    `json:"responsible_user_id"`
+	CreatedUserID     int    `json:"created_user_id"`
+	ModifiedUserID    int    `json:"modified_user_id"`
+	DateCreate        int64  `json:"date_create"`
+	LastModified      int64  `json:"last_modified"`
+	AccountID         int    `json:"account_id"`
+}
+
+// Talk беседа в вебхуке.
+type Talk struct {
+	TalkID     int    `json:"talk_id"`
+	ChatID     string `json:"chat_id"`
+	ContactID  int    `json:"contact_id"`
+	EntityID   int    `json:"entity_id"`
+	EntityType string `json:"entity_type"`
+	Origin     string `json:"origin"`
+	IsInWork   bool   `json:"is_in_work"`
+	IsRead     bool   `json:"is_read"`
+	Rate       int    `json:"rate,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	UpdatedAt  int64  `json:"updated_at"`
+	AccountID  int    `json:"account_id"`
+}
+
+// Unsorted неразобранная заявка в вебхуке.
+type Unsorted struct {
+	UID        string `json:"uid"`
+	Source     string `json:"source"`
+	Category   string `json:"category"`
+	PipelineID int    `json:"pipeline_id"`
+	LeadID     int    `json:"lead_id,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	Action     string `json:"action,omitempty"`
+	AccountID  int    `json:"account_id"`
+}
+
+// Event одно уведомление вебхука: действие Action над сущностью Entity.
+// Заполнено поле, соответствующее сущности; для действия ActionNote - поле Note.
+type Event struct {
+	Entity   string
+	Action   string
+	Account  Account
+	Lead     *Lead
+	Contact  *Contact
+	Company  *Company
+	Customer *Customer
+	Task     *Task
+	Note     *Note
+	Talk     *Talk
+	Unsorted *Unsorted
+}
+
+// Payload разобранное тело вебхука.
+type Payload struct {
+	Account Account
+	Events  []Event
+}
+
+// formRoots соответствие корневых ключей формы сущностям, в порядке разбора
+var formRoots = []struct {
+	key    string
+	entity string
+}{
+	{"leads", EntityLead},
+	{"contacts", EntityContact},
+	{"companies", EntityCompany},
+	{"customers", EntityCustomer},
+	{"task", EntityTask},
+	{"tasks", EntityTask},
+	{"talk", EntityTalk},
+	{"talks", EntityTalk},
+	{"unsorted", EntityUnsorted},
+}
+
+// actionOrder порядок разбора действий
+var actionOrder = []string{ActionAdd, ActionUpdate, ActionRestore, ActionStatusChange, ActionResponsible, ActionNote, ActionDelete}
+
+// ParsePayload разбирает параметры формы вебхука amoCRM.
+//
+// amoCRM передает вебхук в формате application/x-www-form-urlencoded с ключами вида
+// leads[status][0][id], leads[add][0][custom_fields][0][values][0][value] и account[subdomain].
+func ParsePayload(values url.Values) (*Payload, error) {
+	root := parseForm(values)
+
+	payload := &Payload{}
+	if err := decodeForm(root.child("account"), &payload.Account, "account"); err != nil {
+		return nil, err
+	}
+
+	for _, formRoot := range formRoots {
+		node := root.child(formRoot.key)
+		if node == nil {
+			continue
+		}
+		for _, action := range actionOrder {
+			for i, item := range node.child(action).items() {
+				path := fmt.Sprintf("%s[%s][%d]", formRoot.key, action, i)
+				event, err := decodeEvent(item, formRoot.entity, action, path)
+				if err != nil {
+					return nil, err
+				}
+				event.Account = payload.Account
+				payload.Events = append(payload.Events, *event)
+			}
+		}
+	}
+
+	return payload, nil
+}
+
+// decodeEvent разбирает одно уведомление о действии над сущностью
+func decodeEvent(item *formNode, entity, action, path string) (*Event, error) {
+	event := &Event{Entity: entity, Action: action}
+
+	// Примечание передается вложенным объектом: leads[note][0][note][text]
+	if action == ActionNote {
+		event.Note = &Note{}
+		return event, decodeForm(item.child("note"), event.Note, path+"[note]")
+	}
+
+	// Компании в старом формате приходят в contacts с типом company
+	if kind := item.child("type"); entity == EntityContact && kind != nil && kind.value == "company" {
+		event.Entity = EntityCompany
+		entity = EntityCompany
+	}
+
+	var target interface{}
+	switch entity {
+	case EntityLead:
+		event.Lead = &Lead{}
+		target = event.Lead
+	case EntityContact:
+		event.Contact = &Contact{}
+		target = event.Contact
+	case EntityCompany:
+		event.Company = &Company{}
+		target = event.Company
+	case EntityCustomer:
+		event.Customer = &Customer{}
+		target = event.Customer
+	case EntityTask:
+		event.Task = &Task{}
+		target = event.Task
+	case EntityTalk:
+		event.Talk = &Talk{}
+		target = event.Talk
+	case EntityUnsorted:
+		event.Unsorted = &Unsorted{}
+		target = event.Unsorted
+	}
+
+	return event, decodeForm(item, target, path)
+}
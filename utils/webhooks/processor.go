@@ -0,0 +1,259 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Параметры асинхронной обработки по умолчанию
+const (
+	DefaultWorkers        = 4
+	DefaultQueueSize      = 1000
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+)
+
+// ErrProcessorClosed возвращается в обработчик недоставленных уведомлений,
+// если уведомление не было обработано до завершения работы Processor.
+var ErrProcessorClosed = errors.New("обработка вебхуков остановлена")
+
+// Processor принимает вебхуки amoCRM, сразу отвечает на запрос и обрабатывает уведомления
+// в пуле обработчиков. amoCRM отключает вебхуки, которые отвечают медленно или с ошибкой,
+// поэтому обработка не должна выполняться во время запроса.
+//
+// Повторные отправки одного уведомления отбрасываются по ключу Event.Key через DedupStore.
+// Если обработчик вернул ошибку, уведомление обрабатывается повторно с растущей паузой,
+// а после исчерпания попыток передается в обработчик недоставленных уведомлений.
+type Processor struct {
+	receiver       *Receiver
+	store          DedupStore
+	workers        int
+	queueSize      int
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	onDeadLetter   func(event *Event, err error)
+
+	queue  chan *Event
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// ProcessorOption задает параметры асинхронной обработки.
+type ProcessorOption func(*Processor)
+
+// WithWorkers задает количество одновременно работающих обработчиков.
+func WithWorkers(workers int) ProcessorOption {
+	return func(p *Processor) {
+		if workers > 0 {
+			p.workers = workers
+		}
+	}
+}
+
+// WithQueueSize задает размер очереди. Если очередь заполнена, вебхук отклоняется
+// со статусом 503, и amoCRM повторяет отправку позже.
+func WithQueueSize(size int) ProcessorOption {
+	return func(p *Processor) {
+		if size > 0 {
+			p.queueSize = size
+		}
+	}
+}
+
+// WithDedupStore задает хранилище ключей принятых уведомлений. По умолчанию ключи хранятся в памяти.
+func WithDedupStore(store DedupStore) ProcessorOption {
+	return func(p *Processor) {
+		p.store = store
+	}
+}
+
+// WithRetry задает количество попыток обработки и паузы между ними.
+// Пауза удваивается после каждой попытки, но не превышает maxBackoff.
+func WithRetry(maxAttempts int, initialBackoff, maxBackoff time.Duration) ProcessorOption {
+	return func(p *Processor) {
+		if maxAttempts > 0 {
+			p.maxAttempts = maxAttempts
+		}
+		p.initialBackoff = initialBackoff
+		p.maxBackoff = maxBackoff
+	}
+}
+
+// WithDeadLetter задает обработчик уведомлений, которые не удалось обработать.
+func WithDeadLetter(onDeadLetter func(event *Event, err error)) ProcessorOption {
+	return func(p *Processor) {
+		p.onDeadLetter = onDeadLetter
+	}
+}
+
+// NewProcessor создает асинхронную обработку вебхуков с обработчиками receiver и запускает пул обработчиков.
+func NewProcessor(receiver *Receiver, options ...ProcessorOption) *Processor {
+	p := &Processor{
+		receiver:       receiver,
+		workers:        DefaultWorkers,
+		queueSize:      DefaultQueueSize,
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		onDeadLetter:   func(*Event, error) {},
+	}
+	for _, option := range options {
+		option(p)
+	}
+	if p.store == nil {
+		p.store = NewMemoryDedupStore(DefaultDedupTTL)
+	}
+
+	p.queue = make(chan *Event, p.queueSize)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+	return p
+}
+
+// ServeHTTP разбирает вебхук, ставит новые уведомления в очередь и сразу отвечает 200.
+func (p *Processor) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	payload, status, err := ReadPayload(w, req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := p.Enqueue(req.Context(), payload); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK"))
+}
+
+// Enqueue ставит в очередь уведомления, которые еще не принимались.
+// Если очередь заполнена или обработка остановлена, возвращается ошибка,
+// а ключи непоставленных уведомлений удаляются, чтобы повторная отправка была принята.
+func (p *Processor) Enqueue(ctx context.Context, payload *Payload) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrProcessorClosed
+	}
+
+	for i := range payload.Events {
+		event := &payload.Events[i]
+		key := event.Key()
+
+		added, err := p.store.Add(ctx, key)
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке повтора уведомления: %w", err)
+		}
+		if !added {
+			continue
+		}
+
+		select {
+		case p.queue <- event:
+		default:
+			if err := p.store.Remove(ctx, key); err != nil {
+				return fmt.Errorf("ошибка при удалении ключа уведомления: %w", err)
+			}
+			return fmt.Errorf("очередь обработки вебхуков заполнена")
+		}
+	}
+	return nil
+}
+
+// Shutdown прекращает прием вебхуков и ждет обработки уведомлений из очереди.
+// Если ctx отменен раньше, повторные попытки прерываются, а необработанные уведомления
+// передаются в обработчик недоставленных уведомлений с ошибкой ErrProcessorClosed.
+func (p *Processor) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work обрабатывает уведомления из очереди
+func (p *Processor) work() {
+	defer p.wg.Done()
+	for event := range p.queue {
+		p.process(event)
+	}
+}
+
+// process обрабатывает уведомление с повторными попытками
+func (p *Processor) process(event *Event) {
+	var err error
+	for attempt := 1; attempt <= p.maxAttempts; attempt++ {
+		if p.ctx.Err() != nil {
+			p.onDeadLetter(event, ErrProcessorClosed)
+			return
+		}
+
+		if err = p.receiver.DispatchEvent(p.ctx, event); err == nil {
+			return
+		}
+
+		if attempt < p.maxAttempts {
+			if sleep(p.ctx, p.backoff(attempt)) != nil {
+				p.onDeadLetter(event, ErrProcessorClosed)
+				return
+			}
+		}
+	}
+	p.onDeadLetter(event, err)
+}
+
+// backoff возвращает паузу перед следующей попыткой
+func (p *Processor) backoff(attempt int) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < attempt && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+	return delay
+}
+
+// sleep ожидает указанное время или отмену ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
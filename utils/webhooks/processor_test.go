@@ -0,0 +1,170 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// postWebhook отправляет записанный вебхук в обработчик
+func postWebhook(handler http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/amocrm", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestEventKey(t *testing.T) {
+	payload := parseRecordedPayload(t, "lead_status.txt")
+	if key := payload.Events[0].Key(); key != "leads/status/25399013/1700000300" {
+		t.Errorf("Неверный ключ уведомления: %s", key)
+	}
+
+	unsorted := parseRecordedPayload(t, "talk_task_unsorted.txt").Events[2]
+	key := unsorted.Key()
+	if !strings.HasPrefix(key, "unsorted/add/") || key != unsorted.Key() {
+		t.Errorf("Ожидался стабильный ключ по хешу, получен %s", key)
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	var mu sync.Mutex
+	attempts := make(map[string]int)
+	var deadLetters []error
+
+	receiver := NewReceiver()
+	receiver.HandleAll(func(ctx context.Context, event *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[event.Entity]++
+		switch event.Entity {
+		case EntityContact:
+			// Первая попытка завершается ошибкой, вторая - успешно
+			if attempts[event.Entity] == 1 {
+				return errors.New("временная ошибка")
+			}
+		case EntityCompany:
+			return errors.New("некорректные данные")
+		}
+		return nil
+	})
+
+	processor := NewProcessor(receiver,
+		WithWorkers(2),
+		WithRetry(3, time.Millisecond, 5*time.Millisecond),
+		WithDeadLetter(func(event *Event, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			deadLetters = append(deadLetters, err)
+		}),
+	)
+
+	body := readRecordedPayload(t, "lead_status.txt")
+	for i := 0; i < 3; i++ {
+		if rec := postWebhook(processor, body); rec.Code != http.StatusOK {
+			t.Fatalf("Ожидался статус 200, получен %d", rec.Code)
+		}
+	}
+	if rec := postWebhook(processor, readRecordedPayload(t, "contacts_add.txt")); rec.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d", rec.Code)
+	}
+
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Ошибка при остановке обработки: %v", err)
+	}
+
+	if attempts[EntityLead] != 1 {
+		t.Errorf("Повторные отправки должны обрабатываться один раз, обработано %d", attempts[EntityLead])
+	}
+	if attempts[EntityContact] != 2 {
+		t.Errorf("Ожидалось 2 попытки обработки контакта, выполнено %d", attempts[EntityContact])
+	}
+	if attempts[EntityCompany] != 3 || len(deadLetters) != 1 {
+		t.Errorf("Ожидалось 3 попытки и 1 недоставленное уведомление, получено %d и %d", attempts[EntityCompany], len(deadLetters))
+	}
+
+	if rec := postWebhook(processor, body); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("После остановки ожидался статус 503, получен %d", rec.Code)
+	}
+}
+
+func TestProcessorQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	receiver := NewReceiver()
+	receiver.HandleAll(func(ctx context.Context, event *Event) error {
+		<-release
+		return nil
+	})
+
+	var deadLetters []error
+	processor := NewProcessor(receiver, WithWorkers(1), WithQueueSize(1),
+		WithDeadLetter(func(event *Event, err error) {
+			deadLetters = append(deadLetters, err)
+		}))
+
+	// Первое уведомление занимает обработчик, второе - очередь, третье не помещается
+	rec := postWebhook(processor, readRecordedPayload(t, "talk_task_unsorted.txt"))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Ожидался статус 503 при заполненной очереди, получен %d", rec.Code)
+	}
+
+	// Непоставленное уведомление принимается при повторной отправке
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		rec = postWebhook(processor, readRecordedPayload(t, "talk_task_unsorted.txt"))
+		if rec.Code == http.StatusOK || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("Ожидался статус 200 при повторной отправке, получен %d", rec.Code)
+	}
+
+	if err := processor.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Ошибка при остановке обработки: %v", err)
+	}
+	if len(deadLetters) != 0 {
+		t.Errorf("Не ожидалось недоставленных уведомлений, получено %v", deadLetters)
+	}
+}
+
+func TestProcessorShutdownTimeout(t *testing.T) {
+	receiver := NewReceiver()
+	receiver.HandleAll(func(ctx context.Context, event *Event) error {
+		return errors.New("сервис недоступен")
+	})
+
+	deadLetters := make(chan error, 10)
+	processor := NewProcessor(receiver, WithWorkers(1),
+		WithRetry(10, time.Hour, time.Hour),
+		WithDeadLetter(func(event *Event, err error) {
+			deadLetters <- err
+		}))
+
+	if rec := postWebhook(processor, readRecordedPayload(t, "lead_status.txt")); rec.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d", rec.Code)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := processor.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Ожидалась ошибка истечения времени, получено %v", err)
+	}
+	select {
+	case err := <-deadLetters:
+		if !errors.Is(err, ErrProcessorClosed) {
+			t.Errorf("Ожидалась ошибка ErrProcessorClosed, получено %v", err)
+		}
+	default:
+		t.Error("Необработанное уведомление не передано в обработчик недоставленных уведомлений")
+	}
+}
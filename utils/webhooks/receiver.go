@@ -0,0 +1,116 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/chudno/amo_crm_sdk/utils/changefeed"
+)
+
+// MaxBodySize максимальный размер тела входящего вебхука.
+const MaxBodySize = 10 << 20
+
+// HandlerFunc обрабатывает одно уведомление вебхука.
+type HandlerFunc func(ctx context.Context, event *Event) error
+
+// Receiver принимает вебхуки amoCRM и вызывает обработчики, зарегистрированные
+// для сущности и действия. Receiver реализует http.Handler.
+type Receiver struct {
+	handlers map[string][]HandlerFunc
+	all      []HandlerFunc
+}
+
+// NewReceiver создает обработчик входящих вебхуков без зарегистрированных обработчиков.
+func NewReceiver() *Receiver {
+	return &Receiver{handlers: make(map[string][]HandlerFunc)}
+}
+
+// Handle регистрирует обработчик действия action над сущностью entity,
+// например Handle(EntityLead, ActionStatusChange, ...).
+// Примечания приходят действием ActionNote сущности, к которой они добавлены.
+func (r *Receiver) Handle(entity, action string, handler HandlerFunc) {
+	key := entity + "/" + action
+	r.handlers[key] = append(r.handlers[key], handler)
+}
+
+// HandleAll регистрирует обработчик всех уведомлений.
+func (r *Receiver) HandleAll(handler HandlerFunc) {
+	r.all = append(r.all, handler)
+}
+
+// HandleChanges передает уведомления, для которых есть соответствующее событие amoCRM,
+// в маршрутизатор изменений. Так одни и те же обработчики работают с вебхуками и с опросом событий.
+func (r *Receiver) HandleChanges(router *changefeed.Router) {
+	r.HandleAll(func(ctx context.Context, event *Event) error {
+		change := event.Change()
+		if change == nil || !router.Handles(change.Type) {
+			return nil
+		}
+		return router.Dispatch(ctx, change)
+	})
+}
+
+// Dispatch вызывает обработчики всех уведомлений вебхука.
+// Обработка прекращается на первой ошибке.
+func (r *Receiver) Dispatch(ctx context.Context, payload *Payload) error {
+	for i := range payload.Events {
+		if err := r.DispatchEvent(ctx, &payload.Events[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DispatchEvent вызывает обработчики одного уведомления.
+func (r *Receiver) DispatchEvent(ctx context.Context, event *Event) error {
+	for _, handler := range r.handlers[event.Entity+"/"+event.Action] {
+		if err := handler(ctx, event); err != nil {
+			return fmt.Errorf("ошибка при обработке %s/%s: %w", event.Entity, event.Action, err)
+		}
+	}
+	for _, handler := range r.all {
+		if err := handler(ctx, event); err != nil {
+			return fmt.Errorf("ошибка при обработке %s/%s: %w", event.Entity, event.Action, err)
+		}
+	}
+	return nil
+}
+
+// ServeHTTP разбирает вебхук и синхронно вызывает обработчики.
+// При ошибке разбора возвращается 400, при ошибке обработчика - 500, и amoCRM повторит отправку.
+func (r *Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	payload, status, err := ReadPayload(w, req)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	if err := r.Dispatch(req.Context(), payload); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("OK"))
+}
+
+// ReadPayload проверяет метод запроса и разбирает тело вебхука.
+// При ошибке возвращается HTTP-статус ответа.
+func ReadPayload(w http.ResponseWriter, req *http.Request) (*Payload, int, error) {
+	if req.Method != http.MethodPost {
+		return nil, http.StatusMethodNotAllowed, fmt.Errorf("метод %s не поддерживается", req.Method)
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, MaxBodySize)
+	if err := req.ParseForm(); err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("ошибка при чтении вебхука: %w", err)
+	}
+
+	payload, err := ParsePayload(req.PostForm)
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("ошибка при разборе вебхука: %w", err)
+	}
+
+	return payload, http.StatusOK, nil
+}
@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chudno/amo_crm_sdk/entities/events"
+	"github.com/chudno/amo_crm_sdk/utils/changefeed"
+)
+
+// readRecordedPayload читает записанное тело вебхука из testdata
+func readRecordedPayload(t *testing.T, name string) string {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Ошибка при чтении %s: %v", name, err)
+	}
+	return string(data)
+}
+
+// parseRecordedPayload разбирает записанное тело вебхука
+func parseRecordedPayload(t *testing.T, name string) *Payload {
+	values, err := url.ParseQuery(readRecordedPayload(t, name))
+	if err != nil {
+		t.Fatalf("Ошибка при разборе формы %s: %v", name, err)
+	}
+	payload, err := ParsePayload(values)
+	if err != nil {
+		t.Fatalf("Ошибка при разборе вебхука %s: %v", name, err)
+	}
+	return payload
+}
+
+func TestParsePayloadLeadStatus(t *testing.T) {
+	payload := parseRecordedPayload(t, "lead_status.txt")
+
+	if payload.Account.ID != 29085955 || payload.Account.Subdomain != "example" {
+		t.Errorf("Неверный аккаунт: %+v", payload.Account)
+	}
+	if len(payload.Events) != 1 {
+		t.Fatalf("Ожидалось 1 уведомление, получено %d", len(payload.Events))
+	}
+
+	event := payload.Events[0]
+	if event.Entity != EntityLead || event.Action != ActionStatusChange || event.Lead == nil {
+		t.Fatalf("Ожидалась смена этапа сделки, получено %s/%s", event.Entity, event.Action)
+	}
+
+	lead := event.Lead
+	if lead.ID != 25399013 || lead.Name != "Заказ #1042" || lead.Price != 15000 {
+		t.Errorf("Неверные данные сделки: %+v", lead)
+	}
+	if lead.StatusID != 142 || lead.OldStatusID != 47525395 || lead.PipelineID != 5284780 {
+		t.Errorf("Неверный этап сделки: %+v", lead)
+	}
+	if len(lead.Tags) != 1 || lead.Tags[0].Name != "vip" {
+		t.Errorf("Неверные теги: %+v", lead.Tags)
+	}
+	if len(lead.CustomFields) != 2 {
+		t.Fatalf("Ожидалось 2 дополнительных поля, получено %d", len(lead.CustomFields))
+	}
+	source := lead.CustomFields[0]
+	if source.ID != 697115 || source.Values[0].Value != "Сайт" || source.Values[0].Enum != 402537 {
+		t.Errorf("Неверное значение поля: %+v", source)
+	}
+
+	change := event.Change()
+	if change == nil || change.Type != events.EventTypeLeadStatusChanged || change.Source != changefeed.SourceWebhook {
+		t.Fatalf("Ожидалось изменение lead_status_changed, получено %+v", change)
+	}
+	before, _ := change.Before.(*events.LeadStatus)
+	after, _ := change.After.(*events.LeadStatus)
+	if before == nil || after == nil || before.StatusID != 47525395 || after.StatusID != 142 {
+		t.Errorf("Неверные значения изменения: %#v -> %#v", change.Before, change.After)
+	}
+}
+
+func TestParsePayloadContacts(t *testing.T) {
+	payload := parseRecordedPayload(t, "contacts_add.txt")
+
+	if len(payload.Events) != 2 {
+		t.Fatalf("Ожидалось 2 уведомления, получено %d", len(payload.Events))
+	}
+
+	contact := payload.Events[0]
+	if contact.Entity != EntityContact || contact.Contact == nil || contact.Contact.Name != "Иван Петров" {
+		t.Fatalf("Ожидался контакт, получено %+v", contact)
+	}
+	phones := contact.Contact.CustomFields[0].Values
+	if len(phones) != 2 || phones[1].Value != "+79007654321" {
+		t.Errorf("Неверные телефоны контакта: %+v", phones)
+	}
+
+	company := payload.Events[1]
+	if company.Entity != EntityCompany || company.Company == nil || company.Company.Name != "ООО Ромашка" {
+		t.Errorf("Ожидалась компания, получено %+v", company)
+	}
+	if change := company.Change(); change == nil || change.Type != events.EventTypeCompanyAdded {
+		t.Errorf("Ожидалось изменение company_added, получено %+v", change)
+	}
+}
+
+func TestParsePayloadNotes(t *testing.T) {
+	payload := parseRecordedPayload(t, "lead_note.txt")
+
+	if len(payload.Events) != 2 {
+		t.Fatalf("Ожидалось 2 уведомления, получено %d", len(payload.Events))
+	}
+
+	responsible := payload.Events[0]
+	if responsible.Action != ActionResponsible || responsible.Lead.OldResponsibleUserID != 8017621 {
+		t.Errorf("Ожидалась смена ответственного, получено %+v", responsible.Lead)
+	}
+
+	note := payload.Events[1]
+	if note.Entity != EntityLead || note.Action != ActionNote || note.Note == nil {
+		t.Fatalf("Ожидалось примечание сделки, получено %s/%s", note.Entity, note.Action)
+	}
+	if note.Note.ID != 112233 || note.Note.NoteType != 10 || note.Note.ElementID != 25399013 {
+		t.Errorf("Неверные данные примечания: %+v", note.Note)
+	}
+
+	change := note.Change()
+	if change == nil || change.Type != events.EventTypeIncomingCall || change.EntityType != events.EventEntityTypeLead {
+		t.Fatalf("Ожидалось изменение incoming_call по сделке, получено %+v", change)
+	}
+	if ref, ok := change.After.(*events.NoteRef); !ok || ref.ID != 112233 {
+		t.Errorf("Ожидалась ссылка на примечание 112233, получено %#v", change.After)
+	}
+}
+
+func TestParsePayloadTalkTaskUnsorted(t *testing.T) {
+	payload := parseRecordedPayload(t, "talk_task_unsorted.txt")
+
+	if len(payload.Events) != 3 {
+		t.Fatalf("Ожидалось 3 уведомления, получено %d", len(payload.Events))
+	}
+
+	task := payload.Events[0]
+	if task.Entity != EntityTask || task.Task == nil || task.Task.Text != "Перезвонить" || task.Task.CompleteTill != 1700086400 {
+		t.Errorf("Неверная задача: %+v", task.Task)
+	}
+
+	talk := payload.Events[1]
+	if talk.Entity != EntityTalk || talk.Talk == nil || talk.Talk.TalkID != 1847 || !talk.Talk.IsInWork || talk.Talk.IsRead {
+		t.Errorf("Неверная беседа: %+v", talk.Talk)
+	}
+
+	unsorted := payload.Events[2]
+	if unsorted.Entity != EntityUnsorted || unsorted.Unsorted == nil || unsorted.Unsorted.LeadID != 25399020 {
+		t.Errorf("Неверная неразобранная заявка: %+v", unsorted.Unsorted)
+	}
+}
+
+func TestParsePayloadInvalidNumber(t *testing.T) {
+	values := url.Values{"leads[add][0][id]": {"сто"}}
+	if _, err := ParsePayload(values); err == nil || !strings.Contains(err.Error(), "leads[add][0][id]") {
+		t.Errorf("Ожидалась ошибка с путем поля, получено %v", err)
+	}
+}
+
+func TestReceiver(t *testing.T) {
+	receiver := NewReceiver()
+
+	var statusLeads []int
+	receiver.Handle(EntityLead, ActionStatusChange, func(ctx context.Context, event *Event) error {
+		statusLeads = append(statusLeads, event.Lead.ID)
+		return nil
+	})
+
+	// Тот же обработчик изменений, что используется при опросе событий
+	var changes []events.EventType
+	router := changefeed.NewRouter()
+	router.Handle(func(ctx context.Context, change *changefeed.Change) error {
+		changes = append(changes, change.Type)
+		return nil
+	}, events.EventTypeLeadStatusChanged, events.EventTypeContactAdded)
+	receiver.HandleChanges(router)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/amocrm", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		receiver.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(readRecordedPayload(t, "lead_status.txt")); rec.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := post(readRecordedPayload(t, "contacts_add.txt")); rec.Code != http.StatusOK {
+		t.Fatalf("Ожидался статус 200, получен %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if len(statusLeads) != 1 || statusLeads[0] != 25399013 {
+		t.Errorf("Ожидалась обработка сделки 25399013, получено %v", statusLeads)
+	}
+	if len(changes) != 2 || changes[0] != events.EventTypeLeadStatusChanged || changes[1] != events.EventTypeContactAdded {
+		t.Errorf("Ожидались изменения lead_status_changed и contact_added, получено %v", changes)
+	}
+
+	// Ошибка обработчика - amoCRM повторит отправку
+	receiver.Handle(EntityLead, ActionStatusChange, func(ctx context.Context, event *Event) error {
+		return errors.New("база данных недоступна")
+	})
+	if rec := post(readRecordedPayload(t, "lead_status.txt")); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Ожидался статус 500, получен %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/amocrm", nil)
+	rec := httptest.NewRecorder()
+	receiver.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Ожидался статус 405, получен %d", rec.Code)
+	}
+}
@@ -0,0 +1,203 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/chudno/amo_crm_sdk/client"
+)
+
+// Subscription подписка на вебхуки в формате API amoCRM v4.
+// amoCRM определяет подписку по адресу Destination, Settings - имена событий вида add_lead и status_lead.
+type Subscription struct {
+	ID          int      `json:"id,omitempty"`
+	Destination string   `json:"destination"`
+	Settings    []string `json:"settings"`
+	Sort        int      `json:"sort,omitempty"`
+	Disabled    bool     `json:"disabled,omitempty"`
+	CreatedAt   int64    `json:"created_at,omitempty"`
+	UpdatedAt   int64    `json:"updated_at,omitempty"`
+	CreatedBy   int      `json:"created_by,omitempty"`
+	AccountID   int      `json:"account_id,omitempty"`
+}
+
+// settingEntities суффиксы имен настроек по сущностям
+var settingEntities = map[string]string{
+	EntityLead:     "lead",
+	EntityContact:  "contact",
+	EntityCompany:  "company",
+	EntityCustomer: "customer",
+	EntityTask:     "task",
+	EntityTalk:     "talk",
+	EntityUnsorted: "unsorted",
+}
+
+// settingActions действия, на которые можно подписаться для каждой сущности
+var settingActions = map[string][]string{
+	EntityLead:     {ActionAdd, ActionUpdate, ActionDelete, ActionRestore, ActionStatusChange, ActionResponsible, ActionNote},
+	EntityContact:  {ActionAdd, ActionUpdate, ActionDelete, ActionRestore, ActionResponsible, ActionNote},
+	EntityCompany:  {ActionAdd, ActionUpdate, ActionDelete, ActionRestore, ActionResponsible, ActionNote},
+	EntityCustomer: {ActionAdd, ActionUpdate, ActionDelete, ActionStatusChange, ActionResponsible, ActionNote},
+	EntityTask:     {ActionAdd, ActionUpdate, ActionDelete, ActionResponsible},
+	EntityTalk:     {ActionAdd, ActionUpdate},
+	EntityUnsorted: {ActionAdd, ActionDelete},
+}
+
+// SettingName возвращает имя настройки amoCRM для действия над сущностью,
+// например SettingName(EntityLead, ActionStatusChange) - "status_lead".
+func SettingName(entity, action string) (string, error) {
+	suffix, ok := settingEntities[entity]
+	if !ok {
+		return "", fmt.Errorf("неизвестная сущность вебхука: %s", entity)
+	}
+	for _, supported := range settingActions[entity] {
+		if supported == action {
+			return action + "_" + suffix, nil
+		}
+	}
+	return "", fmt.Errorf("действие %s не поддерживается для сущности %s", action, entity)
+}
+
+// SettingNames возвращает имена настроек для всех поддерживаемых сочетаний сущностей и действий.
+// Неподдерживаемые сочетания, например status для контактов, пропускаются.
+func SettingNames(entities, actions []string) ([]string, error) {
+	var names []string
+	for _, entity := range entities {
+		if _, ok := settingEntities[entity]; !ok {
+			return nil, fmt.Errorf("неизвестная сущность вебхука: %s", entity)
+		}
+		for _, action := range actions {
+			if name, err := SettingName(entity, action); err == nil {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("нет поддерживаемых сочетаний сущностей %v и действий %v", entities, actions)
+	}
+	return names, nil
+}
+
+// ListSubscriptions получает подписки на вебхуки аккаунта.
+func ListSubscriptions(apiClient *client.Client) ([]Subscription, error) {
+	url := fmt.Sprintf("%s/api/v4/webhooks", apiClient.GetBaseURL())
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Если подписок нет, amoCRM возвращает 204 без тела
+	if resp.StatusCode == http.StatusNoContent {
+		return []Subscription{}, nil
+	}
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Embedded struct {
+			Webhooks []Subscription `json:"webhooks"`
+		} `json:"_embedded"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Embedded.Webhooks, nil
+}
+
+// Subscribe подписывает адрес на события.
+// Если подписка на этот адрес уже есть, amoCRM заменяет ее настройки.
+func Subscribe(apiClient *client.Client, subscription *Subscription) (*Subscription, error) {
+	url := fmt.Sprintf("%s/api/v4/webhooks", apiClient.GetBaseURL())
+
+	data, err := json.Marshal(struct {
+		Destination string   `json:"destination"`
+		Settings    []string `json:"settings"`
+		Sort        int      `json:"sort,omitempty"`
+	}{subscription.Destination, subscription.Settings, subscription.Sort})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	var created Subscription
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// Unsubscribe удаляет подписку на вебхуки по адресу.
+func Unsubscribe(apiClient *client.Client, destination string) error {
+	url := fmt.Sprintf("%s/api/v4/webhooks", apiClient.GetBaseURL())
+
+	data, err := json.Marshal(map[string]string{"destination": destination})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := apiClient.DoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Проверяем статус-код ответа
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("неожиданный статус-код: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sortedSettings возвращает отсортированные имена настроек без повторов
+func sortedSettings(settings []string) []string {
+	seen := make(map[string]bool, len(settings))
+	result := make([]string, 0, len(settings))
+	for _, name := range settings {
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
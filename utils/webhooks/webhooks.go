@@ -34,6 +34,8 @@ const (
 	EntityCompany  = "companies"
 	EntityCustomer = "customers"
 	EntityTask     = "tasks"
+	EntityTalk     = "talks"
+	EntityUnsorted = "unsorted"
 )
 
 // Action определяет типы действий для вебхуков
@@ -43,6 +45,8 @@ const (
 	ActionDelete       = "delete"
 	ActionRestore      = "restore"
 	ActionStatusChange = "status"
+	ActionResponsible  = "responsible"
+	ActionNote         = "note"
 )
 
 // GetWebhook получает вебхук по его ID.